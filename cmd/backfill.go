@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/storage"
+)
+
+// Backfill tuning
+const (
+	backfillChunkSize        = 1000 // Blocks checked against the store per query
+	backfillProgressInterval = 10 * time.Second
+)
+
+// runBackfill implements the backfill command: it analyzes --from through
+// --to and persists the results, skipping blocks already stored
+func runBackfill(args []string) error {
+	flags := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	from := flags.Int("from", -1, "First block to analyze")
+	to := flags.Int("to", -1, "Last block to analyze")
+	concurrency := flags.Int("concurrency", 4, "Blocks analyzed at once")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil // Usage was printed
+		}
+		return err
+	}
+
+	if *from < 0 || *to < *from {
+		return errors.New("--from and --to must give a non-empty block range")
+	}
+	if *concurrency < 1 {
+		return errors.New("--concurrency must be at least 1")
+	}
+
+	cfg := loadConfig()
+	if cfg.DB.Host == "" {
+		return errors.New("backfill requires db.host to persist results")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	dsn := storage.DSN(cfg.DB.Host, cfg.DB.Port, cfg.DB.User, cfg.DB.Password, cfg.DB.Name, cfg.DB.SSLMode)
+	store, err := storage.NewPostgresStore(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	mevDetector := newDetector(cfg.Blockchain)
+	analyze := func(ctx context.Context, blockNumber int) (models.BlockMEVResult, error) {
+		result, err := mevDetector.AnalyzeBlock(ctx, blockNumber)
+		return mevDetector.FilterResultByConfidence(result, cfg.DB.MinConfidence), err
+	}
+	return backfill(ctx, store, analyze, mevDetector.ConfigVersion(), *from, *to, *concurrency)
+}
+
+// backfillStats counts the blocks a backfill has handled
+type backfillStats struct {
+	saved   atomic.Int64
+	skipped atomic.Int64
+	failed  atomic.Int64
+}
+
+func (s *backfillStats) done() int64 {
+	return s.saved.Load() + s.skipped.Load() + s.failed.Load()
+}
+
+// backfill analyzes blocks from through to with concurrency workers and
+// saves their results to store. Blocks already stored are skipped, so an
+// interrupted backfill resumes where it left off when rerun; results saved
+// before fee recipients were recorded, or analyzed under a detector
+// configuration other than version, are analyzed again. Failed blocks are
+// logged and reported once the range is done, leaving them to a rerun.
+func backfill(ctx context.Context, store storage.Store, analyze analyzeFunc, version string, from, to, concurrency int) error {
+	total := to - from + 1
+	var stats backfillStats
+
+	blocks := make(chan int)
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range blocks {
+				result, err := analyze(ctx, b)
+				if err == nil {
+					err = store.SaveBlockResult(ctx, result)
+				}
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Printf("Backfill: block %d: %v", b, err)
+					}
+					stats.failed.Add(1)
+					continue
+				}
+				stats.saved.Add(1)
+			}
+		}()
+	}
+
+	progressDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(backfillProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-progressDone:
+				return
+			case <-ticker.C:
+				log.Printf("Backfill: %d/%d blocks (%d saved, %d skipped, %d failed)",
+					stats.done(), total, stats.saved.Load(), stats.skipped.Load(), stats.failed.Load())
+			}
+		}
+	}()
+
+	err := feedBackfill(ctx, store, blocks, version, from, to, &stats)
+	close(blocks)
+	wg.Wait()
+	close(progressDone)
+
+	if err == nil {
+		err = ctx.Err()
+	}
+	log.Printf("Backfill finished: %d/%d blocks (%d saved, %d skipped, %d failed)",
+		stats.done(), total, stats.saved.Load(), stats.skipped.Load(), stats.failed.Load())
+	if err != nil {
+		return err
+	}
+	if failed := stats.failed.Load(); failed > 0 {
+		return fmt.Errorf("%d blocks failed; rerun to retry them", failed)
+	}
+	return nil
+}
+
+// feedBackfill sends each block from through to that is not yet stored
+// under version to blocks, until ctx is done
+func feedBackfill(ctx context.Context, store storage.Store, blocks chan<- int, version string, from, to int, stats *backfillStats) error {
+	for start := from; start <= to; start += backfillChunkSize {
+		end := min(start+backfillChunkSize-1, to)
+		stored, err := store.GetBlockResults(ctx, start, end)
+		if err != nil {
+			return err
+		}
+
+		skip := make(map[int]bool, len(stored))
+		for _, result := range stored {
+			if result.FeeRecipient != "" && result.DetectorVersion == version {
+				skip[result.BlockNumber] = true
+			}
+		}
+
+		for b := start; b <= end; b++ {
+			if skip[b] {
+				stats.skipped.Add(1)
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case blocks <- b:
+			}
+		}
+	}
+	return nil
+}
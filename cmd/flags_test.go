@@ -0,0 +1,17 @@
+package main
+
+import "testing"
+
+func TestHelpIsNotAnError(t *testing.T) {
+	commands := map[string]func(args []string) error{
+		"scan":     runScan,
+		"backfill": runBackfill,
+	}
+	for name, run := range commands {
+		t.Run(name, func(t *testing.T) {
+			if err := run([]string{"-h"}); err != nil {
+				t.Errorf("%s -h: %v", name, err)
+			}
+		})
+	}
+}
@@ -1,17 +1,77 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/brianreynaldgit/mev-staking-tracker/configs"
+	"github.com/brianreynaldgit/mev-staking-tracker/docs"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/alerts"
 	"github.com/brianreynaldgit/mev-staking-tracker/internal/api"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/beacon"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/ens"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/grpcwire"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/live"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/metrics"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/pricing"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/relay"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/requestid"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/scanner"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/storage"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 )
 
+const usage = `Usage: mev-staking-tracker [command]
+
+Commands:
+  serve     Run the HTTP API (default)
+  scan      Analyze a block range and write one CSV row per block
+  backfill  Analyze a block range and persist the results, skipping stored blocks
+`
+
+// @title MEV Staking Tracker API
+// @version 1.0
+// @description Track Ethereum validator rewards with MEV boost detection.
 func main() {
-	// Load configuration from YAML
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		if err := serve(); err != nil {
+			log.Fatalf("Server stopped: %v", err)
+		}
+	case "scan":
+		if err := runScan(args); err != nil {
+			log.Fatalf("Scan failed: %v", err)
+		}
+	case "backfill":
+		if err := runBackfill(args); err != nil {
+			log.Fatalf("Backfill failed: %v", err)
+		}
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+}
+
+// loadConfig loads the YAML configuration named by CONFIG_PATH
+func loadConfig() *configs.Config {
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
 		configPath = "config.yaml" // <- assume it's in the root directory
@@ -20,24 +80,379 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	return cfg
+}
+
+// serve runs the HTTP API until interrupted
+func serve() error {
+	cfg := loadConfig()
+	logger := newLogger(cfg.Server.LogLevel)
+	mevDetector := newDetector(cfg.Blockchain)
+
+	// Export request traces when a collector is configured
+	if cfg.Server.TracingURL != "" {
+		exporter := tracing.NewOTLPExporter(cfg.Server.TracingURL, "mev-staking-tracker")
+		exporter.Start()
+		tracing.SetExporter(exporter)
+		defer exporter.Shutdown()
+	}
+
+	// Beacon node integration is optional
+	var beaconClient *beacon.Client
+	if cfg.Blockchain.BeaconURL != "" {
+		beaconClient = beacon.NewClient(cfg.Blockchain.BeaconURL)
+	}
+
+	// Relay data is optional
+	var relayClient *relay.Client
+	if len(cfg.Blockchain.RelayURLs) > 0 {
+		relayClient = relay.NewClient(cfg.Blockchain.RelayURLs)
+	}
+
+	// Persist analyzed blocks when a database host is configured
+	var store storage.Store
+	if cfg.DB.Host != "" {
+		dsn := storage.DSN(cfg.DB.Host, cfg.DB.Port, cfg.DB.User, cfg.DB.Password, cfg.DB.Name, cfg.DB.SSLMode)
+		pgStore, err := storage.NewPostgresStore(context.Background(), dsn)
+		if err != nil {
+			return fmt.Errorf("failed to initialize storage: %w", err)
+		}
+		defer pgStore.Close()
+		store = pgStore
+	} else {
+		log.Printf("No database host configured, results will not be persisted")
+	}
+
+	// Validate the provider before accepting traffic
+	if cfg.Blockchain.Warmup {
+		warmupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		warnings, err := mevDetector.Warmup(warmupCtx)
+		cancel()
+		if err != nil {
+			log.Printf("Warning: provider warmup failed: %v", err)
+		}
+		for _, warning := range warnings {
+			log.Printf("Warning: provider warmup: %s", warning)
+		}
+	}
+
+	// Continuously ingest new blocks when enabled
+	if cfg.Scanner.Enabled {
+		blockScanner := scanner.New(mevDetector, store)
+		if cfg.Scanner.PollInterval > 0 {
+			blockScanner.PollInterval = cfg.Scanner.PollInterval
+		}
+		if cfg.Scanner.MaxConcurrency > 0 {
+			blockScanner.MaxConcurrency = cfg.Scanner.MaxConcurrency
+		}
+		blockScanner.StartBlock = cfg.Scanner.StartBlock
+		blockScanner.MinConfidence = cfg.DB.MinConfidence
+		if cfg.Alerts.WebhookURL != "" {
+			webhook := alerts.NewWebhook(cfg.Alerts.WebhookURL, cfg.Alerts.MinReward)
+			webhook.Currency = mevDetector.NativeSymbol
+			webhook.Start()
+			defer webhook.Stop()
+			blockScanner.OnResult = webhook.Notify
+		}
+		if err := blockScanner.Start(context.Background()); err != nil {
+			return fmt.Errorf("failed to start scanner: %w", err)
+		}
+		defer blockScanner.Stop()
+	}
 
 	// Create API handler
-	apiHandler := api.NewAPI(cfg.Blockchain.AlchemyAPIURL, cfg.Blockchain.AlchemyAPIKey)
+	apiHandler := api.NewAPI(mevDetector, beaconClient, store)
+	if cfg.Blockchain.MinConcurrency > 0 {
+		apiHandler.MinConcurrency = cfg.Blockchain.MinConcurrency
+	}
+	if cfg.Blockchain.MaxConcurrency > 0 {
+		apiHandler.MaxConcurrency = cfg.Blockchain.MaxConcurrency
+	}
+	apiHandler.FeeRecipients = cfg.Blockchain.FeeRecipients
+	apiHandler.Relays = relayClient
+
+	priceOracleURL := pricing.DefaultCoinGeckoURL
+	if cfg.Blockchain.PriceOracleURL != "" {
+		priceOracleURL = cfg.Blockchain.PriceOracleURL
+	}
+	apiHandler.Prices = pricing.NewCoinGecko(priceOracleURL)
+	if chain := cfg.Blockchain.Chain; chain == "" || chain == models.DefaultChain {
+		apiHandler.Names = ens.NewResolver(mevDetector.Provider)
+	}
+	if cfg.Server.MaxBlockRange > 0 {
+		apiHandler.MaxBlockRange = cfg.Server.MaxBlockRange
+	}
+	if cfg.Server.RequestTimeout > 0 {
+		apiHandler.RequestTimeout = cfg.Server.RequestTimeout
+	}
+	if cfg.Blockchain.FinalityDepth > 0 {
+		apiHandler.FinalityDepth = cfg.Blockchain.FinalityDepth
+	}
+	if cfg.Blockchain.BlockRetries != 0 {
+		apiHandler.BlockRetries = max(cfg.Blockchain.BlockRetries, 0)
+	}
+	apiHandler.MinConfidence = cfg.DB.MinConfidence
+	var heads live.HeadSource = live.NewPoller(mevDetector.Provider)
+	if cfg.Blockchain.WSURL != "" {
+		heads = live.NewSubscriber(cfg.Blockchain.WSURL)
+	}
+	apiHandler.Live = live.NewFeed(mevDetector, heads)
+
+	// Watch pending transactions when enabled
+	if cfg.Mempool.Enabled {
+		pending := live.NewPendingMonitor(mevDetector)
+		if cfg.Mempool.PollInterval > 0 {
+			pending.Interval = cfg.Mempool.PollInterval
+		}
+		if err := pending.Start(context.Background()); err != nil {
+			return fmt.Errorf("failed to start mempool monitor: %w", err)
+		}
+		defer pending.Stop()
+		apiHandler.Pending = pending
+	}
 
 	// Set up router
-	router := gin.Default()
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	router.Use(gin.Recovery(), api.RequestLogger(logger), api.Tracing(), api.Metrics(), api.ServerTiming())
+	router.Use(api.CORS(cfg.Server.CORSAllowedOrigins, cfg.Server.CORSAllowedMethods, cfg.Server.CORSAllowedHeaders))
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/healthz", apiHandler.Healthz)
+	router.GET("/readyz", apiHandler.Readyz)
+	router.GET("/openapi.json", api.OpenAPISpec(docs.SwaggerJSON))
+	router.GET("/swagger/*any", api.SwaggerUI)
+
+	// Live block feed
+	wsGroup := router.Group("/ws")
+	if len(cfg.Server.APIKeys) > 0 {
+		wsGroup.Use(api.APIKeyAuth(cfg.Server.APIKeys))
+	}
+	wsGroup.GET("/blocks", apiHandler.StreamLiveBlocks)
+
+	// Profiling routes
+	if cfg.Server.EnablePprof {
+		pprofGroup := router.Group("/debug/pprof")
+		if len(cfg.Server.APIKeys) > 0 {
+			pprofGroup.Use(api.APIKeyAuth(cfg.Server.APIKeys))
+		} else {
+			log.Printf("Warning: pprof is enabled without server.api_keys; profiles are publicly accessible")
+		}
+		pprofGroup.GET("/*name", api.Pprof)
+		pprofGroup.POST("/*name", api.Pprof)
+	}
 
 	// API routes
-	apiGroup := router.Group("/api/v1")
+	var apiMiddleware []gin.HandlerFunc
+	if len(cfg.Server.APIKeys) > 0 {
+		apiMiddleware = append(apiMiddleware, api.APIKeyAuth(cfg.Server.APIKeys))
+	}
+	if cfg.Server.RateLimit > 0 {
+		apiMiddleware = append(apiMiddleware, api.NewRateLimiter(cfg.Server.RateLimit, cfg.Server.RateBurst).Middleware())
+	}
+	apiGroup := router.Group("/api/v1", apiMiddleware...)
 	{
 		apiGroup.GET("/mev/block/:blockNumber", apiHandler.GetBlockMEV)
+		apiGroup.GET("/block/:blockNumber/transactions", apiHandler.GetBlockTransactions)
+		apiGroup.GET("/detectors", apiHandler.GetDetectors)
+		apiGroup.GET("/bots", apiHandler.GetBots)
+		apiGroup.POST("/bots", apiHandler.AddBot)
+		apiGroup.DELETE("/bots/:address", apiHandler.RemoveBot)
+		apiGroup.GET("/mev/fee-breakdown", apiHandler.GetFeeBreakdown)
+		apiGroup.GET("/mev/top-extractors", apiHandler.GetTopExtractors)
+		apiGroup.GET("/mev/calendar", apiHandler.GetMEVCalendar)
+		apiGroup.GET("/mev/stats", apiHandler.GetMEVStats)
+		apiGroup.GET("/mev/anomalies", apiHandler.GetMEVAnomalies)
+		apiGroup.GET("/mev/pending", apiHandler.GetPendingMEV)
+		apiGroup.GET("/mev/history", apiHandler.GetMEVHistory)
 		apiGroup.GET("/validator/:validatorIndex/mev-rewards", apiHandler.GetValidatorMEVRewards)
+		apiGroup.GET("/validators/leaderboard", apiHandler.GetValidatorLeaderboard)
+		apiGroup.GET("/validator/:validatorIndex/mev-rewards/stream", apiHandler.StreamValidatorMEVRewards)
+		apiGroup.GET("/validator/:validatorIndex/forecast", apiHandler.GetValidatorForecast)
+		apiGroup.GET("/validator/:validatorIndex/backtest", apiHandler.GetValidatorBacktest)
+		apiGroup.GET("/validator/:validatorIndex/actual-rewards", apiHandler.GetValidatorActualRewards)
+		apiGroup.GET("/validator/:validatorIndex/epoch/:epoch/peers", apiHandler.GetValidatorEpochPeers)
 		apiGroup.POST("/simulate", apiHandler.SimulateMEVRewards)
+		apiGroup.POST("/graphql", apiHandler.GraphQL)
 	}
 
-	// Start server
+	// Serve until SIGINT or SIGTERM, then drain requests before the
+	// deferred scanner and storage cleanup runs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	servers := []*http.Server{newHTTPServer(cfg.Server, router)}
 	log.Printf("Starting MEV Staking Tracker API on port %s", cfg.Server.Port)
-	if err := router.Run(":" + cfg.Server.Port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+
+	// gRPC service, behind the same authentication and rate limits as the
+	// REST API
+	if cfg.Server.GRPCPort != "" {
+		grpcRouter := gin.New()
+		if err := grpcRouter.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+			return fmt.Errorf("invalid trusted proxies: %w", err)
+		}
+		grpcRouter.Use(gin.Recovery(), api.RequestLogger(logger), api.Metrics())
+		grpcRouter.Use(apiMiddleware...)
+		grpcRouter.POST("/"+api.GRPCService+"/:method", gin.WrapH(apiHandler.GRPCServer()))
+
+		grpcConfig := cfg.Server
+		grpcConfig.Port = cfg.Server.GRPCPort
+		servers = append(servers, newHTTPServer(grpcConfig, grpcwire.H2C(grpcRouter)))
+		log.Printf("Starting gRPC service on port %s", cfg.Server.GRPCPort)
+	}
+
+	if err := runServers(ctx, shutdownTimeout, servers...); err != nil {
+		return err
+	}
+	log.Printf("Server stopped")
+	return nil
+}
+
+// newLogger installs a JSON logger at level as the default for both slog and
+// the standard log package. Records logged with a request context carry its
+// request ID.
+func newLogger(level string) *slog.Logger {
+	var lvl slog.Level
+	if level != "" {
+		lvl.UnmarshalText([]byte(level)) //nolint:errcheck // validated with the config
+	}
+	handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: lvl})
+	logger := slog.New(requestid.Handler{Handler: handler})
+	slog.SetDefault(logger)
+	return logger
+}
+
+// newDetector creates an MEV detector configured from the blockchain config
+func newDetector(cfg configs.BlockchainConfig) *models.MEVDetector {
+	mevDetector := models.NewMEVDetector(newProvider(cfg))
+	if cfg.ArchiveURL != "" {
+		mevDetector.Archive = configureRPC(models.NewJSONRPCProvider(cfg.ArchiveURL), cfg)
+	}
+	if cfg.CallDecodeDepth > 0 {
+		mevDetector.CallDecodeDepth = cfg.CallDecodeDepth
+	}
+	if cfg.KnownBotsPath != "" {
+		bots, err := models.LoadKnownBots(cfg.KnownBotsPath)
+		if err != nil {
+			log.Fatalf("Failed to load known bots: %v", err)
+		}
+		mevDetector.KnownMEVBots = bots
+		log.Printf("Loaded %d known MEV bots from %s", bots.Len(), cfg.KnownBotsPath)
+	}
+	if cfg.LabelsPath != "" {
+		labels, err := models.LoadLabels(cfg.LabelsPath)
+		if err != nil {
+			log.Fatalf("Failed to load address labels: %v", err)
+		}
+		for addr, label := range labels {
+			mevDetector.Labels[addr] = label
+		}
+		log.Printf("Loaded %d address labels from %s", len(labels), cfg.LabelsPath)
+	}
+	mevDetector.AddLiquidationTargets(cfg.LendingProtocols, cfg.LiquidationSelectors)
+	if cfg.ArbitrageMinSwaps > 0 {
+		mevDetector.ArbitrageMinSwaps = cfg.ArbitrageMinSwaps
+	}
+	if cfg.ValidatorMEVShare > 0 {
+		mevDetector.ValidatorMEVShare = cfg.ValidatorMEVShare
+	}
+	if cfg.RewardCeiling > 0 {
+		mevDetector.RewardCeiling = cfg.RewardCeiling
+	}
+	if cfg.NativeSymbol != "" {
+		mevDetector.NativeSymbol = cfg.NativeSymbol
+	} else if cfg.Chain != "" {
+		symbol, ok := models.NativeSymbol(cfg.Chain)
+		if !ok {
+			log.Fatalf("Unknown chain %q: set blockchain.native_symbol", cfg.Chain)
+		}
+		mevDetector.NativeSymbol = symbol
+	}
+	if cfg.WrappedNative != "" {
+		mevDetector.WrappedNative, _ = models.NormalizeAddress(cfg.WrappedNative)
+	} else if cfg.Chain != "" {
+		// Unknown chains value arbitrage by transaction fee alone
+		mevDetector.WrappedNative, _ = models.WrappedNative(cfg.Chain)
+	}
+
+	return mevDetector
+}
+
+// newProvider creates the RPC provider selected by the blockchain config,
+// failing over to any fallback endpoints in order. Each endpoint has its
+// own circuit breaker.
+func newProvider(cfg configs.BlockchainConfig) models.RPCProvider {
+	var primary models.RPCProvider
+	if cfg.Provider == "jsonrpc" {
+		primary = configureRPC(models.NewJSONRPCProvider(cfg.RPCURL), cfg)
+	} else {
+		alchemy := models.NewAlchemyProvider(cfg.AlchemyAPIURL, cfg.AlchemyAPIVersion, cfg.AlchemyAPIKey)
+		configureRPC(alchemy.JSONRPCProvider, cfg)
+		primary = alchemy
+	}
+
+	if len(cfg.FallbackRPCURLs) == 0 {
+		return configureBreaker(models.NewBreakerProvider("primary", primary), cfg)
+	}
+
+	endpoints := []models.FailoverEndpoint{{Name: "primary", Provider: configureBreaker(models.NewBreakerProvider("primary", primary), cfg)}}
+	for i, rpcURL := range cfg.FallbackRPCURLs {
+		name := fmt.Sprintf("fallback-%d", i+1)
+		endpoints = append(endpoints, models.FailoverEndpoint{
+			Name:     name,
+			Provider: configureBreaker(models.NewBreakerProvider(name, configureRPC(models.NewJSONRPCProvider(rpcURL), cfg)), cfg),
+		})
+	}
+	return models.NewFailoverProvider(endpoints...)
+}
+
+// configureBreaker applies the configured circuit breaker settings
+func configureBreaker(b *models.BreakerProvider, cfg configs.BlockchainConfig) *models.BreakerProvider {
+	if cfg.BreakerFailureRatio > 0 {
+		b.FailureRatio = cfg.BreakerFailureRatio
+	}
+	if cfg.BreakerMinRequests > 0 {
+		b.MinRequests = cfg.BreakerMinRequests
+	}
+	if cfg.BreakerOpenTimeout > 0 {
+		b.OpenTimeout = cfg.BreakerOpenTimeout
+	}
+	return b
+}
+
+// configureRPC applies the configured retry, timeout, response size,
+// connection pool and proxy settings to a JSON-RPC provider
+func configureRPC(p *models.JSONRPCProvider, cfg configs.BlockchainConfig) *models.JSONRPCProvider {
+	if cfg.MaxRetries > 0 {
+		p.MaxRetries = cfg.MaxRetries
+	}
+	if cfg.RetryBaseDelay > 0 {
+		p.RetryBaseDelay = cfg.RetryBaseDelay
+	}
+	if cfg.RPCTimeout > 0 {
+		p.HttpClient.Timeout = cfg.RPCTimeout
+	}
+	if cfg.MaxResponseBytes > 0 {
+		p.MaxResponseBytes = cfg.MaxResponseBytes
+	}
+	if t, ok := p.HttpClient.Transport.(*http.Transport); ok {
+		if cfg.MaxIdleConns > 0 {
+			t.MaxIdleConns = cfg.MaxIdleConns
+		}
+		if cfg.MaxIdleConnsPerHost > 0 {
+			t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		}
+		if cfg.IdleConnTimeout > 0 {
+			t.IdleConnTimeout = cfg.IdleConnTimeout
+		}
+		if cfg.ProxyURL != "" {
+			proxyURL, err := url.Parse(cfg.ProxyURL)
+			if err != nil {
+				log.Fatalf("Invalid proxy URL: %v", err)
+			}
+			t.Proxy = http.ProxyURL(proxyURL)
+		}
 	}
+	return p
 }
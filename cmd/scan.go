@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// scanCSVHeader names the columns written by the scan command
+var scanCSVHeader = []string{"block_number", "opportunities", "validator_reward", "types"}
+
+// analyzeFunc analyzes a single block
+type analyzeFunc func(ctx context.Context, blockNumber int) (models.BlockMEVResult, error)
+
+// runScan implements the scan command: it analyzes --from through --to and
+// writes one CSV row per block to --out
+func runScan(args []string) error {
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	from := flags.Int("from", -1, "First block to analyze")
+	to := flags.Int("to", -1, "Last block to analyze")
+	out := flags.String("out", "-", "CSV output path, or - for stdout")
+	concurrency := flags.Int("concurrency", 4, "Blocks analyzed at once")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil // Usage was printed
+		}
+		return err
+	}
+
+	if *from < 0 || *to < *from {
+		return errors.New("--from and --to must give a non-empty block range")
+	}
+	if *concurrency < 1 {
+		return errors.New("--concurrency must be at least 1")
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	mevDetector := newDetector(loadConfig().Blockchain)
+	return writeScanCSV(ctx, w, mevDetector.AnalyzeBlock, *from, *to, *concurrency)
+}
+
+// writeScanCSV analyzes blocks from through to, up to concurrency at a time,
+// and writes their rows to w in block order
+func writeScanCSV(ctx context.Context, w io.Writer, analyze analyzeFunc, from, to, concurrency int) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(scanCSVHeader); err != nil {
+		return err
+	}
+
+	for start := from; start <= to; start += concurrency {
+		end := min(start+concurrency-1, to)
+		results := make([]models.BlockMEVResult, end-start+1)
+		errs := make([]error, len(results))
+
+		var wg sync.WaitGroup
+		for b := start; b <= end; b++ {
+			wg.Add(1)
+			go func(i, b int) {
+				defer wg.Done()
+				results[i], errs[i] = analyze(ctx, b)
+			}(b-start, b)
+		}
+		wg.Wait()
+
+		for i, result := range results {
+			if errs[i] != nil {
+				cw.Flush()
+				return fmt.Errorf("block %d: %w", start+i, errs[i])
+			}
+			if err := cw.Write(scanCSVRow(result)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+	}
+
+	return cw.Error()
+}
+
+// scanCSVRow formats a block result as a CSV row. Types lists each distinct
+// opportunity type once, separated by semicolons.
+func scanCSVRow(result models.BlockMEVResult) []string {
+	var types []string
+	seen := make(map[string]bool)
+	for _, opp := range result.Opportunities {
+		if !seen[opp.Type] {
+			seen[opp.Type] = true
+			types = append(types, opp.Type)
+		}
+	}
+
+	return []string{
+		strconv.Itoa(result.BlockNumber),
+		strconv.Itoa(len(result.Opportunities)),
+		strconv.FormatFloat(result.ValidatorReward, 'f', -1, 64),
+		strings.Join(types, ";"),
+	}
+}
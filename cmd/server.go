@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/configs"
+)
+
+// shutdownTimeout bounds how long in-flight requests may drain on shutdown
+const shutdownTimeout = 15 * time.Second
+
+// Defaults for HTTP server limits
+const (
+	defaultReadTimeout       = 30 * time.Second
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultWriteTimeout      = 5 * time.Minute
+	defaultIdleTimeout       = 120 * time.Second
+	defaultMaxHeaderBytes    = 1 << 20
+)
+
+// newHTTPServer creates a server for handler with the configured timeouts,
+// falling back to the defaults for unset values
+func newHTTPServer(cfg configs.ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadTimeout:       orDefault(cfg.ReadTimeout, defaultReadTimeout),
+		ReadHeaderTimeout: orDefault(cfg.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		WriteTimeout:      orDefault(cfg.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       orDefault(cfg.IdleTimeout, defaultIdleTimeout),
+		MaxHeaderBytes:    orDefault(cfg.MaxHeaderBytes, defaultMaxHeaderBytes),
+	}
+}
+
+// orDefault returns value, or def when value is zero
+func orDefault[T comparable](value, def T) T {
+	var zero T
+	if value == zero {
+		return def
+	}
+	return value
+}
+
+// runServers runs each server with runServer until ctx is cancelled or any
+// of them fails, which stops the rest
+func runServers(ctx context.Context, timeout time.Duration, servers ...*http.Server) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errs[i] = runServer(ctx, srv, timeout); errs[i] != nil {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// runServer serves until ctx is cancelled, then stops accepting connections
+// and waits up to timeout for in-flight requests to complete before forcibly
+// closing the rest. It returns early if the server fails to start.
+func runServer(ctx context.Context, srv *http.Server, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, draining in-flight requests")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// freeAddr returns a loopback address with a port that was free when checked
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// waitListening polls addr until it accepts connections
+func waitListening(t *testing.T, addr string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("server on %s did not start", addr)
+}
+
+// blockingServer serves a handler that signals started and then waits for
+// release before answering
+func blockingServer(t *testing.T) (srv *http.Server, started chan struct{}, release chan struct{}) {
+	started = make(chan struct{}, 1)
+	release = make(chan struct{})
+	srv = &http.Server{
+		Addr: freeAddr(t),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+			io.WriteString(w, "done")
+		}),
+	}
+	return srv, started, release
+}
+
+type response struct {
+	body string
+	err  error
+}
+
+// get requests url in the background
+func get(url string) <-chan response {
+	ch := make(chan response, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			ch <- response{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		ch <- response{body: string(body), err: err}
+	}()
+	return ch
+}
+
+// TestRunServerDrainsInFlightRequests checks that cancelling the context
+// lets an in-flight request finish, refuses new connections, and returns
+// only once the request has been answered
+func TestRunServerDrainsInFlightRequests(t *testing.T) {
+	srv, started, release := blockingServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- runServer(ctx, srv, 5*time.Second) }()
+	waitListening(t, srv.Addr)
+
+	inFlight := get("http://" + srv.Addr)
+	<-started
+	cancel()
+
+	// Shutdown closes the listener before waiting on in-flight requests
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.Dial("tcp", srv.Addr)
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("server still accepting connections after shutdown began")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("runServer returned %v before the in-flight request finished", err)
+	default:
+	}
+
+	close(release)
+	if resp := <-inFlight; resp.err != nil || resp.body != "done" {
+		t.Errorf("in-flight request got %q, error %v; want it answered", resp.body, resp.err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("runServer: %v", err)
+	}
+}
+
+// TestRunServerShutdownTimeout checks that requests outlasting the timeout
+// are cut off and reported
+func TestRunServerShutdownTimeout(t *testing.T) {
+	srv, started, release := blockingServer(t)
+	defer close(release)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- runServer(ctx, srv, 50*time.Millisecond) }()
+	waitListening(t, srv.Addr)
+
+	inFlight := get("http://" + srv.Addr)
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "graceful shutdown failed") {
+			t.Errorf("got %v, want a graceful shutdown error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runServer did not give up after the shutdown timeout")
+	}
+	if resp := <-inFlight; resp.err == nil {
+		t.Errorf("stuck request got %q, want its connection closed", resp.body)
+	}
+}
+
+// TestRunServersStopsOthersOnFailure checks that one server failing to
+// start shuts down the rest instead of leaving them serving
+func TestRunServersStopsOthersOnFailure(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+
+	ok := &http.Server{Addr: freeAddr(t), Handler: http.NotFoundHandler()}
+	failing := &http.Server{Addr: taken.Addr().String(), Handler: http.NotFoundHandler()}
+
+	done := make(chan error, 1)
+	go func() { done <- runServers(context.Background(), 5*time.Second, ok, failing) }()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "server failed") {
+			t.Errorf("got %v, want the failing server's error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runServers kept running after a server failed")
+	}
+
+	if conn, err := net.Dial("tcp", ok.Addr); err == nil {
+		conn.Close()
+		t.Error("healthy server still accepting connections")
+	}
+}
+
+// TestRunServersStopsAllOnCancel checks that cancelling the context drains
+// every server and reports success
+func TestRunServersStopsAllOnCancel(t *testing.T) {
+	servers := []*http.Server{
+		{Addr: freeAddr(t), Handler: http.NotFoundHandler()},
+		{Addr: freeAddr(t), Handler: http.NotFoundHandler()},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- runServers(ctx, 5*time.Second, servers...) }()
+	for _, srv := range servers {
+		waitListening(t, srv.Addr)
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("runServers: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runServers did not stop after cancellation")
+	}
+	for _, srv := range servers {
+		if conn, err := net.Dial("tcp", srv.Addr); err == nil {
+			conn.Close()
+			t.Errorf("server on %s still accepting connections", srv.Addr)
+		}
+	}
+}
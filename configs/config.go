@@ -1,8 +1,17 @@
 package configs
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,6 +20,9 @@ type Config struct {
 	DB         DBConfig         `yaml:"db"`
 	Server     ServerConfig     `yaml:"server"`
 	Blockchain BlockchainConfig `yaml:"blockchain"`
+	Scanner    ScannerConfig    `yaml:"scanner"`
+	Mempool    MempoolConfig    `yaml:"mempool"`
+	Alerts     AlertsConfig     `yaml:"alerts"`
 }
 
 type DBConfig struct {
@@ -19,17 +31,125 @@ type DBConfig struct {
 	User     string `yaml:"user"`
 	Password string `yaml:"password"`
 	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"` // Defaults to "disable"
+
+	// Opportunities scoring below this confidence, from 0 to 1, are left
+	// out of persisted results; responses still show them. 0 persists all.
+	MinConfidence float64 `yaml:"min_confidence"`
 }
 
 type ServerConfig struct {
-	Port string `yaml:"port"`
+	Port           string        `yaml:"port"`
+	GRPCPort       string        `yaml:"grpc_port"`       // Serves the gRPC service in proto/mev/v1; empty disables it
+	MaxBlockRange  int           `yaml:"max_block_range"` // 0 uses the API default
+	RequestTimeout time.Duration `yaml:"request_timeout"` // e.g. "5s"; 0 uses the API default
+	LogLevel       string        `yaml:"log_level"`       // debug, info, warn or error; empty uses info
+	TracingURL     string        `yaml:"tracing_url"`     // OTLP/HTTP collector, e.g. http://localhost:4318; empty disables tracing
+
+	// HTTP server limits; 0 uses the default in parentheses
+	ReadTimeout       time.Duration `yaml:"read_timeout"`        // Whole request including body (30s)
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"` // Request headers (10s)
+	WriteTimeout      time.Duration `yaml:"write_timeout"`       // Whole response, so it also bounds streams and scans (5m)
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`        // Keep-alive connections (120s)
+	MaxHeaderBytes    int           `yaml:"max_header_bytes"`    // (1 MiB)
+
+	// Keys accepted in the X-API-Key header for /api/v1 routes; empty disables authentication
+	APIKeys []string `yaml:"api_keys"`
+
+	// Serve net/http/pprof under /debug/pprof, behind api_keys when set
+	EnablePprof bool `yaml:"enable_pprof"`
+
+	// Browser origins allowed to call the API, or "*" for any; empty denies cross-origin requests
+	CORSAllowedOrigins []string `yaml:"cors_allowed_origins"`
+	CORSAllowedMethods []string `yaml:"cors_allowed_methods"` // Empty allows GET, POST and DELETE
+	CORSAllowedHeaders []string `yaml:"cors_allowed_headers"` // Empty allows the headers the API reads
+
+	// Proxy IPs or CIDRs whose X-Forwarded-For header is trusted for the
+	// client IP that rate limits and logs use; empty trusts none
+	TrustedProxies []string `yaml:"trusted_proxies"`
+
+	// Per-client rate limiting of /api/v1 routes; 0 disables it
+	RateLimit float64 `yaml:"rate_limit"` // Requests per second
+	RateBurst int     `yaml:"rate_burst"` // Requests allowed at once; 0 uses 1
+}
+
+// ScannerConfig controls the background scanner that ingests new blocks
+type ScannerConfig struct {
+	Enabled        bool          `yaml:"enabled"`         // Requires a database
+	PollInterval   time.Duration `yaml:"poll_interval"`   // e.g. "12s"; 0 uses the scanner default
+	StartBlock     int           `yaml:"start_block"`     // Used when no progress is persisted; 0 starts at the head
+	MaxConcurrency int           `yaml:"max_concurrency"` // 0 uses the scanner default
+}
+
+// MempoolConfig controls monitoring of pending transactions for MEV
+type MempoolConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	PollInterval time.Duration `yaml:"poll_interval"` // e.g. "2s"; 0 uses the monitor default
+}
+
+// AlertsConfig controls alerts about blocks ingested by the scanner
+type AlertsConfig struct {
+	WebhookURL string  `yaml:"webhook_url"` // Receives a JSON POST per alert; empty disables alerts
+	MinReward  float64 `yaml:"min_reward"`  // Alert on blocks whose validator reward exceeds this, in native units
 }
 
 type BlockchainConfig struct {
-	AlchemyAPIURL string `yaml:"alchemy_url"`
-	AlchemyAPIKey string `yaml:"alchemy_key"`
+	Provider          string        `yaml:"provider"`          // "alchemy" (default) or "jsonrpc"
+	RPCURL            string        `yaml:"rpc_url"`           // Endpoint for the jsonrpc provider
+	WSURL             string        `yaml:"ws_url"`            // ws:// or wss:// endpoint for newHeads subscriptions; empty polls for new blocks
+	FallbackRPCURLs   []string      `yaml:"fallback_rpc_urls"` // Tried in order when the provider fails
+	AlchemyAPIURL     string        `yaml:"alchemy_url"`       // Defaults to Ethereum mainnet
+	AlchemyAPIKey     string        `yaml:"alchemy_key"`
+	AlchemyAPIVersion string        `yaml:"alchemy_api_version"` // Path segment between alchemy_url and the key; defaults to "v2"
+	ArchiveURL        string        `yaml:"archive_url"`         // Full JSON-RPC URL of an archive node
+	BeaconURL         string        `yaml:"beacon_url"`
+	RelayURLs         []string      `yaml:"relay_urls"`          // MEV-Boost relays queried for delivered payloads
+	PriceOracleURL    string        `yaml:"price_oracle_url"`    // CoinGecko-compatible API; defaults to CoinGecko
+	KnownBotsPath     string        `yaml:"known_bots_path"`     // JSON array or newline file of bot addresses
+	LabelsPath        string        `yaml:"labels_path"`         // JSON object of address to {label, category}, merged over the built-in labels
+	CallDecodeDepth   int           `yaml:"call_decode_depth"`   // 0 uses the detector default
+	Chain             string        `yaml:"chain"`               // Defaults to "ethereum"
+	NativeSymbol      string        `yaml:"native_symbol"`       // Overrides the chain's native symbol
+	WrappedNative     string        `yaml:"wrapped_native"`      // Overrides the chain's wrapped native token, e.g. WETH
+	RewardCeiling     float64       `yaml:"reward_ceiling"`      // 0 uses the detector default
+	ValidatorMEVShare float64       `yaml:"validator_mev_share"` // In (0, 1]; 0 uses the detector default
+	ArbitrageMinSwaps int           `yaml:"arbitrage_min_swaps"` // 0 uses the detector default
+	MaxRetries        int           `yaml:"max_retries"`         // 0 uses the detector default
+	RetryBaseDelay    time.Duration `yaml:"retry_base_delay"`    // e.g. "250ms"; 0 uses the detector default
+	RPCTimeout        time.Duration `yaml:"rpc_timeout"`         // Per HTTP request to RPC endpoints; defaults to 10s
+	MaxResponseBytes  int64         `yaml:"max_response_bytes"`  // Cap on an RPC response body; 0 uses the provider default
+	MinConcurrency    int           `yaml:"min_concurrency"`     // Scan concurrency floor; 0 uses the default
+	MaxConcurrency    int           `yaml:"max_concurrency"`     // Scan concurrency ceiling; 0 uses the default
+	Warmup            bool          `yaml:"warmup"`              // Validate the provider before accepting traffic
+	FinalityDepth     int           `yaml:"finality_depth"`      // Blocks behind the head before responses are cached as immutable; 0 uses the default
+	BlockRetries      int           `yaml:"block_retries"`       // Times a scan re-analyzes a failed block; 0 uses the default, -1 disables retries
+
+	// Connections kept open to each RPC endpoint between requests; 0 uses the default in parentheses
+	MaxIdleConns        int           `yaml:"max_idle_conns"`          // Across all hosts (100)
+	MaxIdleConnsPerHost int           `yaml:"max_idle_conns_per_host"` // Should cover scan concurrency (32)
+	IdleConnTimeout     time.Duration `yaml:"idle_conn_timeout"`       // e.g. "90s" (90s)
+
+	// HTTP or HTTPS proxy for RPC requests; empty uses HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	ProxyURL string `yaml:"proxy_url"`
+
+	// Each RPC endpoint fails fast once too many of its requests fail
+	BreakerFailureRatio float64       `yaml:"breaker_failure_ratio"` // In (0, 1]; 0 uses the default
+	BreakerMinRequests  int           `yaml:"breaker_min_requests"`  // Requests per interval before the ratio applies; 0 uses the default
+	BreakerOpenTimeout  time.Duration `yaml:"breaker_open_timeout"`  // e.g. "30s"; how long to fail fast before probing; 0 uses the default
+
+	// Blocks are attributed to a validator when their miner matches its fee recipient
+	FeeRecipients map[int]string `yaml:"fee_recipients"` // Validator index to fee recipient address
+
+	// Liquidation detection is enabled when both lists are set
+	LendingProtocols     []string `yaml:"lending_protocols"`     // e.g. Aave v3 Pool, Compound Comptroller
+	LiquidationSelectors []string `yaml:"liquidation_selectors"` // e.g. 0x00a718a9 (liquidationCall)
 }
 
+var (
+	addressPattern  = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	selectorPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{8}$`)
+)
+
 func LoadConfig(configPath string) (*Config, error) {
 	// Set default config path if empty
 	if configPath == "" {
@@ -47,12 +167,26 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse YAML
+	// Expand ${VAR} references
+	data, err = expandEnv(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse YAML or JSON
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if isJSONConfig(configPath, data) {
+		if err := unmarshalJSONConfig(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file as JSON: %w", err)
+		}
+	} else if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	// Environment overrides take precedence over the file
+	applyEnvOverrides(&cfg)
+	applyDefaults(&cfg)
+
 	// Validate
 	if err := validateConfig(&cfg); err != nil {
 		return nil, err
@@ -62,18 +196,266 @@ func LoadConfig(configPath string) (*Config, error) {
 }
 
 func validateConfig(cfg *Config) error {
+	var problems []error
 	var missing []string
 
 	if cfg.DB.Password == "" {
 		missing = append(missing, "db.password")
 	}
-	if cfg.Blockchain.AlchemyAPIKey == "" {
-		missing = append(missing, "blockchain.alchemy_key")
+	switch cfg.Blockchain.Provider {
+	case "", "alchemy":
+		if cfg.Blockchain.AlchemyAPIKey == "" {
+			missing = append(missing, "blockchain.alchemy_key")
+		}
+	case "jsonrpc":
+		if cfg.Blockchain.RPCURL == "" {
+			missing = append(missing, "blockchain.rpc_url")
+		}
+	default:
+		problems = append(problems, fmt.Errorf("unknown blockchain.provider %q: use alchemy or jsonrpc", cfg.Blockchain.Provider))
 	}
 
 	if len(missing) > 0 {
-		return fmt.Errorf("missing required configuration fields: %v", missing)
+		problems = append(problems, fmt.Errorf("missing required configuration fields: %v", missing))
+	}
+
+	if err := validatePort(cfg.Server.Port); err != nil {
+		problems = append(problems, fmt.Errorf("server.port: %w", err))
+	}
+	if cfg.Server.GRPCPort != "" {
+		if err := validatePort(cfg.Server.GRPCPort); err != nil {
+			problems = append(problems, fmt.Errorf("server.grpc_port: %w", err))
+		} else if cfg.Server.GRPCPort == cfg.Server.Port {
+			problems = append(problems, fmt.Errorf("server.grpc_port must differ from server.port"))
+		}
+	}
+	for _, proxy := range cfg.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				problems = append(problems, fmt.Errorf("server.trusted_proxies: %q is not an IP address or CIDR", proxy))
+			}
+		}
+	}
+	if cfg.DB.Port != "" {
+		if err := validatePort(cfg.DB.Port); err != nil {
+			problems = append(problems, fmt.Errorf("db.port: %w", err))
+		}
+	}
+
+	urls := map[string]string{
+		"blockchain.rpc_url":          cfg.Blockchain.RPCURL,
+		"blockchain.archive_url":      cfg.Blockchain.ArchiveURL,
+		"blockchain.beacon_url":       cfg.Blockchain.BeaconURL,
+		"blockchain.price_oracle_url": cfg.Blockchain.PriceOracleURL,
+		"blockchain.proxy_url":        cfg.Blockchain.ProxyURL,
+		"server.tracing_url":          cfg.Server.TracingURL,
+		"alerts.webhook_url":          cfg.Alerts.WebhookURL,
+	}
+	if cfg.Blockchain.Provider == "alchemy" {
+		urls["blockchain.alchemy_url"] = cfg.Blockchain.AlchemyAPIURL
+	}
+	if cfg.Blockchain.WSURL != "" {
+		if err := validateWebSocketURL(cfg.Blockchain.WSURL); err != nil {
+			problems = append(problems, fmt.Errorf("blockchain.ws_url: %w", err))
+		}
+	}
+	for i, rawURL := range cfg.Blockchain.FallbackRPCURLs {
+		urls[fmt.Sprintf("blockchain.fallback_rpc_urls[%d]", i)] = rawURL
+	}
+	for i, rawURL := range cfg.Blockchain.RelayURLs {
+		urls[fmt.Sprintf("blockchain.relay_urls[%d]", i)] = rawURL
+	}
+	fields := make([]string, 0, len(urls))
+	for field := range urls {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if urls[field] == "" {
+			continue
+		}
+		if err := validateURL(urls[field]); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", field, err))
+		}
+	}
+
+	if cfg.Blockchain.CallDecodeDepth < 0 || cfg.Blockchain.CallDecodeDepth > 8 {
+		problems = append(problems, fmt.Errorf("blockchain.call_decode_depth must be between 0 and 8"))
+	}
+
+	if cfg.Blockchain.RewardCeiling < 0 {
+		problems = append(problems, fmt.Errorf("blockchain.reward_ceiling must not be negative"))
+	}
+
+	if cfg.Blockchain.ValidatorMEVShare < 0 || cfg.Blockchain.ValidatorMEVShare > 1 {
+		problems = append(problems, fmt.Errorf("blockchain.validator_mev_share must be within (0, 1]"))
+	}
+
+	if cfg.Blockchain.ArbitrageMinSwaps < 0 {
+		problems = append(problems, fmt.Errorf("blockchain.arbitrage_min_swaps must not be negative"))
+	}
+
+	if cfg.Blockchain.BlockRetries < -1 {
+		problems = append(problems, fmt.Errorf("blockchain.block_retries must be -1 or more"))
+	}
+
+	if cfg.Blockchain.MaxRetries < 0 {
+		problems = append(problems, fmt.Errorf("blockchain.max_retries must not be negative"))
+	}
+
+	if cfg.Blockchain.RetryBaseDelay < 0 {
+		problems = append(problems, fmt.Errorf("blockchain.retry_base_delay must not be negative"))
+	}
+
+	if cfg.Blockchain.RPCTimeout < 0 {
+		problems = append(problems, fmt.Errorf("blockchain.rpc_timeout must not be negative"))
+	}
+
+	if cfg.Blockchain.MaxResponseBytes < 0 {
+		problems = append(problems, fmt.Errorf("blockchain.max_response_bytes must not be negative"))
+	}
+
+	if cfg.Blockchain.BreakerFailureRatio < 0 || cfg.Blockchain.BreakerFailureRatio > 1 {
+		problems = append(problems, fmt.Errorf("blockchain.breaker_failure_ratio must be within (0, 1]"))
+	}
+
+	if cfg.Blockchain.BreakerMinRequests < 0 || cfg.Blockchain.BreakerOpenTimeout < 0 {
+		problems = append(problems, fmt.Errorf("blockchain.breaker_min_requests and breaker_open_timeout must not be negative"))
+	}
+
+	if cfg.Blockchain.MaxIdleConns < 0 || cfg.Blockchain.MaxIdleConnsPerHost < 0 || cfg.Blockchain.IdleConnTimeout < 0 {
+		problems = append(problems, fmt.Errorf("blockchain.max_idle_conns, max_idle_conns_per_host and idle_conn_timeout must not be negative"))
+	}
+
+	if cfg.Blockchain.MinConcurrency < 0 || cfg.Blockchain.MaxConcurrency < 0 {
+		problems = append(problems, fmt.Errorf("blockchain.min_concurrency and max_concurrency must not be negative"))
+	}
+
+	if cfg.Blockchain.MaxConcurrency > 0 && cfg.Blockchain.MinConcurrency > cfg.Blockchain.MaxConcurrency {
+		problems = append(problems, fmt.Errorf("blockchain.min_concurrency must not exceed max_concurrency"))
+	}
+
+	if cfg.Server.ReadTimeout < 0 || cfg.Server.ReadHeaderTimeout < 0 || cfg.Server.WriteTimeout < 0 || cfg.Server.IdleTimeout < 0 {
+		problems = append(problems, fmt.Errorf("server timeouts must not be negative"))
+	}
+
+	for _, key := range cfg.Server.APIKeys {
+		if strings.TrimSpace(key) == "" {
+			problems = append(problems, fmt.Errorf("server.api_keys must not contain empty keys"))
+			break
+		}
+	}
+
+	if cfg.Server.LogLevel != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(cfg.Server.LogLevel)); err != nil {
+			problems = append(problems, fmt.Errorf("server.log_level must be debug, info, warn or error"))
+		}
+	}
+
+	if cfg.Server.RateLimit < 0 || cfg.Server.RateBurst < 0 {
+		problems = append(problems, fmt.Errorf("server.rate_limit and server.rate_burst must not be negative"))
 	}
 
+	if cfg.Server.MaxHeaderBytes < 0 {
+		problems = append(problems, fmt.Errorf("server.max_header_bytes must not be negative"))
+	}
+
+	if cfg.Server.MaxBlockRange < 0 || cfg.Server.MaxBlockRange > 100000 {
+		problems = append(problems, fmt.Errorf("server.max_block_range must be between 0 and 100000"))
+	}
+
+	if cfg.Server.RequestTimeout < 0 || cfg.Server.RequestTimeout > 5*time.Minute {
+		problems = append(problems, fmt.Errorf("server.request_timeout must be between 0 and 5m"))
+	}
+
+	if len(cfg.Blockchain.RelayURLs) > 0 && cfg.Blockchain.BeaconURL == "" {
+		problems = append(problems, fmt.Errorf("blockchain.relay_urls requires blockchain.beacon_url to resolve proposer slots"))
+	}
+
+	if cfg.Scanner.Enabled && cfg.DB.Host == "" {
+		problems = append(problems, fmt.Errorf("scanner.enabled requires db.host to persist results"))
+	}
+
+	if cfg.Alerts.WebhookURL != "" && !cfg.Scanner.Enabled {
+		problems = append(problems, fmt.Errorf("alerts.webhook_url requires scanner.enabled, as alerts are raised for scanned blocks"))
+	}
+
+	if cfg.Alerts.MinReward < 0 {
+		problems = append(problems, fmt.Errorf("alerts.min_reward must not be negative"))
+	}
+
+	if cfg.DB.MinConfidence < 0 || cfg.DB.MinConfidence > 1 {
+		problems = append(problems, fmt.Errorf("db.min_confidence must be between 0 and 1"))
+	}
+
+	if cfg.Scanner.PollInterval < 0 || cfg.Scanner.StartBlock < 0 || cfg.Scanner.MaxConcurrency < 0 {
+		problems = append(problems, fmt.Errorf("scanner.poll_interval, start_block and max_concurrency must not be negative"))
+	}
+
+	if cfg.Mempool.PollInterval < 0 {
+		problems = append(problems, fmt.Errorf("mempool.poll_interval must not be negative"))
+	}
+
+	for validatorIndex, addr := range cfg.Blockchain.FeeRecipients {
+		if !addressPattern.MatchString(addr) {
+			problems = append(problems, fmt.Errorf("invalid fee recipient for validator %d: %s", validatorIndex, addr))
+		}
+	}
+
+	if addr := cfg.Blockchain.WrappedNative; addr != "" && !addressPattern.MatchString(addr) {
+		problems = append(problems, fmt.Errorf("invalid wrapped native token address: %s", addr))
+	}
+
+	for _, addr := range cfg.Blockchain.LendingProtocols {
+		if !addressPattern.MatchString(addr) {
+			problems = append(problems, fmt.Errorf("invalid lending protocol address: %s", addr))
+		}
+	}
+	for _, selector := range cfg.Blockchain.LiquidationSelectors {
+		if !selectorPattern.MatchString(selector) {
+			problems = append(problems, fmt.Errorf("invalid liquidation selector: %s", selector))
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// validatePort checks that port is a TCP port number
+func validatePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("%q is not a port between 1 and 65535", port)
+	}
+	return nil
+}
+
+// validateURL checks that rawURL is an absolute http or https URL
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q must use http or https", rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", rawURL)
+	}
+	return nil
+}
+
+// validateWebSocketURL checks that rawURL is an absolute ws or wss URL
+func validateWebSocketURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return fmt.Errorf("%q must use ws or wss", rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", rawURL)
+	}
 	return nil
 }
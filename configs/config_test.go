@@ -0,0 +1,136 @@
+package configs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePort(t *testing.T) {
+	tests := map[string]bool{
+		"1":     true,
+		"8080":  true,
+		"65535": true,
+		"":      false,
+		"0":     false,
+		"65536": false,
+		"-1":    false,
+		"80a":   false,
+		":8080": false,
+	}
+	for port, valid := range tests {
+		if err := validatePort(port); (err == nil) != valid {
+			t.Errorf("validatePort(%q) = %v, want valid %v", port, err, valid)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := map[string]bool{
+		"http://localhost:8545":             true,
+		"https://eth-mainnet.g.alchemy.com": true,
+		"https://relay.example.com/path?q=": true,
+		"ws://localhost:8546":               false,
+		"ftp://example.com":                 false,
+		"localhost:8545":                    false,
+		"http://":                           false,
+		"/v1/traces":                        false,
+		"http://[::1":                       false,
+	}
+	for rawURL, valid := range tests {
+		if err := validateURL(rawURL); (err == nil) != valid {
+			t.Errorf("validateURL(%q) = %v, want valid %v", rawURL, err, valid)
+		}
+	}
+}
+
+func TestValidateWebSocketURL(t *testing.T) {
+	tests := map[string]bool{
+		"ws://localhost:8546":                true,
+		"wss://eth-mainnet.g.alchemy.com/v2": true,
+		"http://localhost:8545":              false,
+		"ws://":                              false,
+		"localhost:8546":                     false,
+	}
+	for rawURL, valid := range tests {
+		if err := validateWebSocketURL(rawURL); (err == nil) != valid {
+			t.Errorf("validateWebSocketURL(%q) = %v, want valid %v", rawURL, err, valid)
+		}
+	}
+}
+
+// validConfig returns a config that passes validation
+func validConfig() *Config {
+	cfg := &Config{
+		DB:         DBConfig{Password: "secret"},
+		Blockchain: BlockchainConfig{AlchemyAPIKey: "key"},
+	}
+	applyDefaults(cfg)
+	return cfg
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string // Substring of the error; empty for a valid config
+	}{
+		{"valid", func(*Config) {}, ""},
+		{"server port", func(c *Config) { c.Server.Port = "0" }, "server.port"},
+		{"grpc port", func(c *Config) { c.Server.GRPCPort = "70000" }, "server.grpc_port"},
+		{"grpc port clash", func(c *Config) { c.Server.GRPCPort = c.Server.Port }, "server.grpc_port must differ"},
+		{"db port set", func(c *Config) { c.DB.Port = "5432" }, ""},
+		{"db port", func(c *Config) { c.DB.Port = "postgres" }, "db.port"},
+		{"rpc url", func(c *Config) { c.Blockchain.RPCURL = "localhost:8545" }, "blockchain.rpc_url"},
+		{"webhook url", func(c *Config) { c.Scanner.Enabled, c.DB.Host, c.Alerts.WebhookURL = true, "db", "ftp://hooks" }, "alerts.webhook_url"},
+		{"tracing url", func(c *Config) { c.Server.TracingURL = "localhost:4318" }, "server.tracing_url"},
+		{"alchemy url", func(c *Config) { c.Blockchain.AlchemyAPIURL = "eth-mainnet.g.alchemy.com" }, "blockchain.alchemy_url"},
+		{"ws url", func(c *Config) { c.Blockchain.WSURL = "http://localhost:8546" }, "blockchain.ws_url"},
+		{"fallback url", func(c *Config) { c.Blockchain.FallbackRPCURLs = []string{"http://a", "b"} }, "blockchain.fallback_rpc_urls[1]"},
+		{"relay url", func(c *Config) { c.Blockchain.BeaconURL, c.Blockchain.RelayURLs = "http://beacon", []string{"relay"} }, "blockchain.relay_urls[0]"},
+		{"trusted proxy", func(c *Config) { c.Server.TrustedProxies = []string{"10.0.0.0/8", "proxy"} }, `"proxy" is not an IP address or CIDR`},
+		{"unknown provider", func(c *Config) { c.Blockchain.Provider = "infura" }, "unknown blockchain.provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+			err := validateConfig(cfg)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("got %v, want a valid config", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidateConfigReportsAllProblems checks that every problem is reported
+// at once, with URL problems in field order
+func TestValidateConfigReportsAllProblems(t *testing.T) {
+	cfg := validConfig()
+	cfg.DB.Password = ""
+	cfg.Server.Port = "http"
+	cfg.Blockchain.RPCURL = "ws://node"
+	cfg.Blockchain.ArchiveURL = "archive"
+	cfg.Blockchain.BeaconURL = "beacon"
+
+	err := validateConfig(cfg)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	lines := strings.Split(err.Error(), "\n")
+	want := []string{"db.password", "server.port", "blockchain.archive_url", "blockchain.beacon_url", "blockchain.rpc_url"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d problems, want %d:\n%v", len(lines), len(want), err)
+	}
+	for i, field := range want {
+		if !strings.Contains(lines[i], field) {
+			t.Errorf("problem %d = %q, want it about %s", i, lines[i], field)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package configs
+
+import "time"
+
+// Defaults for optional settings
+const (
+	DefaultPort              = "8080"
+	DefaultProvider          = "alchemy"
+	DefaultAlchemyAPIURL     = "https://eth-mainnet.g.alchemy.com"
+	DefaultAlchemyAPIVersion = "v2"
+	DefaultRPCTimeout        = 10 * time.Second
+)
+
+// applyDefaults fills unset optional fields so a minimal config works
+func applyDefaults(cfg *Config) {
+	if cfg.Server.Port == "" {
+		cfg.Server.Port = DefaultPort
+	}
+	if cfg.Blockchain.Provider == "" {
+		cfg.Blockchain.Provider = DefaultProvider
+	}
+	if cfg.Blockchain.Provider == "alchemy" && cfg.Blockchain.AlchemyAPIURL == "" {
+		cfg.Blockchain.AlchemyAPIURL = DefaultAlchemyAPIURL
+	}
+	if cfg.Blockchain.Provider == "alchemy" && cfg.Blockchain.AlchemyAPIVersion == "" {
+		cfg.Blockchain.AlchemyAPIVersion = DefaultAlchemyAPIVersion
+	}
+	if cfg.Blockchain.RPCTimeout == 0 {
+		cfg.Blockchain.RPCTimeout = DefaultRPCTimeout
+	}
+}
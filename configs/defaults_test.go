@@ -0,0 +1,116 @@
+package configs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		in   BlockchainConfig
+		want BlockchainConfig
+	}{
+		{
+			name: "empty",
+			want: BlockchainConfig{
+				Provider:          "alchemy",
+				AlchemyAPIURL:     DefaultAlchemyAPIURL,
+				AlchemyAPIVersion: DefaultAlchemyAPIVersion,
+				RPCTimeout:        DefaultRPCTimeout,
+			},
+		},
+		{
+			name: "set values kept",
+			in: BlockchainConfig{
+				AlchemyAPIURL:     "https://base-mainnet.g.alchemy.com",
+				AlchemyAPIVersion: "v3",
+				RPCTimeout:        time.Second,
+			},
+			want: BlockchainConfig{
+				Provider:          "alchemy",
+				AlchemyAPIURL:     "https://base-mainnet.g.alchemy.com",
+				AlchemyAPIVersion: "v3",
+				RPCTimeout:        time.Second,
+			},
+		},
+		{
+			name: "jsonrpc gets no alchemy defaults",
+			in:   BlockchainConfig{Provider: "jsonrpc", RPCURL: "http://localhost:8545"},
+			want: BlockchainConfig{Provider: "jsonrpc", RPCURL: "http://localhost:8545", RPCTimeout: DefaultRPCTimeout},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Blockchain: tt.in}
+			applyDefaults(cfg)
+			if cfg.Blockchain.Provider != tt.want.Provider ||
+				cfg.Blockchain.RPCURL != tt.want.RPCURL ||
+				cfg.Blockchain.AlchemyAPIURL != tt.want.AlchemyAPIURL ||
+				cfg.Blockchain.AlchemyAPIVersion != tt.want.AlchemyAPIVersion ||
+				cfg.Blockchain.RPCTimeout != tt.want.RPCTimeout {
+				t.Errorf("got %+v, want %+v", cfg.Blockchain, tt.want)
+			}
+			if cfg.Server.Port != DefaultPort {
+				t.Errorf("got port %q, want %q", cfg.Server.Port, DefaultPort)
+			}
+		})
+	}
+
+	cfg := &Config{Server: ServerConfig{Port: "9000"}}
+	applyDefaults(cfg)
+	if cfg.Server.Port != "9000" {
+		t.Errorf("got port %q, want the configured 9000", cfg.Server.Port)
+	}
+}
+
+// TestLoadConfigMinimal checks that a config with only the required fields
+// loads and validates with the defaults filled in
+func TestLoadConfigMinimal(t *testing.T) {
+	clearEnvOverrides(t)
+
+	path := writeConfig(t, "config.yaml", "db:\n  password: secret\nblockchain:\n  alchemy_key: key\n")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Server.Port != DefaultPort || cfg.Blockchain.Provider != DefaultProvider {
+		t.Errorf("got port %q provider %q, want the defaults", cfg.Server.Port, cfg.Blockchain.Provider)
+	}
+	if cfg.Blockchain.AlchemyAPIURL != DefaultAlchemyAPIURL || cfg.Blockchain.AlchemyAPIVersion != DefaultAlchemyAPIVersion {
+		t.Errorf("got alchemy url %q version %q, want the defaults", cfg.Blockchain.AlchemyAPIURL, cfg.Blockchain.AlchemyAPIVersion)
+	}
+	if cfg.Blockchain.RPCTimeout != DefaultRPCTimeout {
+		t.Errorf("got rpc timeout %v, want %v", cfg.Blockchain.RPCTimeout, DefaultRPCTimeout)
+	}
+}
+
+func TestLoadConfigMinimalJSONRPC(t *testing.T) {
+	clearEnvOverrides(t)
+
+	path := writeConfig(t, "config.yaml", "db:\n  password: secret\nblockchain:\n  provider: jsonrpc\n  rpc_url: http://localhost:8545\n")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Blockchain.AlchemyAPIURL != "" || cfg.Blockchain.AlchemyAPIVersion != "" {
+		t.Errorf("got alchemy url %q version %q for the jsonrpc provider", cfg.Blockchain.AlchemyAPIURL, cfg.Blockchain.AlchemyAPIVersion)
+	}
+}
+
+func TestLoadConfigMissingRequired(t *testing.T) {
+	clearEnvOverrides(t)
+
+	path := writeConfig(t, "config.yaml", "server:\n  port: \"8081\"\n")
+	_, err := LoadConfig(path)
+	if err == nil {
+		t.Fatal("expected an error for a config without required fields")
+	}
+	for _, field := range []string{"db.password", "blockchain.alchemy_key"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("got %v, want %s named", err, field)
+		}
+	}
+}
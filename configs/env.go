@@ -0,0 +1,75 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envReference matches ${VAR} and ${VAR:-default}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnv replaces ${VAR} references in the raw config with environment
+// values. ${VAR:-default} falls back to default when VAR is unset or empty;
+// a plain ${VAR} that is unset is an error rather than silently empty.
+func expandEnv(data []byte) ([]byte, error) {
+	var missing []string
+	expanded := envReference.ReplaceAllFunc(data, func(ref []byte) []byte {
+		m := envReference.FindSubmatch(ref)
+		name, hasDefault := string(m[1]), strings.Contains(string(ref), ":-")
+		if value := os.Getenv(name); value != "" {
+			return []byte(value)
+		}
+		if hasDefault {
+			return m[2]
+		}
+		if _, ok := os.LookupEnv(name); !ok {
+			missing = append(missing, name)
+		}
+		return nil
+	})
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("config references unset environment variables: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// envOverrides maps environment variables to the config fields they
+// replace. Non-empty values take precedence over the config file.
+var envOverrides = []struct {
+	name  string
+	field func(cfg *Config) *string
+}{
+	{"DB_HOST", func(cfg *Config) *string { return &cfg.DB.Host }},
+	{"DB_PORT", func(cfg *Config) *string { return &cfg.DB.Port }},
+	{"DB_USER", func(cfg *Config) *string { return &cfg.DB.User }},
+	{"DB_PASSWORD", func(cfg *Config) *string { return &cfg.DB.Password }},
+	{"DB_NAME", func(cfg *Config) *string { return &cfg.DB.Name }},
+	{"DB_SSLMODE", func(cfg *Config) *string { return &cfg.DB.SSLMode }},
+	{"SERVER_PORT", func(cfg *Config) *string { return &cfg.Server.Port }},
+	{"SERVER_GRPC_PORT", func(cfg *Config) *string { return &cfg.Server.GRPCPort }},
+	{"ALCHEMY_URL", func(cfg *Config) *string { return &cfg.Blockchain.AlchemyAPIURL }},
+	{"ALCHEMY_KEY", func(cfg *Config) *string { return &cfg.Blockchain.AlchemyAPIKey }},
+	{"RPC_URL", func(cfg *Config) *string { return &cfg.Blockchain.RPCURL }},
+	{"ARCHIVE_URL", func(cfg *Config) *string { return &cfg.Blockchain.ArchiveURL }},
+	{"BEACON_URL", func(cfg *Config) *string { return &cfg.Blockchain.BeaconURL }},
+}
+
+// applyEnvOverrides replaces config values with any set environment
+// overrides. SERVER_API_KEYS is a comma-separated list.
+func applyEnvOverrides(cfg *Config) {
+	for _, override := range envOverrides {
+		if value := os.Getenv(override.name); value != "" {
+			*override.field(cfg) = value
+		}
+	}
+
+	if value := os.Getenv("SERVER_API_KEYS"); value != "" {
+		cfg.Server.APIKeys = nil
+		for _, key := range strings.Split(value, ",") {
+			cfg.Server.APIKeys = append(cfg.Server.APIKeys, strings.TrimSpace(key))
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file named name to a temporary directory and
+// returns its path
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// unsetEnv unsets name for the duration of the test
+func unsetEnv(t *testing.T, name string) {
+	t.Helper()
+	t.Setenv(name, "") // Restores the original value afterwards
+	os.Unsetenv(name)
+}
+
+// clearEnvOverrides unsets every override variable for the test, so the
+// environment running it cannot change what a config file loads as
+func clearEnvOverrides(t *testing.T) {
+	t.Helper()
+	for _, override := range envOverrides {
+		unsetEnv(t, override.name)
+	}
+	unsetEnv(t, "SERVER_API_KEYS")
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("MEV_TEST_SET", "secret")
+	t.Setenv("MEV_TEST_EMPTY", "")
+	unsetEnv(t, "MEV_TEST_UNSET")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"set", "password: ${MEV_TEST_SET}", "password: secret"},
+		{"set with default", "password: ${MEV_TEST_SET:-fallback}", "password: secret"},
+		{"unset with default", "password: ${MEV_TEST_UNSET:-fallback}", "password: fallback"},
+		{"empty with default", "password: ${MEV_TEST_EMPTY:-fallback}", "password: fallback"},
+		{"empty default", "password: ${MEV_TEST_UNSET:-}", "password: "},
+		{"default with punctuation", "url: ${MEV_TEST_UNSET:-http://localhost:8545/?a=b}", "url: http://localhost:8545/?a=b"},
+		{"set but empty", "password: ${MEV_TEST_EMPTY}", "password: "},
+		{"several references", "dsn: ${MEV_TEST_SET}@${MEV_TEST_UNSET:-localhost}", "dsn: secret@localhost"},
+		{"not a reference", "password: $MEV_TEST_SET ${} ${1X}", "password: $MEV_TEST_SET ${} ${1X}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandEnv([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("expandEnv: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandEnvMissing(t *testing.T) {
+	unsetEnv(t, "MEV_TEST_MISSING_A")
+	unsetEnv(t, "MEV_TEST_MISSING_B")
+
+	_, err := expandEnv([]byte("user: ${MEV_TEST_MISSING_A}\npassword: ${MEV_TEST_MISSING_B}\nname: ${MEV_TEST_MISSING_C:-mev}"))
+	if err == nil {
+		t.Fatal("expected an error for unset variables")
+	}
+	if !strings.Contains(err.Error(), "MEV_TEST_MISSING_A, MEV_TEST_MISSING_B") {
+		t.Errorf("got %v, want both missing variables named", err)
+	}
+	if strings.Contains(err.Error(), "MEV_TEST_MISSING_C") {
+		t.Errorf("got %v, which names a variable with a default", err)
+	}
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_PASSWORD", "from-env")
+	t.Setenv("SERVER_GRPC_PORT", "9090")
+	t.Setenv("DB_USER", "") // Empty values leave the file's value
+	t.Setenv("SERVER_API_KEYS", " key-a, key-b ")
+	unsetEnv(t, "DB_NAME")
+
+	cfg := &Config{
+		DB:     DBConfig{Host: "localhost", User: "mev", Password: "from-file", Name: "mev"},
+		Server: ServerConfig{APIKeys: []string{"file-key"}},
+	}
+	applyEnvOverrides(cfg)
+
+	want := DBConfig{Host: "db.internal", User: "mev", Password: "from-env", Name: "mev"}
+	if cfg.DB != want {
+		t.Errorf("got db %+v, want %+v", cfg.DB, want)
+	}
+	if cfg.Server.GRPCPort != "9090" {
+		t.Errorf("got grpc port %q, want 9090", cfg.Server.GRPCPort)
+	}
+	if !reflect.DeepEqual(cfg.Server.APIKeys, []string{"key-a", "key-b"}) {
+		t.Errorf("got api keys %q, want the environment's", cfg.Server.APIKeys)
+	}
+}
+
+// TestLoadConfigEnvPrecedence checks that overrides win over values in the
+// file, including values expanded from other variables
+func TestLoadConfigEnvPrecedence(t *testing.T) {
+	clearEnvOverrides(t)
+	t.Setenv("MEV_TEST_DB_PASSWORD", "expanded")
+	t.Setenv("ALCHEMY_KEY", "override-key")
+	t.Setenv("SERVER_PORT", "9000")
+
+	path := writeConfig(t, "config.yaml", `
+db:
+  password: ${MEV_TEST_DB_PASSWORD}
+  name: ${MEV_TEST_DB_NAME:-mev}
+server:
+  port: "8081"
+blockchain:
+  alchemy_key: file-key
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.DB.Password != "expanded" || cfg.DB.Name != "mev" {
+		t.Errorf("got db password %q name %q, want expanded values", cfg.DB.Password, cfg.DB.Name)
+	}
+	if cfg.Blockchain.AlchemyAPIKey != "override-key" || cfg.Server.Port != "9000" {
+		t.Errorf("got alchemy key %q port %q, want the overrides", cfg.Blockchain.AlchemyAPIKey, cfg.Server.Port)
+	}
+}
+
+func TestLoadConfigMissingVariable(t *testing.T) {
+	clearEnvOverrides(t)
+	unsetEnv(t, "MEV_TEST_MISSING_KEY")
+
+	path := writeConfig(t, "config.yaml", "db:\n  password: secret\nblockchain:\n  alchemy_key: ${MEV_TEST_MISSING_KEY}\n")
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "MEV_TEST_MISSING_KEY") {
+		t.Errorf("got %v, want an error naming MEV_TEST_MISSING_KEY", err)
+	}
+}
@@ -0,0 +1,130 @@
+package configs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsJSONConfig(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		want bool
+	}{
+		{"config.json", "db:\n  password: secret", true},
+		{"CONFIG.JSON", "", true},
+		{"config.yaml", `{"db": {}}`, false},
+		{"config.yml", `{"db": {}}`, false},
+		{"config", "\n  {\"db\": {}}", true},
+		{"config.conf", "db:\n  password: secret", false},
+		{"config", "", false},
+	}
+	for _, tt := range tests {
+		if got := isJSONConfig(tt.path, []byte(tt.data)); got != tt.want {
+			t.Errorf("isJSONConfig(%q, %q) = %v, want %v", tt.path, tt.data, got, tt.want)
+		}
+	}
+}
+
+const jsonConfig = `{
+  "db": {"password": "secret", "port": "5432", "min_confidence": 0.5},
+  "server": {"port": "8081", "request_timeout": "5s", "api_keys": ["key-a", "key-b"], "enable_pprof": true},
+  "blockchain": {
+    "alchemy_key": "key",
+    "rpc_timeout": "1500ms",
+    "max_response_bytes": 1048576,
+    "fee_recipients": {"12": "0x388c818ca8b9251b393131c08a736a67ccb19297"},
+    "lending_protocols": ["0x87870bca3f3fd6335c3f4ce8392d69350b4fa4e2"],
+    "liquidation_selectors": ["0x00a718a9"],
+    "chain": null
+  }
+}`
+
+const yamlConfig = `
+db:
+  password: secret
+  port: "5432"
+  min_confidence: 0.5
+server:
+  port: "8081"
+  request_timeout: 5s
+  api_keys: [key-a, key-b]
+  enable_pprof: true
+blockchain:
+  alchemy_key: key
+  rpc_timeout: 1500ms
+  max_response_bytes: 1048576
+  fee_recipients:
+    12: "0x388c818ca8b9251b393131c08a736a67ccb19297"
+  lending_protocols: ["0x87870bca3f3fd6335c3f4ce8392d69350b4fa4e2"]
+  liquidation_selectors: ["0x00a718a9"]
+`
+
+func TestLoadConfigJSON(t *testing.T) {
+	clearEnvOverrides(t)
+
+	cfg, err := LoadConfig(writeConfig(t, "config.json", jsonConfig))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Server.RequestTimeout != 5*time.Second || cfg.Blockchain.RPCTimeout != 1500*time.Millisecond {
+		t.Errorf("got timeouts %v and %v, want 5s and 1.5s", cfg.Server.RequestTimeout, cfg.Blockchain.RPCTimeout)
+	}
+	if cfg.Blockchain.MaxResponseBytes != 1<<20 || cfg.DB.MinConfidence != 0.5 || !cfg.Server.EnablePprof {
+		t.Errorf("got max response bytes %d min confidence %v pprof %v", cfg.Blockchain.MaxResponseBytes, cfg.DB.MinConfidence, cfg.Server.EnablePprof)
+	}
+	if want := map[int]string{12: "0x388c818ca8b9251b393131c08a736a67ccb19297"}; !reflect.DeepEqual(cfg.Blockchain.FeeRecipients, want) {
+		t.Errorf("got fee recipients %v, want %v", cfg.Blockchain.FeeRecipients, want)
+	}
+	if cfg.Blockchain.Chain != "" {
+		t.Errorf("got chain %q for null", cfg.Blockchain.Chain)
+	}
+}
+
+// TestLoadConfigJSONMatchesYAML checks that the same settings load the same
+// way from either format
+func TestLoadConfigJSONMatchesYAML(t *testing.T) {
+	clearEnvOverrides(t)
+
+	fromJSON, err := LoadConfig(writeConfig(t, "config.json", jsonConfig))
+	if err != nil {
+		t.Fatalf("LoadConfig JSON: %v", err)
+	}
+	fromYAML, err := LoadConfig(writeConfig(t, "config.yaml", yamlConfig))
+	if err != nil {
+		t.Fatalf("LoadConfig YAML: %v", err)
+	}
+	if !reflect.DeepEqual(fromJSON, fromYAML) {
+		t.Errorf("JSON loaded as\n%+v\nYAML as\n%+v", fromJSON, fromYAML)
+	}
+
+	// Detected by content when the extension says nothing
+	fromContent, err := LoadConfig(writeConfig(t, "config", jsonConfig))
+	if err != nil {
+		t.Fatalf("LoadConfig without extension: %v", err)
+	}
+	if !reflect.DeepEqual(fromContent, fromJSON) {
+		t.Errorf("content-detected JSON loaded as\n%+v", fromContent)
+	}
+}
+
+func TestLoadConfigJSONErrors(t *testing.T) {
+	clearEnvOverrides(t)
+
+	tests := map[string]string{
+		"syntax":        `{"db": {"password": "secret"`,
+		"wrong type":    `{"db": {"password": "secret"}, "blockchain": {"alchemy_key": "key", "max_retries": "many"}}`,
+		"bad duration":  `{"db": {"password": "secret"}, "blockchain": {"alchemy_key": "key", "rpc_timeout": "soon"}}`,
+		"validator key": `{"db": {"password": "secret"}, "blockchain": {"alchemy_key": "key", "fee_recipients": {"one": "0x388c818ca8b9251b393131c08a736a67ccb19297"}}}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, "config.json", data))
+			if err == nil || !strings.Contains(err.Error(), "as JSON") {
+				t.Errorf("got %v, want a JSON parse error", err)
+			}
+		})
+	}
+}
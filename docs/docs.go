@@ -0,0 +1,12 @@
+// Package docs holds the OpenAPI specification generated from the handler
+// annotations
+package docs
+
+import _ "embed"
+
+//go:generate go run gen.go
+
+// SwaggerJSON is the Swagger 2.0 specification of the API
+//
+//go:embed swagger.json
+var SwaggerJSON []byte
@@ -0,0 +1,497 @@
+//go:build ignore
+
+// gen builds swagger.json from the swaggo annotations on the API handlers
+// and the response models they reference. Run it with go generate.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	apiDir    = "../internal/api"
+	modelsDir = "../internal/models"
+	mainFile  = "../cmd/main.go"
+	outFile   = "swagger.json"
+)
+
+// paramPattern matches: name in type required "description"
+var paramPattern = regexp.MustCompile(`^(\S+)\s+(\S+)\s+(\S+)\s+(true|false)\s+"(.*)"$`)
+
+// responsePattern matches: code {object} models.Type, or a bare code
+var responsePattern = regexp.MustCompile(`^(\d+)(?:\s+\{(\w+)\}\s+(\S+))?(?:\s+"(.*)")?$`)
+
+// routerPattern matches: /path [method]
+var routerPattern = regexp.MustCompile(`^(\S+)\s+\[(\w+)\]$`)
+
+func main() {
+	models, err := parseModels(modelsDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g := &generator{models: models, definitions: make(map[string]interface{})}
+
+	info, err := parseInfo(mainFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	paths, err := g.parseRoutes(apiDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	spec := map[string]interface{}{
+		"swagger":     "2.0",
+		"info":        info,
+		"paths":       paths,
+		"definitions": g.definitions,
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(spec); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(outFile, buf.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	models      map[string]*ast.TypeSpec
+	definitions map[string]interface{}
+}
+
+// parseInfo reads the general API annotations (@title, @version,
+// @description) from the comment above package main
+func parseInfo(path string) (map[string]interface{}, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	info := map[string]interface{}{}
+	for _, group := range file.Comments {
+		for _, line := range strings.Split(group.Text(), "\n") {
+			key, value, ok := annotation(line)
+			if !ok {
+				continue
+			}
+			switch key {
+			case "@title":
+				info["title"] = value
+			case "@version":
+				info["version"] = value
+			case "@description":
+				info["description"] = value
+			}
+		}
+	}
+	if info["title"] == nil {
+		return nil, fmt.Errorf("%s: missing @title annotation", path)
+	}
+	return info, nil
+}
+
+// parseRoutes builds the paths object from every annotated function in dir
+func (g *generator) parseRoutes(dir string) (map[string]interface{}, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]interface{}{}
+	for _, pkg := range pkgs {
+		for name, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Doc == nil {
+					continue
+				}
+				path, method, op, err := g.parseOperation(fn.Doc.Text())
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s: %w", name, fn.Name.Name, err)
+				}
+				if path == "" {
+					continue
+				}
+				item, _ := paths[path].(map[string]interface{})
+				if item == nil {
+					item = map[string]interface{}{}
+					paths[path] = item
+				}
+				item[method] = op
+			}
+		}
+	}
+	return paths, nil
+}
+
+// parseOperation converts one handler's annotations to an operation object.
+// It returns an empty path for functions without @Router.
+func (g *generator) parseOperation(doc string) (string, string, map[string]interface{}, error) {
+	var path, method string
+	op := map[string]interface{}{}
+	var params []interface{}
+	responses := map[string]interface{}{}
+
+	for _, line := range strings.Split(doc, "\n") {
+		key, value, ok := annotation(line)
+		if !ok {
+			continue
+		}
+		switch key {
+		case "@Summary":
+			op["summary"] = value
+		case "@Description":
+			op["description"] = value
+		case "@Tags":
+			op["tags"] = splitList(value)
+		case "@Accept":
+			op["consumes"] = mimeTypes(value)
+		case "@Produce":
+			op["produces"] = mimeTypes(value)
+		case "@Param":
+			param, err := g.parseParam(value)
+			if err != nil {
+				return "", "", nil, err
+			}
+			params = append(params, param)
+		case "@Success", "@Failure":
+			code, response, err := g.parseResponse(value)
+			if err != nil {
+				return "", "", nil, err
+			}
+			responses[code] = response
+		case "@Router":
+			m := routerPattern.FindStringSubmatch(value)
+			if m == nil {
+				return "", "", nil, fmt.Errorf("malformed @Router %q", value)
+			}
+			path, method = m[1], strings.ToLower(m[2])
+		}
+	}
+
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	op["responses"] = responses
+	return path, method, op, nil
+}
+
+func (g *generator) parseParam(value string) (map[string]interface{}, error) {
+	m := paramPattern.FindStringSubmatch(value)
+	if m == nil {
+		return nil, fmt.Errorf("malformed @Param %q", value)
+	}
+	param := map[string]interface{}{
+		"name":        m[1],
+		"in":          m[2],
+		"required":    m[4] == "true",
+		"description": m[5],
+	}
+	if m[2] == "body" {
+		schema, err := g.typeRef(m[3])
+		if err != nil {
+			return nil, err
+		}
+		param["schema"] = schema
+		return param, nil
+	}
+	param["type"] = primitive(m[3])
+	return param, nil
+}
+
+func (g *generator) parseResponse(value string) (string, map[string]interface{}, error) {
+	m := responsePattern.FindStringSubmatch(value)
+	if m == nil {
+		return "", nil, fmt.Errorf("malformed response %q", value)
+	}
+	code, kind, typ, description := m[1], m[2], m[3], m[4]
+
+	if description == "" {
+		n, _ := strconv.Atoi(code)
+		description = http.StatusText(n)
+	}
+	response := map[string]interface{}{"description": description}
+	if typ == "" {
+		return code, response, nil
+	}
+
+	schema, err := g.typeRef(typ)
+	if err != nil {
+		return "", nil, err
+	}
+	if kind == "array" {
+		schema = map[string]interface{}{"type": "array", "items": schema}
+	}
+	response["schema"] = schema
+	return code, response, nil
+}
+
+// typeRef returns a schema for a models.Name reference, adding the
+// definition and those it depends on
+func (g *generator) typeRef(name string) (map[string]interface{}, error) {
+	local := strings.TrimPrefix(name, "models.")
+	if _, ok := g.models[local]; !ok {
+		return nil, fmt.Errorf("unknown type %q", name)
+	}
+	if err := g.define(local); err != nil {
+		return nil, err
+	}
+	return ref(local), nil
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/definitions/models." + name}
+}
+
+// define adds the named model to definitions
+func (g *generator) define(name string) error {
+	key := "models." + name
+	if _, ok := g.definitions[key]; ok {
+		return nil
+	}
+	g.definitions[key] = nil // Placeholder so recursive types terminate
+
+	schema, err := g.schema(g.models[name].Type)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	g.definitions[key] = schema
+	return nil
+}
+
+// schema converts a Go type expression from the models package to a schema
+func (g *generator) schema(expr ast.Expr) (map[string]interface{}, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return map[string]interface{}{"type": "string"}, nil
+		case "bool":
+			return map[string]interface{}{"type": "boolean"}, nil
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return map[string]interface{}{"type": "integer"}, nil
+		case "float32", "float64":
+			return map[string]interface{}{"type": "number"}, nil
+		}
+		spec, ok := g.models[t.Name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported type %s", t.Name)
+		}
+		if _, isStruct := spec.Type.(*ast.StructType); !isStruct {
+			return g.schema(spec.Type)
+		}
+		if err := g.define(t.Name); err != nil {
+			return nil, err
+		}
+		return ref(t.Name), nil
+	case *ast.StarExpr:
+		return g.schema(t.X)
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "time" && t.Sel.Name == "Time" {
+			return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+		}
+		return nil, fmt.Errorf("unsupported type %s", fmt.Sprintf("%T", t))
+	case *ast.ArrayType:
+		items, err := g.schema(t.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case *ast.MapType:
+		values, err := g.schema(t.Value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case *ast.StructType:
+		return g.structSchema(t)
+	case *ast.InterfaceType:
+		// Any JSON value
+		return map[string]interface{}{}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", fmt.Sprintf("%T", expr))
+}
+
+func (g *generator) structSchema(st *ast.StructType) (map[string]interface{}, error) {
+	properties := map[string]interface{}{}
+	var required []string
+
+	for _, field := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+		}
+		jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+
+		// Embedded structs contribute their fields inline
+		if len(field.Names) == 0 {
+			embedded, err := g.embeddedSchema(field.Type)
+			if err != nil {
+				return nil, err
+			}
+			for name, prop := range embedded["properties"].(map[string]interface{}) {
+				properties[name] = prop
+			}
+			if names, ok := embedded["required"].([]string); ok {
+				required = append(required, names...)
+			}
+			continue
+		}
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			prop, err := g.schema(field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name.Name, err)
+			}
+			if comment := fieldComment(field); comment != "" && prop["$ref"] == nil {
+				prop["description"] = comment
+			}
+
+			propName := jsonName
+			if propName == "" {
+				propName = name.Name
+			}
+			properties[propName] = prop
+			if strings.Contains(tag.Get("binding"), "required") {
+				required = append(required, propName)
+			}
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// embeddedSchema returns the object schema of an embedded models struct
+func (g *generator) embeddedSchema(expr ast.Expr) (map[string]interface{}, error) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil, fmt.Errorf("unsupported embedded type %T", expr)
+	}
+	spec, ok := g.models[ident.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown embedded type %s", ident.Name)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("embedded type %s is not a struct", ident.Name)
+	}
+	return g.structSchema(st)
+}
+
+// parseModels indexes the type declarations of the models package
+func parseModels(dir string) (map[string]*ast.TypeSpec, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	models := map[string]*ast.TypeSpec{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					models[ts.Name.Name] = ts
+				}
+			}
+		}
+	}
+	return models, nil
+}
+
+// annotation splits "@Key value" lines
+func annotation(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "@") {
+		return "", "", false
+	}
+	key, value, _ := strings.Cut(line, " ")
+	return key, strings.TrimSpace(value), true
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// mimeTypes expands swaggo's short MIME aliases
+func mimeTypes(value string) []string {
+	aliases := map[string]string{
+		"json":  "application/json",
+		"plain": "text/plain",
+		"html":  "text/html",
+	}
+	items := splitList(value)
+	for i, item := range items {
+		if full, ok := aliases[item]; ok {
+			items[i] = full
+		}
+	}
+	return items
+}
+
+// primitive maps a parameter type to a Swagger primitive type
+func primitive(typ string) string {
+	switch typ {
+	case "int", "int64", "integer":
+		return "integer"
+	case "float64", "number":
+		return "number"
+	case "bool", "boolean":
+		return "boolean"
+	}
+	return "string"
+}
+
+func fieldComment(field *ast.Field) string {
+	if field.Comment != nil {
+		return strings.TrimSpace(field.Comment.Text())
+	}
+	if field.Doc != nil {
+		return strings.TrimSpace(field.Doc.Text())
+	}
+	return ""
+}
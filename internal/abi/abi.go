@@ -0,0 +1,244 @@
+package abi
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+)
+
+// Method is a contract method identified by its 4-byte selector
+type Method struct {
+	Name      string
+	Signature string
+}
+
+// methods is a bundled 4byte-style table of selectors commonly seen in MEV
+// transactions
+var methods = map[string]Method{
+	// ERC-20 and WETH
+	"0xa9059cbb": {"transfer", "transfer(address,uint256)"},
+	"0x095ea7b3": {"approve", "approve(address,uint256)"},
+	"0x23b872dd": {"transferFrom", "transferFrom(address,address,uint256)"},
+	"0xd0e30db0": {"deposit", "deposit()"},
+	"0x2e1a7d4d": {"withdraw", "withdraw(uint256)"},
+
+	// Uniswap V2 router and pairs
+	"0x38ed1739": {"swapExactTokensForTokens", "swapExactTokensForTokens(uint256,uint256,address[],address,uint256)"},
+	"0x8803dbee": {"swapTokensForExactTokens", "swapTokensForExactTokens(uint256,uint256,address[],address,uint256)"},
+	"0x7ff36ab5": {"swapExactETHForTokens", "swapExactETHForTokens(uint256,address[],address,uint256)"},
+	"0x18cbafe5": {"swapExactTokensForETH", "swapExactTokensForETH(uint256,uint256,address[],address,uint256)"},
+	"0xfb3bdb41": {"swapETHForExactTokens", "swapETHForExactTokens(uint256,address[],address,uint256)"},
+	"0x4a25d94a": {"swapTokensForExactETH", "swapTokensForExactETH(uint256,uint256,address[],address,uint256)"},
+	"0x5c11d795": {"swapExactTokensForTokensSupportingFeeOnTransferTokens", "swapExactTokensForTokensSupportingFeeOnTransferTokens(uint256,uint256,address[],address,uint256)"},
+	"0xb6f9de95": {"swapExactETHForTokensSupportingFeeOnTransferTokens", "swapExactETHForTokensSupportingFeeOnTransferTokens(uint256,address[],address,uint256)"},
+	"0x791ac947": {"swapExactTokensForETHSupportingFeeOnTransferTokens", "swapExactTokensForETHSupportingFeeOnTransferTokens(uint256,uint256,address[],address,uint256)"},
+	"0x022c0d9f": {"swap", "swap(uint256,uint256,address,bytes)"},
+
+	// Uniswap V3 SwapRouter and SwapRouter02
+	"0x414bf389": {"exactInputSingle", "exactInputSingle((address,address,uint24,address,uint256,uint256,uint256,uint160))"},
+	"0xc04b8d59": {"exactInput", "exactInput((bytes,address,uint256,uint256,uint256))"},
+	"0xdb3e2198": {"exactOutputSingle", "exactOutputSingle((address,address,uint24,address,uint256,uint256,uint256,uint160))"},
+	"0xf28c0498": {"exactOutput", "exactOutput((bytes,address,uint256,uint256,uint256))"},
+	"0x04e45aaf": {"exactInputSingle", "exactInputSingle((address,address,uint24,address,uint256,uint256,uint160))"},
+	"0xb858183f": {"exactInput", "exactInput((bytes,address,uint256,uint256))"},
+	"0x09b81346": {"exactOutput", "exactOutput((bytes,address,uint256,uint256))"},
+
+	// Multicall wrappers and the Universal Router
+	"0xac9650d8": {"multicall", "multicall(bytes[])"},
+	"0x5ae401dc": {"multicall", "multicall(uint256,bytes[])"},
+	"0x1f0464d1": {"multicall", "multicall(bytes32,bytes[])"},
+	"0x24856bc3": {"execute", "execute(bytes,bytes[])"},
+	"0x3593564c": {"execute", "execute(bytes,bytes[],uint256)"},
+
+	// Lending protocols
+	"0x00a718a9": {"liquidationCall", "liquidationCall(address,address,address,uint256,bool)"},
+	"0xf5e3c462": {"liquidateBorrow", "liquidateBorrow(address,uint256,address)"},
+	"0xaae40a2a": {"liquidateBorrow", "liquidateBorrow(address,address)"},
+	"0xab9c4b5d": {"flashLoan", "flashLoan(address,address[],uint256[],uint256[],address,bytes,uint16)"},
+	"0x42b0b77c": {"flashLoanSimple", "flashLoanSimple(address,address,uint256,bytes,uint16)"},
+}
+
+// Selector returns the lowercased 0x-prefixed 4-byte selector of transaction
+// input. It reports false for empty, truncated or non-hex input.
+func Selector(input string) (string, bool) {
+	data := strings.TrimPrefix(strings.TrimPrefix(input, "0x"), "0X")
+	if len(data) < 8 {
+		return "", false
+	}
+
+	selector := strings.ToLower(data[:8])
+	if _, err := hex.DecodeString(selector); err != nil {
+		return "", false
+	}
+	return "0x" + selector, true
+}
+
+// DecodeMethod returns the selector of transaction input and the name of the
+// method it calls. The selector is returned even when the method is unknown;
+// ok reports whether it was found in the bundled table.
+func DecodeMethod(input string) (selector string, name string, ok bool) {
+	selector, ok = Selector(input)
+	if !ok {
+		return "", "", false
+	}
+
+	method, ok := methods[selector]
+	if !ok {
+		return selector, "", false
+	}
+	return selector, method.Name, true
+}
+
+// LookupMethod returns the bundled method for a selector
+func LookupMethod(selector string) (Method, bool) {
+	method, ok := methods[strings.ToLower(selector)]
+	return method, ok
+}
+
+// DecodeArgs decodes the arguments of a call to a known method, formatting
+// addresses as lowercase hex, integers in decimal and arrays as [a,b]. It
+// reports false for unknown methods, for argument shapes it cannot decode
+// (tuples, bytes) and for truncated input.
+func DecodeArgs(input string) ([]string, bool) {
+	selector, _, ok := DecodeMethod(input)
+	if !ok {
+		return nil, false
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(input), "0x"))
+	if err != nil {
+		return nil, false
+	}
+	args := data[4:]
+
+	types := paramTypes(methods[selector].Signature)
+	values := make([]string, len(types))
+	for i, typ := range types {
+		value, ok := decodeArg(args, i, typ)
+		if !ok {
+			return nil, false
+		}
+		values[i] = value
+	}
+
+	return values, true
+}
+
+// paramTypes returns the parameter types of a signature, or nil when it has
+// none. Tuple parameters are returned whole and are not decodable.
+func paramTypes(signature string) []string {
+	start := strings.IndexByte(signature, '(')
+	params := signature[start+1 : len(signature)-1]
+	if params == "" {
+		return nil
+	}
+
+	var (
+		types []string
+		depth int
+		last  int
+	)
+	for i, r := range params {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				types = append(types, params[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(types, params[last:])
+}
+
+// decodeArg decodes the argument of type typ in head slot i
+func decodeArg(args []byte, i int, typ string) (string, bool) {
+	switch {
+	case typ == "address":
+		return address(args, i*32)
+	case typ == "bool":
+		word, ok := uint256(args, i*32)
+		if !ok || word.Cmp(big.NewInt(1)) > 0 {
+			return "", false
+		}
+		if word.Sign() == 0 {
+			return "false", true
+		}
+		return "true", true
+	case typ == "bytes32":
+		if i*32+32 > len(args) {
+			return "", false
+		}
+		return "0x" + hex.EncodeToString(args[i*32:i*32+32]), true
+	case strings.HasPrefix(typ, "uint") && !strings.HasSuffix(typ, "]"):
+		word, ok := uint256(args, i*32)
+		if !ok {
+			return "", false
+		}
+		return word.String(), true
+	case typ == "address[]" || typ == "uint256[]":
+		return array(args, i, strings.TrimSuffix(typ, "[]"))
+	}
+	return "", false
+}
+
+// array decodes a dynamic array of static elements whose offset is in head
+// slot i
+func array(args []byte, i int, elem string) (string, bool) {
+	offset, ok := smallInt(args, i*32)
+	if !ok {
+		return "", false
+	}
+	count, ok := smallInt(args, offset)
+	if !ok || offset+32+count*32 > len(args) {
+		return "", false
+	}
+
+	values := make([]string, count)
+	for j := range values {
+		var value string
+		if elem == "address" {
+			value, ok = address(args, offset+32+j*32)
+		} else {
+			var word *big.Int
+			word, ok = uint256(args, offset+32+j*32)
+			if ok {
+				value = word.String()
+			}
+		}
+		if !ok {
+			return "", false
+		}
+		values[j] = value
+	}
+
+	return "[" + strings.Join(values, ",") + "]", true
+}
+
+// uint256 reads the 32-byte word at offset
+func uint256(data []byte, offset int) (*big.Int, bool) {
+	if offset < 0 || offset+32 > len(data) {
+		return nil, false
+	}
+	return new(big.Int).SetBytes(data[offset : offset+32]), true
+}
+
+// smallInt reads the word at offset as an offset or length within data
+func smallInt(data []byte, offset int) (int, bool) {
+	word, ok := uint256(data, offset)
+	if !ok || !word.IsInt64() || word.Int64() > int64(len(data)) {
+		return 0, false
+	}
+	return int(word.Int64()), true
+}
+
+// address reads the address stored in the word at offset
+func address(data []byte, offset int) (string, bool) {
+	if offset < 0 || offset+32 > len(data) {
+		return "", false
+	}
+	return "0x" + hex.EncodeToString(data[offset+12:offset+32]), true
+}
@@ -0,0 +1,176 @@
+package abi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// word left-pads a hex value without 0x to a 32-byte ABI word
+func word(v string) string {
+	return strings.Repeat("0", 64-len(v)) + v
+}
+
+// uintWord encodes n as a 32-byte ABI word
+func uintWord(n uint64) string {
+	return word(fmt.Sprintf("%x", n))
+}
+
+const (
+	weth = "c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	usdc = "a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+)
+
+func TestSelector(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{"0xa9059cbb" + word(weth), "0xa9059cbb", true},
+		{"0xA9059CBB", "0xa9059cbb", true},
+		{"0Xa9059cbb", "0xa9059cbb", true},
+		{"a9059cbb", "0xa9059cbb", true},
+		{"", "", false},
+		{"0x", "", false},
+		{"0xa9059c", "", false},
+		{"0xa9059czz", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Selector(tt.input)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Selector(%q) = %q, %v; want %q, %v", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestDecodeMethod(t *testing.T) {
+	tests := []struct {
+		input        string
+		wantSelector string
+		wantName     string
+		wantOK       bool
+	}{
+		{"0x022c0d9f", "0x022c0d9f", "swap", true},
+		{"0x00A718A9" + word(weth), "0x00a718a9", "liquidationCall", true},
+		{"0xdeadbeef", "0xdeadbeef", "", false},
+		{"0x", "", "", false},
+	}
+	for _, tt := range tests {
+		selector, name, ok := DecodeMethod(tt.input)
+		if selector != tt.wantSelector || name != tt.wantName || ok != tt.wantOK {
+			t.Errorf("DecodeMethod(%q) = %q, %q, %v; want %q, %q, %v", tt.input, selector, name, ok, tt.wantSelector, tt.wantName, tt.wantOK)
+		}
+	}
+}
+
+func TestLookupMethod(t *testing.T) {
+	method, ok := LookupMethod("0x5AE401DC")
+	if !ok || method.Name != "multicall" || method.Signature != "multicall(uint256,bytes[])" {
+		t.Errorf("got %+v, %v; want multicall(uint256,bytes[])", method, ok)
+	}
+	if _, ok := LookupMethod("0xdeadbeef"); ok {
+		t.Error("found an unknown selector")
+	}
+}
+
+func TestDecodeArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   []string
+		wantOK bool
+	}{
+		{
+			name:   "transfer",
+			input:  "0xa9059cbb" + word(usdc) + uintWord(1000000),
+			want:   []string{"0x" + usdc, "1000000"},
+			wantOK: true,
+		},
+		{
+			name:   "no arguments",
+			input:  "0xd0e30db0",
+			want:   []string{},
+			wantOK: true,
+		},
+		{
+			name: "address array",
+			input: "0x38ed1739" + uintWord(1000) + uintWord(990) + uintWord(5*32) + word("1111111111111111111111111111111111111111") + uintWord(1700000000) +
+				uintWord(2) + word(weth) + word(usdc),
+			want:   []string{"1000", "990", "[0x" + weth + ",0x" + usdc + "]", "0x1111111111111111111111111111111111111111", "1700000000"},
+			wantOK: true,
+		},
+		{
+			name:   "bool",
+			input:  "0x00a718a9" + word(weth) + word(usdc) + word("2222222222222222222222222222222222222222") + uintWord(5000) + uintWord(1),
+			want:   []string{"0x" + weth, "0x" + usdc, "0x2222222222222222222222222222222222222222", "5000", "true"},
+			wantOK: true,
+		},
+		{
+			name:  "bool out of range",
+			input: "0x00a718a9" + word(weth) + word(usdc) + word("2222222222222222222222222222222222222222") + uintWord(5000) + uintWord(2),
+		},
+		{
+			name:  "truncated",
+			input: "0xa9059cbb" + word(usdc),
+		},
+		{
+			name:  "array past the end",
+			input: "0x38ed1739" + uintWord(1000) + uintWord(990) + uintWord(5*32) + word("11") + uintWord(1700000000) + uintWord(3) + word(weth),
+		},
+		{
+			name:  "tuple",
+			input: "0x414bf389" + strings.Repeat(uintWord(1), 8),
+		},
+		{
+			name:  "bytes",
+			input: "0x022c0d9f" + uintWord(0) + uintWord(1) + word("11") + uintWord(4*32) + uintWord(0),
+		},
+		{
+			name:  "unknown method",
+			input: "0xdeadbeef" + uintWord(1),
+		},
+		{
+			name:  "non-hex arguments",
+			input: "0xa9059cbb" + strings.Repeat("z", 128),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DecodeArgs(tt.input)
+			if ok != tt.wantOK || ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParamTypes(t *testing.T) {
+	tests := map[string][]string{
+		"deposit()":                 nil,
+		"withdraw(uint256)":         {"uint256"},
+		"transfer(address,uint256)": {"address", "uint256"},
+		"exactInput((bytes,address,uint256,uint256))": {"(bytes,address,uint256,uint256)"},
+		"execute(bytes,bytes[],uint256)":              {"bytes", "bytes[]", "uint256"},
+	}
+	for signature, want := range tests {
+		if got := paramTypes(signature); !reflect.DeepEqual(got, want) {
+			t.Errorf("paramTypes(%q) = %q, want %q", signature, got, want)
+		}
+	}
+}
+
+// TestMethodSignatures checks that every bundled method's name matches its
+// signature
+func TestMethodSignatures(t *testing.T) {
+	for selector, method := range methods {
+		if !strings.HasPrefix(method.Signature, method.Name+"(") || !strings.HasSuffix(method.Signature, ")") {
+			t.Errorf("%s: name %q does not match signature %q", selector, method.Name, method.Signature)
+		}
+		if got, ok := Selector(selector); !ok || got != selector || len(selector) != 10 {
+			t.Errorf("%s is not a lowercase 4-byte selector", selector)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package alerts notifies external services about notable scanned blocks.
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// Delivery defaults for webhook alerts
+const (
+	DefaultMaxAttempts = 3
+	DefaultRetryDelay  = time.Second
+	DefaultTimeout     = 10 * time.Second
+	queueSize          = 256
+)
+
+// Payload is the JSON body posted for each alert
+type Payload struct {
+	BlockNumber      int        `json:"blockNumber"`
+	Reward           float64    `json:"reward"`
+	Currency         string     `json:"currency"`
+	OpportunityTypes []string   `json:"opportunityTypes"`
+	BlockTime        *time.Time `json:"blockTime,omitempty"`
+}
+
+// Webhook posts a Payload to URL for each block whose validator reward
+// exceeds MinReward. Notify never blocks: alerts are queued for a background
+// sender and dropped when the queue is full, so a slow or failing webhook
+// cannot stall the caller. Each alert is attempted up to MaxAttempts times.
+type Webhook struct {
+	URL         string
+	MinReward   float64
+	Currency    string // Native symbol rewards are denominated in
+	MaxAttempts int
+	RetryDelay  time.Duration // Doubled after each failed attempt
+	HttpClient  *http.Client
+
+	queue chan Payload
+	done  chan struct{}
+	wg    sync.WaitGroup
+	once  sync.Once
+}
+
+// NewWebhook creates a webhook alerting on rewards above minReward. Call
+// Start before use and Stop to deliver queued alerts.
+func NewWebhook(url string, minReward float64) *Webhook {
+	return &Webhook{
+		URL:         url,
+		MinReward:   minReward,
+		MaxAttempts: DefaultMaxAttempts,
+		RetryDelay:  DefaultRetryDelay,
+		HttpClient:  &http.Client{Timeout: DefaultTimeout},
+		queue:       make(chan Payload, queueSize),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start begins delivering queued alerts in the background
+func (w *Webhook) Start() {
+	w.wg.Add(1)
+	go w.run()
+}
+
+// Stop delivers any queued alerts, abandoning retries, and stops the sender
+func (w *Webhook) Stop() {
+	w.once.Do(func() { close(w.done) })
+	w.wg.Wait()
+}
+
+// Notify queues an alert for result if its reward exceeds MinReward
+func (w *Webhook) Notify(result models.BlockMEVResult) {
+	if result.ValidatorReward <= w.MinReward {
+		return
+	}
+
+	payload := Payload{
+		BlockNumber:      result.BlockNumber,
+		Reward:           result.ValidatorReward,
+		Currency:         w.Currency,
+		OpportunityTypes: opportunityTypes(result.Opportunities),
+		BlockTime:        result.BlockTime,
+	}
+	select {
+	case w.queue <- payload:
+	default:
+		slog.Warn("Dropped webhook alert, queue full", "block", result.BlockNumber)
+	}
+}
+
+func (w *Webhook) run() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case payload := <-w.queue:
+			w.deliver(payload)
+		case <-w.done:
+			for {
+				select {
+				case payload := <-w.queue:
+					w.deliver(payload)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver posts payload, retrying with exponential backoff until it succeeds,
+// MaxAttempts is reached or the webhook is stopped
+func (w *Webhook) deliver(payload Payload) {
+	delay := w.RetryDelay
+	for attempt := 1; ; attempt++ {
+		err := w.post(payload)
+		if err == nil {
+			return
+		}
+		if attempt >= w.MaxAttempts {
+			slog.Warn("Failed to deliver webhook alert", "block", payload.BlockNumber, "attempts", attempt, "error", err)
+			return
+		}
+
+		select {
+		case <-w.done:
+			slog.Warn("Abandoned webhook alert on shutdown", "block", payload.BlockNumber, "error", err)
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post sends a single alert
+func (w *Webhook) post(payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// opportunityTypes lists the distinct opportunity types in detection order
+func opportunityTypes(opportunities []models.MEVOpportunity) []string {
+	types := []string{}
+	seen := make(map[string]bool)
+	for _, opp := range opportunities {
+		if !seen[opp.Type] {
+			seen[opp.Type] = true
+			types = append(types, opp.Type)
+		}
+	}
+	return types
+}
@@ -0,0 +1,189 @@
+package alerts
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// receiver is a webhook endpoint failing its first failures requests
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	attempts int
+	payloads []Payload
+}
+
+func newReceiver(t *testing.T, failures int) *receiver {
+	r := &receiver{failures: failures}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.attempts++
+		if r.attempts <= r.failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload Payload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil || req.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.payloads = append(r.payloads, payload)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() (attempts int, payloads []Payload) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.attempts, append([]Payload(nil), r.payloads...)
+}
+
+func newTestWebhook(url string) *Webhook {
+	w := NewWebhook(url, 0.5)
+	w.Currency = "ETH"
+	w.RetryDelay = time.Millisecond
+	return w
+}
+
+func TestWebhookNotify(t *testing.T) {
+	r := newReceiver(t, 0)
+	w := newTestWebhook(r.URL)
+	w.Start()
+
+	blockTime := time.Unix(1700000000, 0).UTC()
+	w.Notify(models.BlockMEVResult{BlockNumber: 1, ValidatorReward: 0.5}) // Not above MinReward
+	w.Notify(models.BlockMEVResult{
+		BlockNumber:     2,
+		ValidatorReward: 1.25,
+		BlockTime:       &blockTime,
+		Opportunities: []models.MEVOpportunity{
+			{Type: "sandwich"}, {Type: "arbitrage"}, {Type: "sandwich"},
+		},
+	})
+	w.Stop()
+
+	attempts, payloads := r.received()
+	if attempts != 1 || len(payloads) != 1 {
+		t.Fatalf("got %d attempts and payloads %+v, want one alert", attempts, payloads)
+	}
+	got := payloads[0]
+	if got.BlockNumber != 2 || got.Reward != 1.25 || got.Currency != "ETH" {
+		t.Errorf("got %+v", got)
+	}
+	if !reflect.DeepEqual(got.OpportunityTypes, []string{"sandwich", "arbitrage"}) {
+		t.Errorf("got opportunity types %q, want distinct types in order", got.OpportunityTypes)
+	}
+	if got.BlockTime == nil || !got.BlockTime.Equal(blockTime) {
+		t.Errorf("got block time %v, want %v", got.BlockTime, blockTime)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	r := newReceiver(t, 2)
+	w := newTestWebhook(r.URL)
+	w.Start()
+
+	w.Notify(models.BlockMEVResult{BlockNumber: 1, ValidatorReward: 1})
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, payloads := r.received(); len(payloads) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("alert was not delivered after failures")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	w.Stop()
+
+	if attempts, _ := r.received(); attempts != 3 {
+		t.Errorf("got %d attempts, want 3", attempts)
+	}
+}
+
+func TestWebhookGivesUp(t *testing.T) {
+	r := newReceiver(t, 100)
+	w := newTestWebhook(r.URL)
+	w.Start()
+
+	w.Notify(models.BlockMEVResult{BlockNumber: 1, ValidatorReward: 1})
+	w.Notify(models.BlockMEVResult{BlockNumber: 2, ValidatorReward: 1})
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if attempts, _ := r.received(); attempts == 2*DefaultMaxAttempts {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("alerts were not attempted MaxAttempts times each")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	w.Stop()
+
+	if attempts, payloads := r.received(); attempts != 2*DefaultMaxAttempts || len(payloads) != 0 {
+		t.Errorf("got %d attempts and %d deliveries", attempts, len(payloads))
+	}
+}
+
+// TestWebhookStop checks that Stop delivers queued alerts but abandons
+// retries rather than waiting them out
+func TestWebhookStop(t *testing.T) {
+	r := newReceiver(t, 1)
+	w := newTestWebhook(r.URL)
+	w.RetryDelay = time.Hour
+
+	// Queued before the sender starts, so Stop finds both waiting
+	w.Notify(models.BlockMEVResult{BlockNumber: 1, ValidatorReward: 1})
+	w.Notify(models.BlockMEVResult{BlockNumber: 2, ValidatorReward: 1})
+	w.Start()
+
+	stopped := make(chan struct{})
+	go func() {
+		w.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop waited for a retry")
+	}
+	w.Stop() // Stopping twice is safe
+
+	attempts, payloads := r.received()
+	if attempts != 2 || len(payloads) != 1 || payloads[0].BlockNumber != 2 {
+		t.Errorf("got %d attempts and payloads %+v, want block 1 abandoned and block 2 delivered", attempts, payloads)
+	}
+}
+
+func TestWebhookQueueFull(t *testing.T) {
+	w := newTestWebhook("http://127.0.0.1:0")
+
+	// Without a sender the queue fills and further alerts are dropped
+	// rather than blocking
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < queueSize+10; i++ {
+			w.Notify(models.BlockMEVResult{BlockNumber: i, ValidatorReward: 1})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Notify blocked on a full queue")
+	}
+	if len(w.queue) != queueSize {
+		t.Errorf("got %d queued alerts, want %d", len(w.queue), queueSize)
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAnomalySigma is how many standard deviations above the mean a
+// block's reward must be to count as anomalous
+const defaultAnomalySigma = 3.0
+
+// @Summary Find blocks with unusually high MEV
+// @Description Scans a block range and returns the blocks whose estimated validator reward exceeds the range mean by more than sigma standard deviations
+// @Tags MEV
+// @Accept json
+// @Produce json
+// @Param fromBlock query int false "Starting block number (default: latest - 100)"
+// @Param toBlock query int false "Ending block number (default: latest)"
+// @Param sigma query number false "Standard deviations above the mean a reward must exceed (default: 3)"
+// @Success 200 {object} models.MEVAnomaliesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/mev/anomalies [get]
+func (a *API) GetMEVAnomalies(c *gin.Context) {
+	sigma := defaultAnomalySigma
+	if s := c.Query("sigma"); s != "" {
+		var err error
+		sigma, err = strconv.ParseFloat(s, 64)
+		if err != nil || sigma < 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "sigma must be a non-negative number",
+			})
+			return
+		}
+	}
+
+	fromBlock, toBlock, ok := a.parseBlockRange(c)
+	if !ok {
+		return
+	}
+
+	rewards, err := a.blockRewards(c.Request.Context(), fromBlock, toBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing blocks: %v", err),
+		})
+		return
+	}
+
+	resp := rewardAnomalies(rewards, sigma)
+	resp.FromBlock = fromBlock
+	resp.ToBlock = toBlock
+	resp.Currency = a.mevDetector.NativeSymbol
+	resp.Timestamp = time.Now()
+	c.JSON(http.StatusOK, resp)
+}
+
+// rewardAnomalies returns the rewards exceeding mean + sigma*stddev. A range
+// with no variation has no anomalies.
+func rewardAnomalies(rewards []models.BlockReward, sigma float64) models.MEVAnomaliesResponse {
+	stats := rewardStats(rewards)
+	resp := models.MEVAnomaliesResponse{
+		TotalBlocks: len(rewards),
+		Sigma:       sigma,
+		Mean:        stats.Mean,
+		StdDev:      stats.StdDev,
+		Threshold:   stats.Mean + sigma*stats.StdDev,
+		Anomalies:   []models.BlockReward{},
+	}
+	if stats.StdDev == 0 {
+		return resp
+	}
+
+	for _, r := range rewards {
+		if r.Reward > resp.Threshold {
+			resp.Anomalies = append(resp.Anomalies, r)
+		}
+	}
+	return resp
+}
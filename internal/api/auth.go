@@ -0,0 +1,48 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyHeader carries the client's API key
+const apiKeyHeader = "X-API-Key"
+
+// apiKeyContextKey stores the authenticated key on the gin context
+const apiKeyContextKey = "apiKey"
+
+// APIKeyAuth rejects requests whose X-API-Key header does not match one of
+// keys with 401. Keys are hashed before comparison so every check takes the
+// same time regardless of key length or how much of a key matches.
+func APIKeyAuth(keys []string) gin.HandlerFunc {
+	hashes := make([][sha256.Size]byte, len(keys))
+	for i, key := range keys {
+		hashes[i] = sha256.Sum256([]byte(key))
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(apiKeyHeader)
+		if key == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Missing API key"})
+			return
+		}
+
+		sum := sha256.Sum256([]byte(key))
+		match := 0
+		for i := range hashes {
+			match |= subtle.ConstantTimeCompare(sum[:], hashes[i][:])
+		}
+		if match != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid API key"})
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
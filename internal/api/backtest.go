@@ -0,0 +1,148 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Backtest the reward simulation
+// @Description Simulates the blocks in a range from the distribution of the blocks just before it and compares the result with the rewards actually detected in the range
+// @Tags Validator
+// @Accept json
+// @Produce json
+// @Param validatorIndex path int true "Validator index"
+// @Param fromBlock query int false "Starting block number (default: latest - 100)"
+// @Param toBlock query int false "Ending block number (default: latest)"
+// @Param calibration query int false "Number of blocks before fromBlock to calibrate the simulation on (default: the size of the range)"
+// @Param distribution query string false "exponential (default) or lognormal"
+// @Param seed query int false "Seed for the simulated series (default: random, reported in the response)"
+// @Success 200 {object} models.BacktestResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/validator/{validatorIndex}/backtest [get]
+func (a *API) GetValidatorBacktest(c *gin.Context) {
+	validatorIndex, err := strconv.Atoi(c.Param("validatorIndex"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid validator index",
+		})
+		return
+	}
+
+	fromBlock, toBlock, ok := a.parseBlockRange(c)
+	if !ok {
+		return
+	}
+	blockCount := toBlock - fromBlock + 1
+
+	calibration := blockCount
+	if s := c.Query("calibration"); s != "" {
+		calibration, err = strconv.Atoi(s)
+		if err != nil || calibration <= 0 || calibration > a.MaxBlockRange {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: fmt.Sprintf("calibration must be between 1 and %d", a.MaxBlockRange),
+			})
+			return
+		}
+	}
+	calibrationFrom := fromBlock - calibration
+	if calibrationFrom < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Not enough blocks before fromBlock to calibrate on",
+		})
+		return
+	}
+
+	distribution := c.DefaultQuery("distribution", distributionExponential)
+	if distribution != distributionExponential && distribution != distributionLogNormal {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Distribution must be exponential or lognormal",
+		})
+		return
+	}
+
+	seed := rand.Int64() //nolint:gosec
+	if s := c.Query("seed"); s != "" {
+		seed, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid seed parameter",
+			})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	calibrationRewards, err := a.blockRewards(ctx, calibrationFrom, fromBlock-1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing calibration blocks: %v", err),
+		})
+		return
+	}
+	actual, err := a.blockRewards(ctx, fromBlock, toBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing blocks: %v", err),
+		})
+		return
+	}
+
+	rewards := make([]float64, len(calibrationRewards))
+	for i, r := range calibrationRewards {
+		rewards[i] = r.Reward
+	}
+	dist := summarizeRewards(rewards)
+	dist.kind = distribution
+	simulated, simulatedTotal, _ := simulateBlocks(newRand(&seed), fromBlock-1, blockCount, dist)
+
+	resp := models.BacktestResponse{
+		ValidatorIndex:       validatorIndex,
+		FromBlock:            fromBlock,
+		ToBlock:              toBlock,
+		CalibrationFromBlock: calibrationFrom,
+		CalibrationToBlock:   fromBlock - 1,
+		Distribution:         distribution,
+		Seed:                 seed,
+		Blocks:               make([]models.BacktestBlock, blockCount),
+		SimulatedTotal:       simulatedTotal,
+		Currency:             a.mevDetector.NativeSymbol,
+		Timestamp:            time.Now(),
+	}
+	for i := range resp.Blocks {
+		resp.Blocks[i] = models.BacktestBlock{
+			BlockNumber:     actual[i].BlockNumber,
+			ActualReward:    actual[i].Reward,
+			SimulatedReward: simulated[i].EstimatedReward,
+		}
+		resp.ActualTotal += actual[i].Reward
+	}
+	resp.MAE, resp.RMSE = backtestErrors(resp.Blocks)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// backtestErrors returns the mean absolute and root mean squared difference
+// between the simulated and actual rewards of blocks
+func backtestErrors(blocks []models.BacktestBlock) (mae, rmse float64) {
+	if len(blocks) == 0 {
+		return 0, 0
+	}
+
+	var absSum, sqSum float64
+	for _, b := range blocks {
+		d := b.SimulatedReward - b.ActualReward
+		absSum += math.Abs(d)
+		sqSum += d * d
+	}
+	n := float64(len(blocks))
+	return absSum / n, math.Sqrt(sqSum / n)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List known MEV bots
+// @Description Returns the addresses the known_bot detector currently matches
+// @Tags Bots
+// @Produce json
+// @Success 200 {object} models.BotsResponse
+// @Router /api/v1/bots [get]
+func (a *API) GetBots(c *gin.Context) {
+	bots := a.mevDetector.KnownMEVBots.List()
+	c.JSON(http.StatusOK, models.BotsResponse{
+		Bots:  bots,
+		Count: len(bots),
+	})
+}
+
+// @Summary Add a known MEV bot
+// @Description Adds an address to the known-bot list used by the known_bot detector
+// @Tags Bots
+// @Accept json
+// @Produce json
+// @Param request body models.AddBotRequest true "Bot address"
+// @Success 201 {object} models.BotsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/bots [post]
+func (a *API) AddBot(c *gin.Context) {
+	var req models.AddBotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("Invalid request: %v", err),
+		})
+		return
+	}
+
+	addr, ok := models.NormalizeAddress(req.Address)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid address",
+		})
+		return
+	}
+
+	if !a.mevDetector.KnownMEVBots.AddBot(addr) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "Address is already a known bot",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.BotsResponse{
+		Bots:  []string{addr},
+		Count: a.mevDetector.KnownMEVBots.Len(),
+	})
+}
+
+// @Summary Remove a known MEV bot
+// @Description Removes an address from the known-bot list used by the known_bot detector
+// @Tags Bots
+// @Produce json
+// @Param address path string true "Bot address"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/bots/{address} [delete]
+func (a *API) RemoveBot(c *gin.Context) {
+	addr, ok := models.NormalizeAddress(c.Param("address"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid address",
+		})
+		return
+	}
+
+	if !a.mevDetector.KnownMEVBots.RemoveBot(addr) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Address is not a known bot",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
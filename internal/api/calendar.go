@@ -0,0 +1,238 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get MEV aggregated by calendar time
+// @Description Resolves a time range to blocks and buckets their estimated validator rewards by hour, day or week (starting Monday) using block timestamps. Blocks already in storage are read from it; only the rest are analyzed, and they are limited to the maximum block range.
+// @Tags MEV
+// @Accept json
+// @Produce json
+// @Param from query string true "Range start as a UTC date, RFC 3339 or Unix seconds"
+// @Param to query string true "Range end (exclusive) as a UTC date, RFC 3339 or Unix seconds"
+// @Param granularity query string false "Bucket size: hour, day or week (default: hour)"
+// @Success 200 {object} models.CalendarResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/mev/calendar [get]
+func (a *API) GetMEVCalendar(c *gin.Context) {
+	from, err := parseTimeParam(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid from parameter",
+		})
+		return
+	}
+
+	to, err := parseTimeParam(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid to parameter",
+		})
+		return
+	}
+
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "from must be before to",
+		})
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", "hour")
+	if granularity != "hour" && granularity != "day" && granularity != "week" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "granularity must be hour, day or week",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	latestBlock, err := a.getLatestBlockNumber(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get latest block: %v", err),
+		})
+		return
+	}
+
+	fromBlock, err := a.mevDetector.BlockAtOrAfter(ctx, from, latestBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to resolve from: %v", err),
+		})
+		return
+	}
+
+	toBlock, err := a.mevDetector.BlockAtOrAfter(ctx, to, latestBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to resolve to: %v", err),
+		})
+		return
+	}
+	toBlock-- // to is exclusive
+
+	if toBlock-fromBlock >= maxHistoryRange {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("Time range too large (max %d blocks)", maxHistoryRange),
+		})
+		return
+	}
+
+	stored, err := a.storedTimedResults(ctx, fromBlock, toBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to read stored blocks: %v", err),
+		})
+		return
+	}
+
+	// Only blocks missing from the store count against the range limit
+	var missing []int
+	for b := fromBlock; b <= toBlock; b++ {
+		if _, ok := stored[b]; !ok {
+			missing = append(missing, b)
+		}
+	}
+	if len(missing) > a.MaxBlockRange {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("Time range too large: %d blocks are not stored (max %d)", len(missing), a.MaxBlockRange),
+		})
+		return
+	}
+
+	results := make([]models.BlockMEVResult, 0, toBlock-fromBlock+1)
+	for _, result := range stored {
+		results = append(results, result)
+	}
+	var mu sync.Mutex
+	err = a.forEachChunk(ctx, missing, blockBatchSize, func(ctx context.Context, blockNumbers []int) error {
+		analyzed, errs := a.analyzeBlocks(ctx, blockNumbers)
+		for i, b := range blockNumbers {
+			if errs[i] != nil {
+				return fmt.Errorf("block %d: %w", b, errs[i])
+			}
+			if analyzed[i].BlockTime == nil {
+				return fmt.Errorf("block %d: missing timestamp", b)
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, analyzed...)
+		return nil
+	})
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Request cancelled",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing blocks: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CalendarResponse{
+		From:         from,
+		To:           to,
+		Granularity:  granularity,
+		FromBlock:    fromBlock,
+		ToBlock:      toBlock,
+		StoredBlocks: len(stored),
+		Buckets:      calendarBuckets(results, granularity),
+		Currency:     a.mevDetector.NativeSymbol,
+		Timestamp:    time.Now(),
+	})
+}
+
+// storedTimedResults returns the stored results for fromBlock through
+// toBlock that record their block time and were analyzed with the current
+// detector configuration, by block number
+func (a *API) storedTimedResults(ctx context.Context, fromBlock, toBlock int) (map[int]models.BlockMEVResult, error) {
+	byBlock := make(map[int]models.BlockMEVResult)
+	if a.store == nil || a.MinConfidence > 0 || toBlock < fromBlock {
+		return byBlock, nil // Stored results lack low-confidence opportunities when MinConfidence is set
+	}
+
+	stored, err := a.store.GetBlockResults(ctx, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	version := a.mevDetector.ConfigVersion()
+	for _, result := range stored {
+		if result.BlockTime != nil && result.DetectorVersion == version {
+			byBlock[result.BlockNumber] = result
+		}
+	}
+	return byBlock, nil
+}
+
+// calendarBuckets sums results, which must all have a BlockTime, into
+// buckets of granularity, ordered by start. Buckets without blocks are
+// omitted.
+func calendarBuckets(results []models.BlockMEVResult, granularity string) []models.CalendarBucket {
+	byStart := make(map[time.Time]*models.CalendarBucket)
+	for _, result := range results {
+		start := bucketStart(*result.BlockTime, granularity)
+		bucket, ok := byStart[start]
+		if !ok {
+			bucket = &models.CalendarBucket{Start: start}
+			byStart[start] = bucket
+		}
+		bucket.Blocks++
+		bucket.TotalReward += result.ValidatorReward
+		if result.ValidatorReward > 0 {
+			bucket.MEVBlocks++
+		}
+	}
+
+	buckets := make([]models.CalendarBucket, 0, len(byStart))
+	for _, bucket := range byStart {
+		buckets = append(buckets, *bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets
+}
+
+// parseTimeParam parses an RFC 3339 timestamp or Unix seconds
+func parseTimeParam(s string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	if date, err := time.Parse(time.DateOnly, s); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// bucketStart returns the UTC start of the hour, day or week containing ts.
+// Weeks start on Monday.
+func bucketStart(ts time.Time, granularity string) time.Time {
+	ts = ts.UTC()
+	switch granularity {
+	case "day":
+		return time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)
+	case "week":
+		daysSinceMonday := (int(ts.Weekday()) + 6) % 7
+		return time.Date(ts.Year(), ts.Month(), ts.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+	}
+	return ts.Truncate(time.Hour)
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// calendarStart is a Monday, so weekly buckets start on it
+var calendarStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// addCalendarBlocks serves two weeks of blocks 0-27, one every 12 hours
+// from calendarStart. Block b pays b%3 gwei per gas, so every third block
+// has no MEV.
+func addCalendarBlocks(srv *testutil.RPCServer) {
+	for b := range 28 {
+		block := newMEVBlock(srv, b, b%3)
+		block.Timestamp = fmt.Sprintf("0x%x", calendarStart.Add(time.Duration(b)*12*time.Hour).Unix())
+		srv.AddBlock(b, block)
+	}
+}
+
+// getCalendar requests the calendar for the two weeks of calendar blocks
+func getCalendar(t *testing.T, a *API, granularity string) models.CalendarResponse {
+	t.Helper()
+	router := gin.New()
+	router.GET("/mev/calendar", a.GetMEVCalendar)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/mev/calendar?from=2024-01-01&to=2024-01-15&granularity="+granularity, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+
+	var resp models.CalendarResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestGetMEVCalendarBuckets(t *testing.T) {
+	a, srv := newTestAPI(t)
+	addCalendarBlocks(srv)
+
+	// The reward of a block paying 1 gwei per gas; rewards scale with it
+	unit, err := a.analyzeBlock(context.Background(), 1)
+	if err != nil || unit.ValidatorReward <= 0 {
+		t.Fatalf("1 gwei block reward %v, error %v", unit.ValidatorReward, err)
+	}
+
+	tests := []struct {
+		granularity string
+		span        time.Duration
+		buckets     int
+	}{
+		{granularity: "hour", span: time.Hour, buckets: 28},
+		{granularity: "day", span: 24 * time.Hour, buckets: 14},
+		{granularity: "week", span: 7 * 24 * time.Hour, buckets: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.granularity, func(t *testing.T) {
+			// Expected buckets, keyed by the whole spans since calendarStart
+			type totals struct{ blocks, mevBlocks, gwei int }
+			want := make(map[time.Time]*totals)
+			for b := range 28 {
+				offset := time.Duration(b) * 12 * time.Hour
+				start := calendarStart.Add(offset - offset%tt.span)
+				if want[start] == nil {
+					want[start] = &totals{}
+				}
+				want[start].blocks++
+				want[start].gwei += b % 3
+				if b%3 != 0 {
+					want[start].mevBlocks++
+				}
+			}
+
+			resp := getCalendar(t, a, tt.granularity)
+			if resp.FromBlock != 0 || resp.ToBlock != 27 {
+				t.Errorf("resolved blocks %d-%d, want 0-27", resp.FromBlock, resp.ToBlock)
+			}
+			if len(resp.Buckets) != tt.buckets {
+				t.Fatalf("got %d buckets, want %d", len(resp.Buckets), tt.buckets)
+			}
+			for i, bucket := range resp.Buckets {
+				if i > 0 && !bucket.Start.After(resp.Buckets[i-1].Start) {
+					t.Errorf("bucket %d starts %v, not after the previous bucket", i, bucket.Start)
+				}
+				w, ok := want[bucket.Start]
+				if !ok {
+					t.Errorf("unexpected bucket starting %v", bucket.Start)
+					continue
+				}
+				if bucket.Blocks != w.blocks || bucket.MEVBlocks != w.mevBlocks {
+					t.Errorf("bucket %v has %d blocks, %d with MEV; want %d, %d", bucket.Start, bucket.Blocks, bucket.MEVBlocks, w.blocks, w.mevBlocks)
+				}
+				if reward := float64(w.gwei) * unit.ValidatorReward; math.Abs(bucket.TotalReward-reward) > 1e-12 {
+					t.Errorf("bucket %v total reward %v, want %v", bucket.Start, bucket.TotalReward, reward)
+				}
+			}
+		})
+	}
+}
+
+func TestGetMEVCalendarReusesStore(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	t.Cleanup(srv.Close)
+	store := testutil.NewMemStore()
+	a := NewAPI(srv.Detector(), nil, store)
+	addCalendarBlocks(srv)
+
+	// Store the first week as if the scanner had ingested it
+	ctx := context.Background()
+	for b := range 14 {
+		result, err := a.mevDetector.AnalyzeBlock(ctx, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SaveBlockResult(ctx, result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := srv.Requests("eth_getBlockReceipts")
+
+	resp := getCalendar(t, a, "week")
+	if resp.StoredBlocks != 14 {
+		t.Errorf("read %d blocks from the store, want 14", resp.StoredBlocks)
+	}
+	if got := srv.Requests("eth_getBlockReceipts") - before; got != 14 {
+		t.Errorf("analyzed %d blocks, want only the 14 not stored", got)
+	}
+	if len(resp.Buckets) != 2 || resp.Buckets[0].Blocks != 14 || resp.Buckets[1].Blocks != 14 {
+		t.Errorf("buckets = %+v, want two weeks of 14 blocks", resp.Buckets)
+	}
+
+	// Only the blocks that must be analyzed count against the range limit
+	a.MaxBlockRange = 14
+	getCalendar(t, a, "week")
+}
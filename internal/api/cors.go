@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Defaults for CORS requests
+var (
+	DefaultCORSMethods = []string{"GET", "POST", "DELETE"}
+	DefaultCORSHeaders = []string{"Content-Type", "X-API-Key", "X-Request-ID", "traceparent"}
+)
+
+// corsExposedHeaders are response headers browsers may read cross-origin
+var corsExposedHeaders = []string{"X-Request-ID", "Retry-After", "Server-Timing"}
+
+// CORS allows browsers on the given origins to call the API. An origin of
+// "*" allows any origin; with no origins, cross-origin requests get no CORS
+// headers and browsers block them. Preflight requests are answered
+// directly. Empty methods or headers use the defaults.
+func CORS(origins, methods, headers []string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[strings.TrimRight(origin, "/")] = true
+	}
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	exposeHeaders := strings.Join(corsExposedHeaders, ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Origin")
+
+		if !allowed["*"] && !allowed[origin] {
+			c.Next()
+			return
+		}
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Access-Control-Expose-Headers", exposeHeaders)
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Header("Access-Control-Allow-Methods", allowMethods)
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+			c.Header("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
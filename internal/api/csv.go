@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validatorCSVHeader names the columns of the validator rewards CSV export
+var validatorCSVHeader = []string{"block_number", "opportunities", "validator_reward"}
+
+// writeValidatorCSV streams one CSV row per block attributed to the
+// validator as each block completes, so rows are not in block order and the
+// whole range is never buffered. Failures before the first row produce a
+// JSON error; later failures end the stream early since the status has
+// already been sent.
+func (a *API) writeValidatorCSV(c *gin.Context, blockNumbers []int, feeRecipient string, skipErrors bool) {
+	ctx := c.Request.Context()
+	results := make(chan models.BlockMEVResult)
+	done := make(chan error, 1)
+	go func() {
+		defer close(results)
+		done <- a.forEachChunk(ctx, blockNumbers, blockBatchSize, func(ctx context.Context, blockNumbers []int) error {
+			chunk, errs := a.analyzeBlocks(ctx, blockNumbers)
+			for i, b := range blockNumbers {
+				if err := errs[i]; err != nil {
+					if skipErrors && ctx.Err() == nil {
+						continue
+					}
+					return fmt.Errorf("block %d: %w", b, err)
+				}
+				if feeRecipient != "" && chunk[i].FeeRecipient != feeRecipient {
+					continue // Proposed by another validator
+				}
+
+				select {
+				case results <- chunk[i]:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+	}()
+
+	// Drain every result so no worker is left blocked on a send
+	var w *csv.Writer
+	for result := range results {
+		if w == nil {
+			w = startCSV(c)
+		}
+		w.Write([]string{
+			strconv.Itoa(result.BlockNumber),
+			strconv.Itoa(len(result.Opportunities)),
+			strconv.FormatFloat(result.ValidatorReward, 'f', -1, 64),
+		})
+		w.Flush()
+		c.Writer.Flush()
+	}
+
+	err := <-done
+	if err != nil && w == nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing blocks: %v", err),
+		})
+		return
+	}
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "CSV export ended early", "error", err)
+		return
+	}
+
+	if w == nil {
+		w = startCSV(c) // Header only when no block matched
+		w.Flush()
+	}
+}
+
+// startCSV sends the CSV response headers and header row
+func startCSV(c *gin.Context) *csv.Writer {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="mev-rewards.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(validatorCSVHeader)
+	return w
+}
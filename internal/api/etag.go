@@ -0,0 +1,75 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultFinalityDepth is how many blocks behind the head a block must be
+// before its analysis is served as immutable; two epochs covers finality
+const DefaultFinalityDepth = 64
+
+// headCacheTTL is how long a fetched head is reused to decide finality. A
+// stale head only makes blocks look less final than they are.
+const headCacheTTL = 12 * time.Second
+
+// headCache remembers the latest head fetched from the provider
+type headCache struct {
+	mu      sync.Mutex
+	block   int
+	fetched time.Time
+}
+
+// set records blockNumber as the head just fetched
+func (h *headCache) set(blockNumber int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.block, h.fetched = blockNumber, time.Now()
+}
+
+// get returns the cached head if it was fetched within maxAge
+func (h *headCache) get(maxAge time.Duration) (int, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.fetched.IsZero() || time.Since(h.fetched) > maxAge {
+		return 0, false
+	}
+	return h.block, true
+}
+
+// immutableCacheControl lets clients keep a finalized block's response forever
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// strongETag returns a strong entity tag derived from the JSON encoding of v
+func strongETag(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// notModified sets the ETag and immutable caching headers and reports
+// whether the request's If-None-Match already names etag, in which case a
+// 304 has been written
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", immutableCacheControl)
+
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == etag || candidate == "*" {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
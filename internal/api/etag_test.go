@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// TestGetBlockMEVReusesHead checks that finality checks share one head lookup
+// instead of calling eth_blockNumber on every request
+func TestGetBlockMEVReusesHead(t *testing.T) {
+	a, srv := newTestAPI(t)
+	srv.SetLatest(1000)
+	srv.AddBlock(100, &models.Block{Miner: testFeeRecipient})
+
+	w := serve(a, httptest.NewRequest(http.MethodGet, "/mev/block/100", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("finalized block has no ETag")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/mev/block/100", nil)
+	req.Header.Set("If-None-Match", etag)
+	if w := serve(a, req); w.Code != http.StatusNotModified {
+		t.Errorf("got %d, want 304", w.Code)
+	}
+
+	if got := srv.Requests("eth_blockNumber"); got != 1 {
+		t.Errorf("requested head %d times, want 1", got)
+	}
+}
+
+func TestGetBlockMEVRecentBlockNotCached(t *testing.T) {
+	a, srv := newTestAPI(t)
+	srv.SetLatest(110)
+	srv.AddBlock(100, &models.Block{Miner: testFeeRecipient})
+
+	w := serve(a, httptest.NewRequest(http.MethodGet, "/mev/block/100", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	if etag := w.Header().Get("ETag"); etag != "" {
+		t.Errorf("unfinalized block has ETag %s", etag)
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get the top MEV-extracting addresses
+// @Description Aggregates estimated MEV by sender address across a block range and returns the top extractors
+// @Tags MEV
+// @Accept json
+// @Produce json
+// @Param fromBlock query int false "Starting block number (default: latest - 100)"
+// @Param toBlock query int false "Ending block number (default: latest)"
+// @Param limit query int false "Number of extractors to return (default: 10, max: 100)"
+// @Success 200 {object} models.TopExtractorsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/mev/top-extractors [get]
+func (a *API) GetTopExtractors(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "limit must be between 1 and 100",
+		})
+		return
+	}
+
+	fromBlock, toBlock, ok := a.parseBlockRange(c)
+	if !ok {
+		return
+	}
+
+	var (
+		mu         sync.Mutex
+		extractors = make(map[string]*models.Extractor)
+	)
+	err = a.forEachBlock(c.Request.Context(), fromBlock, toBlock, func(ctx context.Context, b int) error {
+		result, err := a.analyzeBlock(ctx, b)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", b, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for addr, profit := range a.extractorProfits(result.Opportunities) {
+			e, ok := extractors[addr]
+			if !ok {
+				e = &models.Extractor{Address: addr}
+				extractors[addr] = e
+			}
+			e.TotalProfit += profit.total
+			e.Transactions += profit.txs
+			e.Blocks++
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing blocks: %v", err),
+		})
+		return
+	}
+
+	ranked := make([]models.Extractor, 0, len(extractors))
+	for _, e := range extractors {
+		ranked = append(ranked, *e)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].TotalProfit != ranked[j].TotalProfit {
+			return ranked[i].TotalProfit > ranked[j].TotalProfit
+		}
+		return ranked[i].Address < ranked[j].Address
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	c.JSON(http.StatusOK, models.TopExtractorsResponse{
+		FromBlock:  fromBlock,
+		ToBlock:    toBlock,
+		Extractors: ranked,
+		Currency:   a.mevDetector.NativeSymbol,
+		Timestamp:  time.Now(),
+	})
+}
+
+// extractorProfit is one address's estimated MEV within a single block
+type extractorProfit struct {
+	total float64
+	txs   int
+}
+
+// extractorProfits attributes the estimated MEV of a block's opportunities
+// to the sending addresses. Each transaction is counted once, and sandwich
+// victims are not treated as extractors.
+func (a *API) extractorProfits(opportunities []models.MEVOpportunity) map[string]extractorProfit {
+	seen := make(map[string]bool)
+	profits := make(map[string]extractorProfit)
+
+	for _, opp := range opportunities {
+		for i, tx := range opp.Transactions {
+			if opp.Type == "sandwich" && i == 1 {
+				continue // Victim
+			}
+
+			hash := strings.ToLower(tx.Hash)
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+
+			reward, _ := a.mevDetector.CalculateMEVReward([]models.MEVOpportunity{{
+				Transactions:  []models.Transaction{tx},
+				BaseFeePerGas: opp.BaseFeePerGas,
+			}})
+			addr := strings.ToLower(tx.From)
+			p := profits[addr]
+			p.total += reward
+			p.txs++
+			profits[addr] = p
+		}
+	}
+
+	return profits
+}
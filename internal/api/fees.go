@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get a fee vs MEV breakdown for a block range
+// @Description Returns per-block burned base fee, proposer priority fee and estimated MEV as parallel arrays for stacked charts
+// @Tags MEV
+// @Accept json
+// @Produce json
+// @Param fromBlock query int false "Starting block number (default: latest - 100)"
+// @Param toBlock query int false "Ending block number (default: latest)"
+// @Success 200 {object} models.FeeBreakdownResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/mev/fee-breakdown [get]
+func (a *API) GetFeeBreakdown(c *gin.Context) {
+	fromBlock, toBlock, ok := a.parseBlockRange(c)
+	if !ok {
+		return
+	}
+
+	count := toBlock - fromBlock + 1
+	resp := models.FeeBreakdownResponse{
+		FromBlock:    fromBlock,
+		ToBlock:      toBlock,
+		Blocks:       make([]int, count),
+		BaseFees:     make([]float64, count),
+		PriorityFees: make([]float64, count),
+		MEVRewards:   make([]float64, count),
+	}
+
+	err := a.forEachBlock(c.Request.Context(), fromBlock, toBlock, func(ctx context.Context, b int) error {
+		breakdown, err := a.mevDetector.GetFeeBreakdown(ctx, b)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", b, err)
+		}
+
+		i := b - fromBlock
+		resp.Blocks[i] = b
+		resp.BaseFees[i] = breakdown.BaseFee
+		resp.PriorityFees[i] = breakdown.PriorityFee
+		resp.MEVRewards[i] = breakdown.MEVReward
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing blocks: %v", err),
+		})
+		return
+	}
+
+	resp.Currency = a.mevDetector.NativeSymbol
+	resp.Timestamp = time.Now()
+	c.JSON(http.StatusOK, resp)
+}
+
+// gasPriceCollector gathers effective gas prices from block results across
+// a scan
+type gasPriceCollector struct {
+	mu      sync.Mutex
+	prices  []float64
+	missing int
+}
+
+// add adds the effective gas prices recorded on result. Results without
+// them, such as ones stored before they were recorded, are counted as
+// missing rather than failing the scan.
+func (g *gasPriceCollector) add(result models.BlockMEVResult) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if result.GasPrices == nil {
+		g.missing++
+		return
+	}
+	g.prices = append(g.prices, result.GasPrices...)
+}
+
+// percentiles returns the p10/p50/p90/p99 effective gas price across the
+// collected samples
+func (g *gasPriceCollector) percentiles() *models.GasPricePercentiles {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	sorted := append([]float64(nil), g.prices...)
+	sort.Float64s(sorted)
+
+	return &models.GasPricePercentiles{
+		Samples:       len(sorted),
+		MissingBlocks: g.missing,
+		P10:           percentile(sorted, 10),
+		P50:           percentile(sorted, 50),
+		P90:           percentile(sorted, 90),
+		P99:           percentile(sorted, 99),
+	}
+}
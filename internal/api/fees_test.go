@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// TestValidatorGasPricePercentiles spreads effective gas prices of 0 to 100
+// gwei over several blocks and checks the scan-wide percentiles
+func TestValidatorGasPricePercentiles(t *testing.T) {
+	a, srv := newTestAPI(t)
+
+	const blocks = 3
+	blockTxs := make([][]models.Transaction, blocks)
+	blockReceipts := make([][]models.Receipt, blocks)
+	for gwei := 0; gwei <= 100; gwei++ {
+		b := gwei % blocks
+		hash := fmt.Sprintf("0x%064x", gwei+1)
+		price := fmt.Sprintf("0x%x", int64(gwei)*1e9)
+		blockTxs[b] = append(blockTxs[b], models.Transaction{Hash: hash, GasPrice: price, Value: "0x0", Input: "0x"})
+		blockReceipts[b] = append(blockReceipts[b], models.Receipt{TransactionHash: hash, GasUsed: "0x5208", EffectiveGasPrice: price})
+	}
+	for b := range blocks {
+		srv.AddBlock(100+b, &models.Block{Miner: testFeeRecipient, Transactions: blockTxs[b]})
+		srv.AddReceipts(100+b, blockReceipts[b])
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/validator/1/mev-rewards?fromBlock=100&toBlock=%d&feeRecipient=%s", 100+blocks-1, testFeeRecipient), nil)
+	w := serve(a, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+
+	var resp models.ValidatorMEVResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	want := models.GasPricePercentiles{Samples: 101, P10: 10, P50: 50, P90: 90, P99: 99}
+	if resp.GasPrices == nil || *resp.GasPrices != want {
+		t.Errorf("gas price percentiles = %+v, want %+v", resp.GasPrices, want)
+	}
+
+	// The receipts fetched for detection are reused for the percentiles
+	if got := srv.Requests("eth_getBlockReceipts"); got != blocks {
+		t.Errorf("fetched receipts %d times, want %d", got, blocks)
+	}
+}
+
+func TestGasPriceCollectorCountsMissingBlocks(t *testing.T) {
+	var g gasPriceCollector
+	g.add(models.BlockMEVResult{GasPrices: []float64{1, 3}})
+	g.add(models.BlockMEVResult{GasPrices: []float64{}}) // No transactions
+	g.add(models.BlockMEVResult{})                       // Stored before prices were recorded
+
+	got := g.percentiles()
+	if got.Samples != 2 || got.MissingBlocks != 1 || got.P50 != 2 {
+		t.Errorf("percentiles = %+v, want 2 samples, 1 missing block and p50 2", got)
+	}
+}
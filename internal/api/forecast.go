@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	forecastRuns       = 200
+	maxForecastHorizon = 50400 // About one week of blocks
+)
+
+// @Summary Forecast a validator's MEV rewards
+// @Description Scans recent blocks for realized MEV and projects rewards over a future horizon by simulating from the realized distribution
+// @Tags Validator
+// @Accept json
+// @Produce json
+// @Param validatorIndex path int true "Validator index"
+// @Param lookback query int false "Number of recent blocks to scan (default: 100, max: the configured block range)"
+// @Param horizon query int false "Number of future blocks to forecast (default: 7200)"
+// @Success 200 {object} models.ForecastResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/validator/{validatorIndex}/forecast [get]
+func (a *API) GetValidatorForecast(c *gin.Context) {
+	validatorIndex, err := strconv.Atoi(c.Param("validatorIndex"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid validator index",
+		})
+		return
+	}
+
+	lookback, err := strconv.Atoi(c.DefaultQuery("lookback", "100"))
+	if err != nil || lookback <= 0 || lookback > a.MaxBlockRange {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("lookback must be between 1 and %d", a.MaxBlockRange),
+		})
+		return
+	}
+
+	horizon, err := strconv.Atoi(c.DefaultQuery("horizon", "7200"))
+	if err != nil || horizon <= 0 || horizon > maxForecastHorizon {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("horizon must be between 1 and %d", maxForecastHorizon),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	latestBlock, err := a.getLatestBlockNumber(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get latest block: %v", err),
+		})
+		return
+	}
+
+	// Scan the lookback window for realized rewards
+	fromBlock := latestBlock - lookback + 1
+	rewards := make([]float64, lookback)
+	err = a.forEachBlock(ctx, fromBlock, latestBlock, func(ctx context.Context, b int) error {
+		result, err := a.analyzeBlock(ctx, b)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", b, err)
+		}
+		rewards[b-fromBlock] = result.ValidatorReward
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing blocks: %v", err),
+		})
+		return
+	}
+
+	dist := summarizeRewards(rewards)
+	realized := models.RealizedRewards{
+		FromBlock:      fromBlock,
+		ToBlock:        latestBlock,
+		AverageReward:  dist.avgReward,
+		MEVProbability: dist.mevProbability,
+		MaxReward:      dist.maxReward,
+	}
+	for _, reward := range rewards {
+		realized.TotalReward += reward
+		if reward > 0 {
+			realized.MEVBlocks++
+		}
+	}
+
+	// Project the horizon by repeatedly simulating from the realized distribution
+	rng := newRand(nil)
+	totals := make([]float64, forecastRuns)
+	var sum float64
+	for i := range totals {
+		_, total, _ := simulateBlocks(rng, latestBlock, horizon, dist)
+		totals[i] = total
+		sum += total
+	}
+	sort.Float64s(totals)
+
+	c.JSON(http.StatusOK, models.ForecastResponse{
+		ValidatorIndex: validatorIndex,
+		Realized:       realized,
+		Forecast: models.ForecastedReward{
+			HorizonBlocks:   horizon,
+			Runs:            forecastRuns,
+			ExpectedReward:  sum / forecastRuns,
+			LowerBound:      percentile(totals, 5),
+			UpperBound:      percentile(totals, 95),
+			ConfidenceLevel: 0.9,
+		},
+		Currency:  a.mevDetector.NativeSymbol,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/graphql"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// graphQLField maps a root GraphQL field onto a REST handler. Arguments
+// become query parameters named alike, except pathArg, which fills the
+// handler's path parameter, and bodyArg, which is sent as the JSON body.
+type graphQLField struct {
+	handler  func(a *API) gin.HandlerFunc
+	method   string
+	pathArg  string // GraphQL argument holding the path parameter
+	pathName string // Path parameter it fills
+	bodyArg  string
+}
+
+// graphQLQueries are the fields of the Query type. Their values and
+// arguments match the JSON and query parameters of the REST endpoints they
+// delegate to.
+var graphQLQueries = map[string]graphQLField{
+	// blockMEV(blockNumber: Int!, minConfidence: Float, resolveNames: Boolean, currency: String): MEVOpportunitiesResponse
+	"blockMEV": {
+		handler:  func(a *API) gin.HandlerFunc { return a.GetBlockMEV },
+		method:   http.MethodGet,
+		pathArg:  "blockNumber",
+		pathName: "blockNumber",
+	},
+	// validatorRewards(index: Int!, fromBlock: Int, toBlock: Int, feeRecipient: String,
+	// skipErrors: Boolean, page: Int, pageSize: Int, currency: String): ValidatorMEVResponse
+	"validatorRewards": {
+		handler:  func(a *API) gin.HandlerFunc { return a.GetValidatorMEVRewards },
+		method:   http.MethodGet,
+		pathArg:  "index",
+		pathName: "validatorIndex",
+	},
+	// simulate(input: SimulationRequest!, currency: String): SimulationResponse
+	"simulate": {
+		handler: func(a *API) gin.HandlerFunc { return a.SimulateMEVRewards },
+		method:  http.MethodPost,
+		bodyArg: "input",
+	},
+}
+
+// @Summary Query the API with GraphQL
+// @Description Runs a GraphQL query over the blockMEV, validatorRewards and simulate fields, which return the same data as the corresponding REST endpoints narrowed to the selected fields. Fragments, directives and introspection are not supported.
+// @Tags MEV
+// @Accept json
+// @Produce json
+// @Param request body models.GraphQLRequest true "GraphQL query"
+// @Success 200 {object} models.GraphQLResponse
+// @Failure 400 {object} models.GraphQLResponse
+// @Router /api/v1/graphql [post]
+func (a *API) GraphQL(c *gin.Context) {
+	var req models.GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeGraphQLError(c, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+
+	doc, err := graphql.Parse(req.Query, req.OperationName)
+	if err != nil {
+		writeGraphQLError(c, err.Error())
+		return
+	}
+	if doc.Operation != "query" {
+		writeGraphQLError(c, fmt.Sprintf("%s operations are not supported", doc.Operation))
+		return
+	}
+
+	resolvers := make(map[string]graphql.Resolver, len(graphQLQueries))
+	for name, field := range graphQLQueries {
+		resolvers[name] = func(args map[string]interface{}) (interface{}, error) {
+			return a.resolveGraphQLField(c, field, args)
+		}
+	}
+
+	data, fieldErrs, err := graphql.Execute(doc, req.Variables, resolvers)
+	if err != nil {
+		writeGraphQLError(c, err.Error())
+		return
+	}
+
+	resp := models.GraphQLResponse{Data: data}
+	for _, fieldErr := range fieldErrs {
+		resp.Errors = append(resp.Errors, models.GraphQLError{
+			Message: fieldErr.Error(),
+			Path:    []string{fieldErr.Key},
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// writeGraphQLError responds to a GraphQL request that could not run
+func writeGraphQLError(c *gin.Context, msg string) {
+	c.JSON(http.StatusBadRequest, models.GraphQLResponse{
+		Errors: []models.GraphQLError{{Message: msg}},
+	})
+}
+
+// resolveGraphQLField runs field's REST handler with args and returns its
+// decoded JSON response, or its error message as an error
+func (a *API) resolveGraphQLField(c *gin.Context, field graphQLField, args map[string]interface{}) (interface{}, error) {
+	query := url.Values{}
+	var (
+		params gin.Params
+		body   []byte
+	)
+	for name, value := range args {
+		switch name {
+		case field.pathArg:
+			s, err := graphQLParam(name, value)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, gin.Param{Key: field.pathName, Value: s})
+		case field.bodyArg:
+			var err error
+			if body, err = json.Marshal(value); err != nil {
+				return nil, fmt.Errorf("invalid %s argument: %w", name, err)
+			}
+		default:
+			if value == nil {
+				continue
+			}
+			s, err := graphQLParam(name, value)
+			if err != nil {
+				return nil, err
+			}
+			query.Set(name, s)
+		}
+	}
+	if field.pathArg != "" && len(params) == 0 {
+		return nil, fmt.Errorf("argument %s is required", field.pathArg)
+	}
+	if field.bodyArg != "" && body == nil {
+		return nil, fmt.Errorf("argument %s is required", field.bodyArg)
+	}
+
+	w := runHandler(c.Request.Context(), field.handler(a), field.method, params, query, body)
+	if w.Code != http.StatusOK {
+		return nil, fmt.Errorf("%s", handlerError(w))
+	}
+
+	dec := json.NewDecoder(w.Body)
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return value, nil
+}
+
+// runHandler runs a REST handler in-process on a request built from
+// params, query and a JSON body, and returns its recorded response
+func runHandler(ctx context.Context, handler gin.HandlerFunc, method string, params gin.Params, query url.Values, body []byte) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	sub, _ := gin.CreateTestContext(w)
+	sub.Request = httptest.NewRequest(method, "/?"+query.Encode(), bytes.NewReader(body)).WithContext(ctx)
+	sub.Request.Header.Set("Content-Type", "application/json")
+	sub.Params = params
+	handler(sub)
+	return w
+}
+
+// handlerError returns the message of a failed handler response
+func handlerError(w *httptest.ResponseRecorder) string {
+	var errResp models.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &errResp); err != nil || errResp.Error == "" {
+		return fmt.Sprintf("request failed with status %d", w.Code)
+	}
+	return errResp.Error
+}
+
+// graphQLParam formats a scalar argument as a query or path parameter
+func graphQLParam(name string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	}
+	return "", fmt.Errorf("argument %s must be a scalar", name)
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// graphQLResponse is a GraphQL response with its data kept as raw JSON, so
+// tests can check exactly which fields were returned
+type graphQLResponse struct {
+	Data   json.RawMessage       `json:"data"`
+	Errors []models.GraphQLError `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, a *API, body string, wantCode int) graphQLResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := serve(a, req)
+	if w.Code != wantCode {
+		t.Fatalf("got %d %s, want %d", w.Code, w.Body.String(), wantCode)
+	}
+
+	var resp graphQLResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// TestGraphQLSelectsFields checks that only the selected fields are
+// returned, in selection order, with the REST handler's values
+func TestGraphQLSelectsFields(t *testing.T) {
+	a, srv := newTestAPI(t)
+	srv.SetLatest(1000)
+	addMEVBlock(srv, 100, 2)
+
+	resp := postGraphQL(t, a, `{"query": "{ blockMEV(blockNumber: 100) { opportunities { type confidence } number: blockNumber } }"}`, http.StatusOK)
+	if len(resp.Errors) != 0 {
+		t.Fatalf("got errors %+v", resp.Errors)
+	}
+	want := `{"blockMEV":{"opportunities":[{"type":"known_bot","confidence":0.95}],"number":100}}`
+	if string(resp.Data) != want {
+		t.Errorf("got  %s\nwant %s", resp.Data, want)
+	}
+}
+
+func TestGraphQLVariables(t *testing.T) {
+	a, srv := newTestAPI(t)
+	srv.SetLatest(1000)
+	addMEVBlock(srv, 100, 2)
+
+	resp := postGraphQL(t, a, `{
+		"query": "query Block($n: Int!) { blockMEV(blockNumber: $n) { blockNumber } sim: simulate(input: {validatorIndex: 1, blockCount: 3, seed: 7, customAvgReward: 0.1, customMEVProbability: 0.3}) { simulatedBlockCount } }",
+		"variables": {"n": 100}
+	}`, http.StatusOK)
+	if len(resp.Errors) != 0 {
+		t.Fatalf("got errors %+v", resp.Errors)
+	}
+	want := `{"blockMEV":{"blockNumber":100},"sim":{"simulatedBlockCount":3}}`
+	if string(resp.Data) != want {
+		t.Errorf("got  %s\nwant %s", resp.Data, want)
+	}
+}
+
+// TestGraphQLFieldError checks that a failing field is reported with its
+// path while the other fields resolve
+func TestGraphQLFieldError(t *testing.T) {
+	a, srv := newTestAPI(t)
+	srv.SetLatest(1000)
+	addMEVBlock(srv, 100, 2)
+
+	resp := postGraphQL(t, a, `{"query": "{ bad: blockMEV(blockNumber: -1) { blockNumber } good: blockMEV(blockNumber: 100) { blockNumber } }"}`, http.StatusOK)
+	if want := `{"bad":null,"good":{"blockNumber":100}}`; string(resp.Data) != want {
+		t.Errorf("got  %s\nwant %s", resp.Data, want)
+	}
+	if len(resp.Errors) != 1 || len(resp.Errors[0].Path) != 1 || resp.Errors[0].Path[0] != "bad" || resp.Errors[0].Message == "" {
+		t.Errorf("got errors %+v, want one for bad", resp.Errors)
+	}
+}
+
+func TestGraphQLRequestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"missing query", `{}`, "Invalid request"},
+		{"syntax error", `{"query": "{ blockMEV("}`, "expected"},
+		{"mutation", `{"query": "mutation { simulate }"}`, "mutation operations are not supported"},
+		{"unknown field", `{"query": "{ blocks { blockNumber } }"}`, `cannot query field "blocks"`},
+		{"missing variable", `{"query": "query ($n: Int!) { blockMEV(blockNumber: $n) { blockNumber } }"}`, "variable $n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := newTestAPI(t)
+			resp := postGraphQL(t, a, tt.body, http.StatusBadRequest)
+			if resp.Data != nil {
+				t.Errorf("got data %s for a query that could not run", resp.Data)
+			}
+			if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.wantErr) {
+				t.Errorf("got errors %+v, want one containing %q", resp.Errors, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/grpcwire"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// GRPCService is the full name of the service in proto/mev/v1/tracker.proto
+const GRPCService = "mev.v1.MEVTracker"
+
+// GRPCServer serves the MEVTracker gRPC service. As with GraphQL, each
+// method runs the REST handler it mirrors, so validation, defaults and
+// errors match the REST API.
+func (a *API) GRPCServer() *grpcwire.Server {
+	s := grpcwire.NewServer()
+	s.Register("/"+GRPCService+"/GetBlockMEV", a.grpcGetBlockMEV)
+	s.Register("/"+GRPCService+"/GetValidatorMEVRewards", a.grpcGetValidatorMEVRewards)
+	s.Register("/"+GRPCService+"/SimulateMEVRewards", a.grpcSimulateMEVRewards)
+	return s
+}
+
+// grpcGetBlockMEV mirrors GetBlockMEV
+func (a *API) grpcGetBlockMEV(ctx context.Context, msg []byte) ([]byte, error) {
+	req, err := parseProto(msg)
+	if err != nil {
+		return nil, grpcwire.Errorf(grpcwire.InvalidArgument, "invalid request: %v", err)
+	}
+
+	query := url.Values{}
+	if minConfidence, ok := req.double(2); ok {
+		query.Set("minConfidence", strconv.FormatFloat(minConfidence, 'f', -1, 64))
+	}
+	if req.bool(3) {
+		query.Set("resolveNames", "true")
+	}
+	setQuery(query, "currency", req.string(4))
+	params := gin.Params{{Key: "blockNumber", Value: req.string(1)}}
+
+	var resp models.MEVOpportunitiesResponse
+	if err := grpcCall(ctx, a.GetBlockMEV, http.MethodGet, params, query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return encodeBlockMEVResponse(resp), nil
+}
+
+// grpcGetValidatorMEVRewards mirrors GetValidatorMEVRewards
+func (a *API) grpcGetValidatorMEVRewards(ctx context.Context, msg []byte) ([]byte, error) {
+	req, err := parseProto(msg)
+	if err != nil {
+		return nil, grpcwire.Errorf(grpcwire.InvalidArgument, "invalid request: %v", err)
+	}
+
+	validatorIndex, _ := req.int(1)
+	query := url.Values{}
+	for num, name := range map[protowire.Number]string{2: "fromBlock", 3: "toBlock", 6: "page", 7: "pageSize"} {
+		if v, ok := req.int(num); ok {
+			query.Set(name, strconv.FormatInt(v, 10))
+		}
+	}
+	setQuery(query, "feeRecipient", req.string(4))
+	if req.bool(5) {
+		query.Set("skipErrors", "true")
+	}
+	setQuery(query, "currency", req.string(8))
+	params := gin.Params{{Key: "validatorIndex", Value: strconv.FormatInt(validatorIndex, 10)}}
+
+	var resp models.ValidatorMEVResponse
+	if err := grpcCall(ctx, a.GetValidatorMEVRewards, http.MethodGet, params, query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return encodeValidatorMEVResponse(resp), nil
+}
+
+// grpcSimulateMEVRewards mirrors SimulateMEVRewards
+func (a *API) grpcSimulateMEVRewards(ctx context.Context, msg []byte) ([]byte, error) {
+	req, err := parseProto(msg)
+	if err != nil {
+		return nil, grpcwire.Errorf(grpcwire.InvalidArgument, "invalid request: %v", err)
+	}
+
+	validatorIndex, _ := req.int(1)
+	blockCount, _ := req.int(2)
+	runs, _ := req.int(4)
+	sim := models.SimulationRequest{
+		ValidatorIndex: int(validatorIndex),
+		BlockCount:     int(int32(blockCount)),
+		Runs:           int(int32(runs)),
+		Distribution:   req.string(5),
+	}
+	if seed, ok := req.int(3); ok {
+		sim.Seed = &seed
+	}
+	for num, field := range map[protowire.Number]**float64{6: &sim.CustomAvgReward, 7: &sim.CustomMEVProbability, 8: &sim.CustomMaxReward} {
+		if v, ok := req.double(num); ok {
+			*field = &v
+		}
+	}
+	body, err := json.Marshal(sim)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	setQuery(query, "currency", req.string(9))
+
+	var resp models.SimulationResponse
+	if err := grpcCall(ctx, a.SimulateMEVRewards, http.MethodPost, nil, query, body, &resp); err != nil {
+		return nil, err
+	}
+	return encodeSimulationResponse(resp), nil
+}
+
+// grpcCall runs handler and decodes its JSON response into resp, or
+// converts its error response to a gRPC status
+func grpcCall(ctx context.Context, handler gin.HandlerFunc, method string, params gin.Params, query url.Values, body []byte, resp interface{}) error {
+	w := runHandler(ctx, handler, method, params, query, body)
+	if w.Code != http.StatusOK {
+		return grpcwire.Errorf(grpcCode(w), "%s", handlerError(w))
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+		return grpcwire.Errorf(grpcwire.Internal, "failed to decode response: %v", err)
+	}
+	return nil
+}
+
+// grpcCode maps a handler's HTTP status to the equivalent gRPC code
+func grpcCode(w *httptest.ResponseRecorder) grpcwire.Code {
+	switch w.Code {
+	case http.StatusBadRequest:
+		return grpcwire.InvalidArgument
+	case http.StatusNotFound:
+		return grpcwire.NotFound
+	case http.StatusTooManyRequests:
+		return grpcwire.ResourceExhausted
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		return grpcwire.Unavailable
+	case http.StatusGatewayTimeout:
+		return grpcwire.DeadlineExceeded
+	case http.StatusInternalServerError:
+		return grpcwire.Internal
+	}
+	return grpcwire.Unknown
+}
+
+// setQuery sets name to value unless value is empty
+func setQuery(query url.Values, name, value string) {
+	if value != "" {
+		query.Set(name, value)
+	}
+}
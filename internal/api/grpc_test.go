@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/grpcwire"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// grpcInvoke calls method on a's gRPC service over an in-process h2c
+// connection and returns the decoded response fields
+func grpcInvoke(t *testing.T, a *API, method string, req []byte) (protoFields, error) {
+	t.Helper()
+	srv := httptest.NewServer(grpcwire.H2C(a.GRPCServer()))
+	t.Cleanup(srv.Close)
+
+	resp, err := grpcwire.Invoke(context.Background(), grpcwire.NewH2CClient(), srv.URL, "/"+GRPCService+"/"+method, req)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseProto(resp)
+	if err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	return fields, nil
+}
+
+// wantGRPCCode fails unless err is a status with code
+func wantGRPCCode(t *testing.T, err error, code grpcwire.Code) {
+	t.Helper()
+	var st *grpcwire.Status
+	if !errors.As(err, &st) {
+		t.Fatalf("got error %v, want status %d", err, code)
+	}
+	if st.Code != code {
+		t.Errorf("got status %d %q, want %d", st.Code, st.Message, code)
+	}
+}
+
+func TestGRPCGetBlockMEV(t *testing.T) {
+	a, srv := newTestAPI(t)
+	srv.SetLatest(1000)
+	srv.AddBlock(100, &models.Block{
+		Miner: testFeeRecipient,
+		Transactions: []models.Transaction{
+			{Hash: "0x01", From: "0x0000000000007f150bd6f54c40a34d7c3d5e9f56", To: "0x2222222222222222222222222222222222222222", Value: "0x0", GasPrice: "0x3b9aca00", Input: "0x"},
+			{Hash: "0x02", From: "0x1111111111111111111111111111111111111111", To: "0x2222222222222222222222222222222222222222", Value: "0x3635c9adc5dea00000", GasPrice: "0x3b9aca00", Input: "0x"}, // 1000 ETH
+		},
+	})
+
+	var req []byte
+	req = appendString(req, 1, "100")
+	resp, err := grpcInvoke(t, a, "GetBlockMEV", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := resp.int(1); got != 100 {
+		t.Errorf("block_number = %d, want 100", got)
+	}
+	if got := len(resp[2]); got != 2 {
+		t.Fatalf("got %d opportunities, want 2", got)
+	}
+	opp, err := parseProto(resp[2][0].b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := opp.string(1); got != "known_bot" {
+		t.Errorf("first opportunity type = %q, want known_bot", got)
+	}
+	if got := len(opp[3]); got != 1 {
+		t.Errorf("first opportunity has %d transactions, want 1", got)
+	}
+	if got := resp.string(7); got != a.mevDetector.NativeSymbol {
+		t.Errorf("currency = %q, want %q", got, a.mevDetector.NativeSymbol)
+	}
+	if _, ok := resp.last(9); !ok {
+		t.Error("response has no timestamp")
+	}
+
+	// min_confidence narrows the opportunities as the query parameter does
+	req = appendOptionalDouble(req, 2, ptr(0.5))
+	resp, err = grpcInvoke(t, a, "GetBlockMEV", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(resp[2]); got != 1 {
+		t.Errorf("got %d opportunities above 0.5, want 1", got)
+	}
+}
+
+func TestGRPCGetBlockMEVErrors(t *testing.T) {
+	a, srv := newTestAPI(t)
+	srv.SetLatest(1000)
+
+	_, err := grpcInvoke(t, a, "GetBlockMEV", appendString(nil, 1, "not-a-block"))
+	wantGRPCCode(t, err, grpcwire.InvalidArgument)
+
+	_, err = grpcInvoke(t, a, "GetBlockMEV", []byte{0xff})
+	wantGRPCCode(t, err, grpcwire.InvalidArgument)
+}
+
+func TestGRPCGetValidatorMEVRewards(t *testing.T) {
+	a, srv := newTestAPI(t)
+	for b := 100; b < 110; b++ {
+		srv.AddBlock(b, &models.Block{Miner: testFeeRecipient})
+	}
+
+	var req []byte
+	req = appendInt(req, 1, 1)
+	req = appendInt(req, 2, 100)
+	req = appendInt(req, 3, 109)
+	req = appendString(req, 4, testFeeRecipient)
+	resp, err := grpcInvoke(t, a, "GetValidatorMEVRewards", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := resp.int(10); got != 10 {
+		t.Errorf("total_blocks = %d, want 10", got)
+	}
+	if got := len(resp[12]); got != 10 {
+		t.Errorf("got %d blocks, want 10", got)
+	}
+	if got := resp.string(4); got != testFeeRecipient {
+		t.Errorf("fee_recipient = %q, want %q", got, testFeeRecipient)
+	}
+
+	// An inverted range fails as it does over REST
+	req = appendInt(nil, 1, 1)
+	req = appendInt(req, 2, 109)
+	req = appendInt(req, 3, 100)
+	req = appendString(req, 4, testFeeRecipient)
+	_, err = grpcInvoke(t, a, "GetValidatorMEVRewards", req)
+	wantGRPCCode(t, err, grpcwire.InvalidArgument)
+}
+
+func TestGRPCSimulateMEVRewards(t *testing.T) {
+	a, _ := newTestAPI(t)
+
+	var req []byte
+	req = appendInt(req, 1, 1)
+	req = appendInt(req, 2, 20)
+	req = appendInt(req, 3, 42)
+	req = appendOptionalDouble(req, 6, ptr(0.1))
+	req = appendOptionalDouble(req, 7, ptr(0.5))
+	resp, err := grpcInvoke(t, a, "SimulateMEVRewards", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := resp.int(2); got != 20 {
+		t.Errorf("simulated_block_count = %d, want 20", got)
+	}
+	if got := len(resp[11]); got != 20 {
+		t.Errorf("got %d simulated blocks, want 20", got)
+	}
+	if interval, ok := resp.last(10); !ok || len(interval.b) != 16 {
+		t.Errorf("confidence_interval = %v, want two packed doubles", interval.b)
+	}
+
+	// Required fields are enforced by the REST handler
+	_, err = grpcInvoke(t, a, "SimulateMEVRewards", appendInt(nil, 1, 1))
+	wantGRPCCode(t, err, grpcwire.InvalidArgument)
+}
+
+func TestParseProtoRoundTrip(t *testing.T) {
+	var msg []byte
+	msg = appendString(msg, 1, "first")
+	msg = appendString(msg, 1, "last")
+	msg = appendInt(msg, 2, -5)
+	msg = appendOptionalDouble(msg, 3, ptr(0.0))
+	msg = appendBool(msg, 4, true)
+	msg = protowire.AppendTag(msg, 5, protowire.Fixed32Type)
+	msg = protowire.AppendFixed32(msg, 7)
+
+	fields, err := parseProto(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fields.string(1); got != "last" {
+		t.Errorf("field 1 = %q, want the last value", got)
+	}
+	if got, _ := fields.int(2); got != -5 {
+		t.Errorf("field 2 = %d, want -5", got)
+	}
+	if got, ok := fields.double(3); !ok || got != 0 {
+		t.Errorf("field 3 = %v, %v, want an explicit 0", got, ok)
+	}
+	if !fields.bool(4) {
+		t.Error("field 4 = false, want true")
+	}
+	if _, ok := fields.double(6); ok {
+		t.Error("absent field 6 reported present")
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
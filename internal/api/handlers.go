@@ -2,26 +2,73 @@ package api
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"math/rand/v2"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/beacon"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/ens"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/live"
 	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/pricing"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/relay"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Default bounds for adaptive block-scan concurrency
+const (
+	DefaultMinConcurrency = 1
+	DefaultMaxConcurrency = 10
+)
+
+// Defaults for request limits
+const (
+	DefaultMaxBlockRange  = 1000
+	DefaultRequestTimeout = 5 * time.Second
+	DefaultBlockRetries   = 1
+)
+
 type API struct {
 	mevDetector *models.MEVDetector
+	beacon      *beacon.Client
+	store       storage.Store
+	head        headCache // Latest head fetched, reused to decide finality
+
+	MinConcurrency int                  // Lower bound on concurrent block fetches during scans
+	MaxConcurrency int                  // Upper bound on concurrent block fetches during scans
+	MaxBlockRange  int                  // Largest span, in blocks, a range request may cover
+	RequestTimeout time.Duration        // Deadline for single-block analysis
+	FinalityDepth  int                  // Blocks behind the head after which results are cached as immutable
+	BlockRetries   int                  // Times a scan re-analyzes a failed block before reporting it
+	MinConfidence  float64              // Opportunities scoring below this are left out of persisted results
+	FeeRecipients  map[int]string       // Validator index to fee recipient address
+	Relays         *relay.Client        // Optional source of delivered MEV-Boost payloads
+	Prices         pricing.PriceOracle  // Converts rewards to USD on request
+	Names          *ens.Resolver        // Resolves addresses to ENS names on request
+	Live           *live.Feed           // New blocks streamed to WebSocket clients
+	Pending        *live.PendingMonitor // Opportunities among pending transactions; nil when disabled
 }
 
-func NewAPI(alchemyURL, alchemyKey string) *API {
+func NewAPI(mevDetector *models.MEVDetector, beaconClient *beacon.Client, store storage.Store) *API {
 	return &API{
-		mevDetector: models.NewMEVDetector(alchemyURL, alchemyKey),
+		mevDetector: mevDetector,
+		beacon:      beaconClient,
+		store:       store,
+
+		MinConcurrency: DefaultMinConcurrency,
+		MaxConcurrency: DefaultMaxConcurrency,
+		MaxBlockRange:  DefaultMaxBlockRange,
+		RequestTimeout: DefaultRequestTimeout,
+		FinalityDepth:  DefaultFinalityDepth,
+		BlockRetries:   DefaultBlockRetries,
 	}
 }
 
@@ -30,38 +77,97 @@ func NewAPI(alchemyURL, alchemyKey string) *API {
 // @Tags MEV
 // @Accept json
 // @Produce json
-// @Param blockNumber path int true "Block number to analyze"
+// @Param blockNumber path string true "Block number to analyze: decimal, 0x-prefixed hex, latest, pending or earliest"
+// @Param currency query string false "Set to usd to include USD values"
+// @Param minConfidence query number false "Omit opportunities scoring below this confidence, from 0 to 1 (default: 0)"
+// @Param resolveNames query bool false "Annotate transaction senders and recipients with their ENS names (default: false)"
+// @Param If-None-Match header string false "ETag from an earlier response for a finalized block"
 // @Success 200 {object} models.MEVOpportunitiesResponse
+// @Success 304 "Not modified; finalized blocks carry an ETag"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
-// @Router /mev/block/{blockNumber} [get]
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/mev/block/{blockNumber} [get]
 func (a *API) GetBlockMEV(c *gin.Context) {
-	blockNumber, err := strconv.Atoi(c.Param("blockNumber"))
-	if err != nil {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), a.RequestTimeout)
+	defer cancel()
+
+	minConfidence, err := strconv.ParseFloat(c.DefaultQuery("minConfidence", "0"), 64)
+	if err != nil || minConfidence < 0 || minConfidence > 1 {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid block number",
+			Error: "minConfidence must be a number between 0 and 1",
 		})
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	resolveNames, err := strconv.ParseBool(c.DefaultQuery("resolveNames", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid resolveNames parameter",
+		})
+		return
+	}
 
-	opportunities, err := a.mevDetector.CheckMEV(ctx, blockNumber)
+	blockNumber, err := a.resolveBlockParam(ctx, c.Param("blockNumber"))
+	if errors.Is(err, errInvalidBlockParam) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid block number",
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: fmt.Sprintf("Failed to analyze block: %v", err),
+			Error: fmt.Sprintf("Failed to get latest block: %v", err),
 		})
 		return
 	}
 
-	mevReward := a.mevDetector.CalculateMEVReward(opportunities)
+	result, err := a.analyzeBlock(ctx, blockNumber)
+	if err != nil {
+		writeProviderError(c, "Failed to analyze block", err)
+		return
+	}
+	if minConfidence > 0 {
+		result.Opportunities = models.FilterByConfidence(result.Opportunities, minConfidence)
+		result.ValidatorReward, _ = a.mevDetector.CalculateMEVReward(result.Opportunities)
+	}
+
+	if resolveNames {
+		result.Opportunities = a.resolveNames(ctx, result.Opportunities)
+	}
 
-	c.JSON(http.StatusOK, models.MEVOpportunitiesResponse{
+	resp := models.MEVOpportunitiesResponse{
 		BlockNumber:              blockNumber,
-		Opportunities:            opportunities,
-		EstimatedValidatorReward: mevReward,
-		Timestamp:                time.Now(),
+		Opportunities:            result.Opportunities,
+		EstimatedValidatorReward: result.ValidatorReward,
+		RewardByType:             a.mevDetector.RewardByType(result.Opportunities),
+		Warnings:                 a.mevDetector.PlausibilityWarnings(result.ValidatorReward),
+		Currency:                 a.mevDetector.NativeSymbol,
+		BlockTime:                result.BlockTime,
+	}
+
+	// A finalized block's analysis never changes, unless annotated with ENS
+	// names or prices, which do
+	if !resolveNames && !strings.EqualFold(c.Query("currency"), "usd") && a.isFinalized(ctx, blockNumber) {
+		if etag, err := strongETag(resp); err == nil && notModified(c, etag) {
+			return
+		}
+	}
+
+	resp.ValueUSD = a.usdValue(c, result.ValidatorReward)
+	resp.Timestamp = time.Now()
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary List MEV detectors
+// @Description Returns metadata for each detector including its signals and current thresholds
+// @Tags MEV
+// @Produce json
+// @Success 200 {object} models.DetectorsResponse
+// @Router /api/v1/detectors [get]
+func (a *API) GetDetectors(c *gin.Context) {
+	c.JSON(http.StatusOK, models.DetectorsResponse{
+		Detectors: a.mevDetector.Detectors(),
 	})
 }
 
@@ -69,14 +175,20 @@ func (a *API) GetBlockMEV(c *gin.Context) {
 // @Description Returns estimated MEV rewards for a validator across multiple blocks
 // @Tags Validator
 // @Accept json
-// @Produce json
+// @Produce json,text/csv
 // @Param validatorIndex path int true "Validator index"
 // @Param fromBlock query int false "Starting block number (default: latest - 100)"
 // @Param toBlock query int false "Ending block number (default: latest)"
+// @Param feeRecipient query string false "Validator's fee recipient address when no beacon node is configured (default: from blockchain.fee_recipients)"
+// @Param skipErrors query bool false "Report failed blocks instead of failing the whole scan (default: false)"
+// @Param currency query string false "Set to usd to include USD values"
+// @Param format query string false "json (default) or csv to stream one row per block as text/csv"
+// @Param page query int false "Page of blocks to return, from 1; totals still cover the whole range (default: all blocks)"
+// @Param pageSize query int false "Blocks per page when paginating (default: 100, max: 1000)"
 // @Success 200 {object} models.ValidatorMEVResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
-// @Router /validator/{validatorIndex}/mev-rewards [get]
+// @Router /api/v1/validator/{validatorIndex}/mev-rewards [get]
 func (a *API) GetValidatorMEVRewards(c *gin.Context) {
 	validatorIndex, err := strconv.Atoi(c.Param("validatorIndex"))
 	if err != nil {
@@ -86,26 +198,256 @@ func (a *API) GetValidatorMEVRewards(c *gin.Context) {
 		return
 	}
 
+	skipErrors, err := strconv.ParseBool(c.DefaultQuery("skipErrors", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid skipErrors parameter",
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "format must be json or csv",
+		})
+		return
+	}
+
+	page, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	fromBlock, toBlock, ok := a.parseBlockRange(c)
+	if !ok {
+		return
+	}
+
+	blockNumbers, feeRecipient, ok := a.validatorBlocks(c, validatorIndex, fromBlock, toBlock)
+	if !ok {
+		return
+	}
+
+	if format == "csv" {
+		a.writeValidatorCSV(c, blockNumbers, feeRecipient, skipErrors)
+		return
+	}
+
+	// Analyze blocks in batched chunks in parallel; an early return cancels
+	// in-flight work
+	ctx := c.Request.Context()
+	gasPrices := &gasPriceCollector{}
+	analyzed := make([]*models.BlockMEVResult, toBlock-fromBlock+1)
+	var (
+		mu           sync.Mutex
+		failedBlocks []models.BlockError
+	)
+	err = a.forEachChunk(ctx, blockNumbers, blockBatchSize, func(ctx context.Context, blockNumbers []int) error {
+		results, errs := a.analyzeBlocks(ctx, blockNumbers)
+		for i, b := range blockNumbers {
+			if err := errs[i]; err != nil {
+				if !skipErrors || ctx.Err() != nil {
+					return fmt.Errorf("block %d: %w", b, err)
+				}
+				mu.Lock()
+				failedBlocks = append(failedBlocks, models.BlockError{BlockNumber: b, Error: err.Error()})
+				mu.Unlock()
+				continue
+			}
+
+			result := results[i]
+			if feeRecipient != "" && result.FeeRecipient != feeRecipient {
+				continue // Proposed by another validator
+			}
+
+			gasPrices.add(result)
+			result.Warnings = a.mevDetector.PlausibilityWarnings(result.ValidatorReward)
+			analyzed[b-fromBlock] = &result
+		}
+		return nil
+	})
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Request cancelled",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing blocks: %v", err),
+		})
+		return
+	}
+
+	sort.Slice(failedBlocks, func(i, j int) bool {
+		return failedBlocks[i].BlockNumber < failedBlocks[j].BlockNumber
+	})
+
+	tally := newRewardTally(a.mevDetector)
+	var (
+		blockResults []models.BlockMEVResult
+		mevBlocks    int
+	)
+	for _, result := range analyzed {
+		if result == nil {
+			continue // Failed and skipped, or not proposed by the validator
+		}
+		blockResults = append(blockResults, *result)
+		tally.add(*result)
+		if result.ValidatorReward > 0 {
+			mevBlocks++
+		}
+	}
+
+	resp := models.ValidatorMEVResponse{
+		ValidatorIndex:        validatorIndex,
+		FromBlock:             fromBlock,
+		ToBlock:               toBlock,
+		FeeRecipient:          feeRecipient,
+		TotalMEVReward:        tally.total,
+		RewardByType:          tally.byType,
+		ValueUSD:              a.usdValue(c, tally.total),
+		DuplicateTransactions: tally.duplicates,
+		MEVBlocks:             mevBlocks,
+		TotalBlocks:           toBlock - fromBlock + 1,
+		AnalyzedBlocks:        len(blockResults),
+		Blocks:                blockResults,
+		FailedBlocks:          failedBlocks,
+		GasPrices:             gasPrices.percentiles(),
+		Currency:              a.mevDetector.NativeSymbol,
+		Timestamp:             time.Now(),
+	}
+	if page != nil {
+		start, end, totalPages := page.bounds(len(blockResults))
+		resp.Blocks = blockResults[start:end]
+		resp.Page, resp.PageSize, resp.TotalPages = page.page, page.pageSize, totalPages
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// validatorBlocks returns the blocks in [fromBlock, toBlock] to scan for a
+// validator. With a beacon node configured these are exactly the blocks the
+// validator proposed. Otherwise the whole range is returned along with the
+// fee recipient that scanned blocks must match to be attributed to the
+// validator. It writes an error response when the blocks cannot be resolved.
+func (a *API) validatorBlocks(c *gin.Context, validatorIndex, fromBlock, toBlock int) ([]int, string, bool) {
+	if a.beacon == nil {
+		feeRecipient, ok := a.resolveFeeRecipient(c, validatorIndex)
+		if !ok {
+			return nil, "", false
+		}
+		return blockRange(fromBlock, toBlock), feeRecipient, true
+	}
+
+	blockNumbers, err := a.proposedBlocks(c.Request.Context(), validatorIndex, fromBlock, toBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get proposed blocks: %v", err),
+		})
+		return nil, "", false
+	}
+	return blockNumbers, "", true
+}
+
+// proposedBlocks maps [fromBlock, toBlock] onto beacon slots by block
+// timestamp and returns the blocks in the range the validator proposed
+func (a *API) proposedBlocks(ctx context.Context, validatorIndex, fromBlock, toBlock int) ([]int, error) {
+	fromSlot, toSlot, err := a.slotRange(ctx, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	proposed, err := a.beacon.ProposedBlocks(ctx, validatorIndex, fromSlot, toSlot)
+	if err != nil {
+		return nil, err
+	}
+
+	var blockNumbers []int
+	for _, b := range proposed {
+		if b >= fromBlock && b <= toBlock {
+			blockNumbers = append(blockNumbers, b)
+		}
+	}
+	return blockNumbers, nil
+}
+
+// slotRange returns the beacon slots containing fromBlock and toBlock
+func (a *API) slotRange(ctx context.Context, fromBlock, toBlock int) (int, int, error) {
+	fromTime, err := a.mevDetector.BlockTime(ctx, fromBlock)
+	if err != nil {
+		return 0, 0, fmt.Errorf("block %d: %w", fromBlock, err)
+	}
+	toTime, err := a.mevDetector.BlockTime(ctx, toBlock)
+	if err != nil {
+		return 0, 0, fmt.Errorf("block %d: %w", toBlock, err)
+	}
+
+	fromSlot, err := a.beacon.SlotAt(ctx, fromTime)
+	if err != nil {
+		return 0, 0, err
+	}
+	toSlot, err := a.beacon.SlotAt(ctx, toTime)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return fromSlot, toSlot, nil
+}
+
+// resolveFeeRecipient returns the lowercased fee recipient blocks are
+// attributed to the validator by, taken from the feeRecipient query parameter
+// or the configured mapping. It writes an error response when neither is
+// available, since scanning without attribution would report rewards from
+// blocks the validator did not propose.
+func (a *API) resolveFeeRecipient(c *gin.Context, validatorIndex int) (string, bool) {
+	addr := c.Query("feeRecipient")
+	if addr == "" {
+		addr = a.FeeRecipients[validatorIndex]
+	}
+	if addr == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("No fee recipient known for validator %d: pass feeRecipient or set blockchain.fee_recipients", validatorIndex),
+		})
+		return "", false
+	}
+
+	addr, ok := models.NormalizeAddress(addr)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid feeRecipient address",
+		})
+		return "", false
+	}
+
+	return addr, true
+}
+
+// parseBlockRange reads the fromBlock/toBlock query parameters, defaulting to
+// the last 100 blocks, and writes an error response when the range is invalid
+func (a *API) parseBlockRange(c *gin.Context) (int, int, bool) {
 	// Get block range from query params or use defaults
 	fromBlock := -1
 	if fromStr := c.Query("fromBlock"); fromStr != "" {
+		var err error
 		fromBlock, err = strconv.Atoi(fromStr)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, models.ErrorResponse{
 				Error: "Invalid fromBlock parameter",
 			})
-			return
+			return 0, 0, false
 		}
 	}
 
 	toBlock := -1
 	if toStr := c.Query("toBlock"); toStr != "" {
+		var err error
 		toBlock, err = strconv.Atoi(toStr)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, models.ErrorResponse{
 				Error: "Invalid toBlock parameter",
 			})
-			return
+			return 0, 0, false
 		}
 	}
 
@@ -116,7 +458,7 @@ func (a *API) GetValidatorMEVRewards(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 				Error: fmt.Sprintf("Failed to get latest block: %v", err),
 			})
-			return
+			return 0, 0, false
 		}
 
 		if fromBlock == -1 {
@@ -131,146 +473,149 @@ func (a *API) GetValidatorMEVRewards(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error: "fromBlock must be less than toBlock",
 		})
-		return
+		return 0, 0, false
 	}
 
-	// Limit to 1000 blocks max for performance
-	if toBlock-fromBlock > 1000 {
+	// Limit the range for performance
+	if toBlock-fromBlock+1 > a.MaxBlockRange {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Block range too large (max 1000 blocks)",
+			Error: fmt.Sprintf("Block range too large (max %d blocks)", a.MaxBlockRange),
 		})
-		return
+		return 0, 0, false
 	}
 
-	// Analyze blocks in parallel with worker pool
-	results := make(chan models.BlockMEVResult)
-	errors := make(chan error)
-	ctx := c.Request.Context()
+	return fromBlock, toBlock, true
+}
 
-	go func() {
-		defer close(results)
-		defer close(errors)
-
-		sem := make(chan struct{}, 10) // Limit concurrent requests
-		for blockNumber := fromBlock; blockNumber <= toBlock; blockNumber++ {
-			sem <- struct{}{}
-			go func(b int) {
-				defer func() { <-sem }()
-
-				select {
-				case <-ctx.Done():
-					errors <- ctx.Err()
-					return
-				default:
-					opps, err := a.mevDetector.CheckMEV(ctx, b)
-					if err != nil {
-						errors <- fmt.Errorf("block %d: %w", b, err)
-						return
-					}
-
-					reward := a.mevDetector.CalculateMEVReward(opps)
-					results <- models.BlockMEVResult{
-						BlockNumber:     b,
-						Opportunities:   opps,
-						ValidatorReward: reward,
-					}
-				}
-			}(blockNumber)
+// writeProviderError responds to a failed provider request. Rate limiting is
+// reported as 503 with the provider's Retry-After, so clients can tell it
+// from other failures; anything else is a 500.
+func writeProviderError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	var rateLimited *models.RateLimitError
+	if errors.As(err, &rateLimited) {
+		status = http.StatusServiceUnavailable
+		if rateLimited.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(rateLimited.RetryAfter.Seconds()))))
 		}
-	}()
-
-	var (
-		totalReward  float64
-		blockResults []models.BlockMEVResult
-		mevBlocks    int
-	)
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-				Error: "Request cancelled",
-			})
-			return
-		case err := <-errors:
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-				Error: fmt.Sprintf("Error processing blocks: %v", err),
-			})
-			return
-		case result, ok := <-results:
-			if !ok {
-				// All blocks processed
-				c.JSON(http.StatusOK, models.ValidatorMEVResponse{
-					ValidatorIndex: validatorIndex,
-					FromBlock:      fromBlock,
-					ToBlock:        toBlock,
-					TotalMEVReward: totalReward,
-					MEVBlocks:      mevBlocks,
-					TotalBlocks:    toBlock - fromBlock + 1,
-					Blocks:         blockResults,
-					Timestamp:      time.Now(),
-				})
-				return
-			}
+	c.JSON(status, models.ErrorResponse{
+		Error: fmt.Sprintf("%s: %v", message, err),
+	})
+}
 
-			blockResults = append(blockResults, result)
-			totalReward += result.ValidatorReward
-			if result.ValidatorReward > 0 {
-				mevBlocks++
-			}
+// isFinalized reports whether blockNumber is at least FinalityDepth blocks
+// behind the head. Blocks are treated as unfinalized when the head is unknown.
+func (a *API) isFinalized(ctx context.Context, blockNumber int) bool {
+	if a.FinalityDepth <= 0 {
+		return false
+	}
+	latest, ok := a.head.get(headCacheTTL)
+	if !ok {
+		var err error
+		if latest, err = a.getLatestBlockNumber(ctx); err != nil {
+			return false
 		}
 	}
+	return blockNumber <= latest-a.FinalityDepth
 }
 
-func (a *API) getLatestBlockNumber(ctx context.Context) (int, error) {
-	url := fmt.Sprintf("%s/%s", a.mevDetector.AlchemyAPIURL, a.mevDetector.AlchemyAPIKey)
-	payload := `{"jsonrpc":"2.0","method":"eth_blockNumber","params":[],"id":1}`
+// errInvalidBlockParam is returned for block parameters that are neither a
+// number nor a known block tag
+var errInvalidBlockParam = errors.New("invalid block parameter")
+
+// resolveBlockParam converts a decimal or 0x-prefixed hex block number, or
+// one of the latest, pending and earliest tags, into a block number. Only
+// mined blocks can be analyzed, so pending resolves to the latest block.
+func (a *API) resolveBlockParam(ctx context.Context, s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "latest", "pending":
+		return a.getLatestBlockNumber(ctx)
+	case "earliest":
+		return 0, nil
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(payload))
-	if err != nil {
-		return 0, fmt.Errorf("failed to create request: %w", err)
+	var (
+		n   int64
+		err error
+	)
+	if hex, ok := strings.CutPrefix(strings.ToLower(s), "0x"); ok {
+		n, err = strconv.ParseInt(hex, 16, 64)
+	} else {
+		n, err = strconv.ParseInt(s, 10, 64)
+	}
+	if err != nil || n < 0 {
+		return 0, errInvalidBlockParam
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := a.mevDetector.HttpClient.Do(req)
+	return int(n), nil
+}
+
+func (a *API) getLatestBlockNumber(ctx context.Context) (int, error) {
+	latest, err := a.mevDetector.BlockNumber(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+		return 0, err
 	}
-	defer resp.Body.Close()
+	a.head.set(latest)
+	return latest, nil
+}
 
-	var result struct {
-		Result string `json:"result"`
-		Error  struct {
-			Message string `json:"message"`
-		} `json:"error"`
+// historicalDistribution derives a simulation distribution from up to
+// blockCount of the latest blocks, capped at 100, and returns it with the
+// latest block number. It writes an error response and returns false on
+// failure.
+func (a *API) historicalDistribution(c *gin.Context, blockCount int) (int, rewardDistribution, bool) {
+	ctx := c.Request.Context()
+	latestBlock, err := a.getLatestBlockNumber(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get latest block: %v", err),
+		})
+		return 0, rewardDistribution{}, false
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, fmt.Errorf("failed to decode response: %w", err)
+	// Use historical MEV data to simulate future blocks
+	historicalBlocks := 100
+	if blockCount < historicalBlocks {
+		historicalBlocks = blockCount
 	}
 
-	if result.Error.Message != "" {
-		return 0, fmt.Errorf("API error: %s", result.Error.Message)
+	historicalRewards := make([]float64, 0, historicalBlocks)
+	for i := 0; i < historicalBlocks; i++ {
+		blockNumber := latestBlock - i
+		result, err := a.analyzeBlock(ctx, blockNumber)
+		if err != nil {
+			continue // Skip failed blocks
+		}
+		historicalRewards = append(historicalRewards, result.ValidatorReward)
 	}
 
-	blockNumber, err := strconv.ParseInt(result.Result[2:], 16, 64)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse block number: %w", err)
+	if len(historicalRewards) == 0 {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to fetch any historical blocks",
+		})
+		return 0, rewardDistribution{}, false
 	}
 
-	return int(blockNumber), nil
+	// Calculate statistics for simulation from the blocks actually fetched
+	return latestBlock, summarizeRewards(historicalRewards), true
 }
 
+// maxSimulationRuns bounds the Monte Carlo runs per simulation request
+const maxSimulationRuns = 1000
+
 // @Summary Simulate MEV rewards for a validator
-// @Description Simulates potential MEV rewards for a validator over future blocks
+// @Description Simulates potential MEV rewards for a validator over future blocks, drawing rewards from recent blocks or from a custom distribution given in the request
 // @Tags Validator
 // @Accept json
 // @Produce json
 // @Param request body models.SimulationRequest true "Simulation parameters"
+// @Param currency query string false "Set to usd to include USD values"
 // @Success 200 {object} models.SimulationResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
-// @Router /simulate [post]
+// @Router /api/v1/simulate [post]
 func (a *API) SimulateMEVRewards(c *gin.Context) {
 	var req models.SimulationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -294,87 +639,90 @@ func (a *API) SimulateMEVRewards(c *gin.Context) {
 		return
 	}
 
-	ctx := c.Request.Context()
-	latestBlock, err := a.getLatestBlockNumber(ctx)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: fmt.Sprintf("Failed to get latest block: %v", err),
+	switch req.Distribution {
+	case "":
+		req.Distribution = distributionExponential
+	case distributionExponential, distributionLogNormal:
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Distribution must be exponential or lognormal",
 		})
 		return
 	}
 
-	// Use historical MEV data to simulate future blocks
-	historicalBlocks := 100
-	if req.BlockCount < historicalBlocks {
-		historicalBlocks = req.BlockCount
+	if req.Runs == 0 {
+		req.Runs = 1
+	}
+	if req.Runs < 0 || req.Runs > maxSimulationRuns {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("Runs must be between 1 and %d", maxSimulationRuns),
+		})
+		return
 	}
 
-	historicalRewards := make([]float64, 0, historicalBlocks)
-	for i := 0; i < historicalBlocks; i++ {
-		blockNumber := latestBlock - i
-		opps, err := a.mevDetector.CheckMEV(ctx, blockNumber)
-		if err != nil {
-			continue // Skip failed blocks
-		}
-		reward := a.mevDetector.CalculateMEVReward(opps)
-		historicalRewards = append(historicalRewards, reward)
+	dist, custom, err := customDistribution(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("Invalid request: %v", err),
+		})
+		return
 	}
 
-	// Calculate statistics for simulation
-	var (
-		totalHistoricalReward float64
-		mevBlocksCount        int
-		maxReward             float64
-	)
-	for _, reward := range historicalRewards {
-		totalHistoricalReward += reward
-		if reward > 0 {
-			mevBlocksCount++
-		}
-		if reward > maxReward {
-			maxReward = reward
+	// Custom distributions need no chain data, so their blocks are numbered
+	// from 1
+	var latestBlock int
+	if !custom {
+		var ok bool
+		latestBlock, dist, ok = a.historicalDistribution(c, req.BlockCount)
+		if !ok {
+			return
 		}
+		dist.kind = req.Distribution
 	}
 
-	avgReward := totalHistoricalReward / float64(historicalBlocks)
-	mevProbability := float64(mevBlocksCount) / float64(historicalBlocks)
-
-	// Generate simulation results
+	// Generate simulation results; the first run's blocks are returned and
+	// every run contributes to the statistics
+	rng := newRand(req.Seed)
 	var (
-		totalSimulatedReward   float64
-		simulatedBlocksWithMEV int
-		blocks                 []models.SimulatedBlock
+		blocks        []models.SimulatedBlock
+		totals        = make([]float64, req.Runs)
+		blockRewards  = make([]float64, 0, req.Runs*req.BlockCount)
+		sum           float64
+		blocksWithMEV int // Summed across runs
 	)
-
-	for i := 0; i < req.BlockCount; i++ {
-		var reward float64
-		hasMEV := rand.Float64() < mevProbability //nolint:gosec
-
-		if hasMEV {
-			// Use exponential distribution for MEV rewards
-			reward = rand.ExpFloat64() * avgReward //nolint:gosec
-			if reward > maxReward*2 {
-				reward = maxReward * 2
-			}
-			totalSimulatedReward += reward
-			simulatedBlocksWithMEV++
+	for run := range totals {
+		runBlocks, total, withMEV := simulateBlocks(rng, latestBlock, req.BlockCount, dist)
+		if run == 0 {
+			blocks = runBlocks
 		}
-
-		blocks = append(blocks, models.SimulatedBlock{
-			BlockNumber:     latestBlock + i + 1,
-			HasMEV:          hasMEV,
-			EstimatedReward: reward,
-		})
+		blocksWithMEV += withMEV
+		for _, block := range runBlocks {
+			blockRewards = append(blockRewards, block.EstimatedReward)
+		}
+		totals[run] = total
+		sum += total
 	}
+	sort.Float64s(totals)
+	sort.Float64s(blockRewards)
 
+	meanTotal := sum / float64(req.Runs)
 	c.JSON(http.StatusOK, models.SimulationResponse{
 		ValidatorIndex:      req.ValidatorIndex,
 		SimulatedBlockCount: req.BlockCount,
-		TotalReward:         totalSimulatedReward,
-		AverageReward:       totalSimulatedReward / float64(req.BlockCount),
-		BlocksWithMEV:       simulatedBlocksWithMEV,
-		MEVProbability:      mevProbability,
-		Blocks:              blocks,
-		Timestamp:           time.Now(),
+		Runs:                req.Runs,
+		TotalReward:         meanTotal,
+		ValueUSD:            a.usdValue(c, meanTotal),
+		AverageReward:       meanTotal / float64(req.BlockCount),
+		BlocksWithMEV:       float64(blocksWithMEV) / float64(req.Runs),
+		MEVProbability:      dist.mevProbability,
+		Percentiles: map[string]float64{
+			"p50": percentile(blockRewards, 50),
+			"p90": percentile(blockRewards, 90),
+			"p99": percentile(blockRewards, 99),
+		},
+		ConfidenceInterval: [2]float64{percentile(totals, 2.5), percentile(totals, 97.5)},
+		Blocks:             blocks,
+		Currency:           a.mevDetector.NativeSymbol,
+		Timestamp:          time.Now(),
 	})
 }
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testFeeRecipient = "0x1111111111111111111111111111111111111111"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestAPI returns an API reading from a fake RPC server with no store or
+// beacon node
+func newTestAPI(t *testing.T) (*API, *testutil.RPCServer) {
+	t.Helper()
+	srv := testutil.NewRPCServer()
+	t.Cleanup(srv.Close)
+	return NewAPI(srv.Detector(), nil, nil), srv
+}
+
+// serve runs a request through a router with the API's routes used by tests
+func serve(a *API, req *http.Request) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.GET("/mev/block/:blockNumber", a.GetBlockMEV)
+	router.GET("/validator/:validatorIndex/mev-rewards", a.GetValidatorMEVRewards)
+	router.POST("/simulate", a.SimulateMEVRewards)
+	router.GET("/validator/:validatorIndex/epoch/:epoch/peers", a.GetValidatorEpochPeers)
+	router.POST("/graphql", a.GraphQL)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetLatestBlockNumber(t *testing.T) {
+	a, srv := newTestAPI(t)
+	srv.SetLatest(19000000)
+
+	got, err := a.getLatestBlockNumber(context.Background())
+	if err != nil {
+		t.Fatalf("getLatestBlockNumber: %v", err)
+	}
+	if got != 19000000 {
+		t.Errorf("getLatestBlockNumber = %d, want 19000000", got)
+	}
+}
+
+func TestGetLatestBlockNumberErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(srv *testutil.RPCServer)
+	}{
+		{
+			name: "rpc error",
+			setup: func(srv *testutil.RPCServer) {
+				srv.SetError("eth_blockNumber", -32000, "header not available")
+			},
+		},
+		{
+			name: "http error",
+			setup: func(srv *testutil.RPCServer) {
+				srv.SetStatus(http.StatusServiceUnavailable)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, srv := newTestAPI(t)
+			tt.setup(srv)
+
+			if _, err := a.getLatestBlockNumber(context.Background()); err == nil {
+				t.Fatal("getLatestBlockNumber succeeded, want error")
+			}
+		})
+	}
+}
+
+// TestGetValidatorMEVRewardsCancelStopsWorkers cancels a scan while blocks
+// are in flight and checks that every worker exits
+func TestGetValidatorMEVRewardsCancelStopsWorkers(t *testing.T) {
+	a, srv := newTestAPI(t)
+	for b := 0; b <= 200; b++ {
+		srv.AddBlock(b, &models.Block{Miner: testFeeRecipient})
+	}
+	srv.SetDelay(50 * time.Millisecond)
+
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/validator/1/mev-rewards?fromBlock=0&toBlock=200&feeRecipient="+testFeeRecipient, nil)
+	w := serve(a, req.WithContext(ctx))
+
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "Request cancelled") {
+		t.Fatalf("got %d %s, want a cancelled scan", w.Code, w.Body.String())
+	}
+
+	// Idle keep-alive connections hold goroutines of their own
+	srv.CloseClientConnections()
+	srv.Client().CloseIdleConnections()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("%d goroutines still running after cancel, %d before the scan\n%s",
+				runtime.NumGoroutine(), before, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGetValidatorMEVRewardsBlockCounts(t *testing.T) {
+	a, srv := newTestAPI(t)
+	for b := 100; b < 110; b++ {
+		miner := testFeeRecipient
+		if b%2 == 0 {
+			miner = "0x2222222222222222222222222222222222222222"
+		}
+		srv.AddBlock(b, &models.Block{Miner: miner})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/validator/1/mev-rewards?fromBlock=100&toBlock=109&feeRecipient="+testFeeRecipient, nil)
+	w := serve(a, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+
+	var resp models.ValidatorMEVResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.TotalBlocks != 10 {
+		t.Errorf("totalBlocks = %d, want the 10 blocks in the range", resp.TotalBlocks)
+	}
+	if resp.AnalyzedBlocks != 5 {
+		t.Errorf("analyzedBlocks = %d, want the 5 blocks proposed by the validator", resp.AnalyzedBlocks)
+	}
+}
+
+func TestGetValidatorMEVRewardsRangeLimit(t *testing.T) {
+	a, srv := newTestAPI(t)
+	a.MaxBlockRange = 400
+	for b := 100; b < 500; b++ {
+		srv.AddBlock(b, &models.Block{Miner: testFeeRecipient})
+	}
+
+	tests := []struct {
+		name     string
+		toBlock  int
+		wantCode int
+	}{
+		{name: "at the limit", toBlock: 499, wantCode: http.StatusOK},
+		{name: "over the limit", toBlock: 500, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/validator/1/mev-rewards?fromBlock=100&toBlock=%d&feeRecipient=%s", tt.toBlock, testFeeRecipient), nil)
+			w := serve(a, req)
+			if w.Code != tt.wantCode {
+				t.Fatalf("got %d %s, want %d", w.Code, w.Body.String(), tt.wantCode)
+			}
+			if tt.wantCode == http.StatusBadRequest && !strings.Contains(w.Body.String(), "max 400 blocks") {
+				t.Errorf("error %s does not name the configured limit", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetValidatorMEVRewardsConcurrencyLimit(t *testing.T) {
+	a, srv := newTestAPI(t)
+	a.MinConcurrency, a.MaxConcurrency = 1, 2
+	for b := 100; b < 140; b++ {
+		srv.AddBlock(b, &models.Block{Miner: testFeeRecipient})
+	}
+	srv.SetDelay(5 * time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodGet, "/validator/1/mev-rewards?fromBlock=100&toBlock=139&feeRecipient="+testFeeRecipient, nil)
+	if w := serve(a, req); w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	if got := srv.MaxConcurrent(); got > 2 {
+		t.Errorf("served %d requests at once, want at most the configured 2", got)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout bounds the provider check performed by Readyz
+const readinessTimeout = 2 * time.Second
+
+// @Summary Liveness probe
+// @Description Returns 200 whenever the service is running
+// @Tags Health
+// @Produce json
+// @Success 200 {object} models.HealthResponse
+// @Router /healthz [get]
+func (a *API) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, models.HealthResponse{Status: "ok"})
+}
+
+// @Summary Readiness probe
+// @Description Returns 200 when the RPC provider answers eth_blockNumber, 503 otherwise
+// @Tags Health
+// @Produce json
+// @Success 200 {object} models.HealthResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /readyz [get]
+func (a *API) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	latestBlock, err := a.getLatestBlockNumber(ctx)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error: fmt.Sprintf("Provider unreachable: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HealthResponse{
+		Status:      "ok",
+		LatestBlock: latestBlock,
+	})
+}
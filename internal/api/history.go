@@ -0,0 +1,130 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxHistoryRange is the largest span, in blocks, a history query may cover.
+// Stored results are read without provider calls, so it exceeds
+// MaxBlockRange.
+const maxHistoryRange = 100000
+
+// @Summary Get stored MEV results for a block range
+// @Description Reads results persisted by the scanner or earlier requests without querying the provider. Blocks without a stored result, or whose result was analyzed with a different detector configuration, are not analyzed; they are reported as gaps. With type set, blocks are narrowed to opportunities of that type and their rewards recomputed.
+// @Tags MEV
+// @Produce json
+// @Param fromBlock query int true "Starting block number"
+// @Param toBlock query int true "Ending block number"
+// @Param type query string false "Opportunity type, e.g. sandwich"
+// @Success 200 {object} models.MEVHistoryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/mev/history [get]
+func (a *API) GetMEVHistory(c *gin.Context) {
+	if a.store == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error: "History requires a database",
+		})
+		return
+	}
+
+	fromBlock, err := strconv.Atoi(c.Query("fromBlock"))
+	if err != nil || fromBlock < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "fromBlock must be a non-negative block number",
+		})
+		return
+	}
+	toBlock, err := strconv.Atoi(c.Query("toBlock"))
+	if err != nil || toBlock < fromBlock {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "toBlock must be a block number no less than fromBlock",
+		})
+		return
+	}
+	if toBlock-fromBlock >= maxHistoryRange {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("Block range too large (max %d blocks)", maxHistoryRange),
+		})
+		return
+	}
+
+	stored, err := a.store.GetBlockResults(c.Request.Context(), fromBlock, toBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to read stored blocks: %v", err),
+		})
+		return
+	}
+
+	resp := a.mevHistory(a.currentResults(stored), fromBlock, toBlock, c.Query("type"))
+	resp.Timestamp = time.Now()
+	c.JSON(http.StatusOK, resp)
+}
+
+// currentResults returns the results in stored that were analyzed with the
+// detector's current configuration, keeping their order
+func (a *API) currentResults(stored []models.BlockMEVResult) []models.BlockMEVResult {
+	version := a.mevDetector.ConfigVersion()
+	current := stored[:0]
+	for _, result := range stored {
+		if result.DetectorVersion == version {
+			current = append(current, result)
+		}
+	}
+	return current
+}
+
+// mevHistory summarizes stored, which must be ordered by block number and
+// within fromBlock-toBlock. Blocks are narrowed to opportunities of oppType
+// when it is set.
+func (a *API) mevHistory(stored []models.BlockMEVResult, fromBlock, toBlock int, oppType string) models.MEVHistoryResponse {
+	resp := models.MEVHistoryResponse{
+		FromBlock:     fromBlock,
+		ToBlock:       toBlock,
+		Type:          oppType,
+		RewardByType:  make(map[string]float64),
+		ScannedBlocks: len(stored),
+		TotalBlocks:   toBlock - fromBlock + 1,
+		Gaps:          []models.BlockRange{},
+		Blocks:        []models.BlockMEVResult{},
+		Currency:      a.mevDetector.NativeSymbol,
+	}
+	resp.Complete = resp.ScannedBlocks == resp.TotalBlocks
+
+	next := fromBlock // First block not yet accounted for
+	for _, result := range stored {
+		if result.BlockNumber > next {
+			resp.Gaps = append(resp.Gaps, models.BlockRange{FromBlock: next, ToBlock: result.BlockNumber - 1})
+		}
+		next = result.BlockNumber + 1
+
+		if oppType != "" {
+			result.Opportunities = models.FilterByType(result.Opportunities, oppType)
+			result.ValidatorReward, _ = a.mevDetector.CalculateMEVReward(result.Opportunities)
+		}
+		if len(result.Opportunities) == 0 {
+			continue
+		}
+
+		resp.Blocks = append(resp.Blocks, result)
+		resp.MEVBlocks++
+		resp.TotalMEVReward += result.ValidatorReward
+		for t, reward := range a.mevDetector.RewardByType(result.Opportunities) {
+			resp.RewardByType[t] += reward
+		}
+	}
+	if next <= toBlock {
+		resp.Gaps = append(resp.Gaps, models.BlockRange{FromBlock: next, ToBlock: toBlock})
+	}
+
+	return resp
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Rank validators by MEV reward
+// @Description Attributes each block in a range to its proposer, via the beacon node or the configured fee recipients, and ranks validators by total estimated MEV reward
+// @Tags Validator
+// @Accept json
+// @Produce json
+// @Param fromBlock query int false "Starting block number (default: latest - 100)"
+// @Param toBlock query int false "Ending block number (default: latest)"
+// @Param limit query int false "Number of validators to return (default: 10, max: 100)"
+// @Success 200 {object} models.LeaderboardResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/validators/leaderboard [get]
+func (a *API) GetValidatorLeaderboard(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "limit must be between 1 and 100",
+		})
+		return
+	}
+
+	if a.beacon == nil && len(a.FeeRecipients) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Attributing blocks to validators requires a beacon node or blockchain.fee_recipients",
+		})
+		return
+	}
+
+	fromBlock, toBlock, ok := a.parseBlockRange(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	proposerOf, err := a.blockProposers(ctx, fromBlock, toBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get block proposers: %v", err),
+		})
+		return
+	}
+
+	var (
+		mu           sync.Mutex
+		entries      = make(map[int]*models.LeaderboardEntry)
+		unattributed int
+	)
+	err = a.forEachChunk(ctx, blockRange(fromBlock, toBlock), blockBatchSize, func(ctx context.Context, blockNumbers []int) error {
+		results, errs := a.analyzeBlocks(ctx, blockNumbers)
+
+		mu.Lock()
+		defer mu.Unlock()
+		for i, b := range blockNumbers {
+			if errs[i] != nil {
+				return fmt.Errorf("block %d: %w", b, errs[i])
+			}
+
+			validatorIndex, ok := proposerOf(b, results[i])
+			if !ok {
+				unattributed++
+				continue
+			}
+			e, ok := entries[validatorIndex]
+			if !ok {
+				e = &models.LeaderboardEntry{ValidatorIndex: validatorIndex}
+				entries[validatorIndex] = e
+			}
+			e.TotalReward += results[i].ValidatorReward
+			e.ProposedBlocks++
+			if results[i].ValidatorReward > 0 {
+				e.MEVBlocks++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing blocks: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LeaderboardResponse{
+		FromBlock:          fromBlock,
+		ToBlock:            toBlock,
+		Validators:         rankValidators(entries, limit),
+		UnattributedBlocks: unattributed,
+		Currency:           a.mevDetector.NativeSymbol,
+		Timestamp:          time.Now(),
+	})
+}
+
+// blockProposers returns a function attributing an analyzed block to its
+// proposer. Beacon duties are authoritative when a beacon node is configured;
+// otherwise blocks are matched to validators by fee recipient, skipping
+// recipients shared by several validators.
+func (a *API) blockProposers(ctx context.Context, fromBlock, toBlock int) (func(blockNumber int, result models.BlockMEVResult) (int, bool), error) {
+	if a.beacon != nil {
+		fromSlot, toSlot, err := a.slotRange(ctx, fromBlock, toBlock)
+		if err != nil {
+			return nil, err
+		}
+		proposers, err := a.beacon.BlockProposers(ctx, fromSlot, toSlot)
+		if err != nil {
+			return nil, err
+		}
+		return func(blockNumber int, _ models.BlockMEVResult) (int, bool) {
+			validatorIndex, ok := proposers[blockNumber]
+			return validatorIndex, ok
+		}, nil
+	}
+
+	validators := make(map[string]int)
+	shared := make(map[string]bool)
+	for validatorIndex, addr := range a.FeeRecipients {
+		addr, ok := models.NormalizeAddress(addr)
+		if !ok {
+			continue
+		}
+		if _, ok := validators[addr]; ok {
+			shared[addr] = true
+		}
+		validators[addr] = validatorIndex
+	}
+	return func(_ int, result models.BlockMEVResult) (int, bool) {
+		validatorIndex, ok := validators[result.FeeRecipient]
+		return validatorIndex, ok && !shared[result.FeeRecipient]
+	}, nil
+}
+
+// rankValidators orders entries by total reward and then by validator index,
+// returning at most limit
+func rankValidators(entries map[int]*models.LeaderboardEntry, limit int) []models.LeaderboardEntry {
+	ranked := make([]models.LeaderboardEntry, 0, len(entries))
+	for _, e := range entries {
+		ranked = append(ranked, *e)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].TotalReward != ranked[j].TotalReward {
+			return ranked[i].TotalReward > ranked[j].TotalReward
+		}
+		return ranked[i].ValidatorIndex < ranked[j].ValidatorIndex
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// liveWriteTimeout bounds how long a live client may take to accept a block
+const liveWriteTimeout = 10 * time.Second
+
+// @Summary Stream new blocks' MEV over WebSocket
+// @Description Upgrades to a WebSocket and sends each new block's analysis as a JSON text message as soon as the block arrives. Heads come from an eth_subscribe newHeads subscription when blockchain.ws_url is configured and from polling otherwise. Blocks are skipped for clients that fall behind.
+// @Tags MEV
+// @Produce json
+// @Success 101 {object} models.BlockMEVResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /ws/blocks [get]
+func (a *API) StreamLiveBlocks(c *gin.Context) {
+	if a.Live == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error: "Live block feed is not enabled",
+		})
+		return
+	}
+
+	// Clients authenticate with API keys rather than cookies, so any origin
+	// is accepted
+	server := websocket.Server{Handler: a.serveLiveBlocks}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serveLiveBlocks sends blocks from the live feed until the client leaves
+func (a *API) serveLiveBlocks(ws *websocket.Conn) {
+	results, unsubscribe := a.Live.Subscribe()
+	defer unsubscribe()
+
+	// Clients send nothing; reading detects when they disconnect
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var discard []byte
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case result := <-results:
+			ws.SetWriteDeadline(time.Now().Add(liveWriteTimeout)) //nolint:errcheck
+			if err := websocket.JSON.Send(ws, result); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRequestIDLength bounds client-supplied request IDs
+const maxRequestIDLength = 64
+
+// RequestLogger assigns each request an ID, echoed in the X-Request-ID
+// header and carried on the request context, and logs the request once it
+// completes. A client-supplied X-Request-ID is reused when reasonably short.
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := c.GetHeader(requestid.Header)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = requestid.New()
+		}
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+
+		c.Next()
+
+		level := slog.LevelInfo
+		if c.Writer.Status() >= 500 {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("request_id", id),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		// The ID is already an attribute, so log without the request context
+		// to keep requestid.Handler from adding it twice
+		logger.LogAttrs(context.Background(), level, "request", attrs...)
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/metrics"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/requestid"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/servertiming"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records request latency per route. Unmatched requests are
+// grouped under a single route label to bound cardinality.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequestDuration.
+			WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// ServerTiming reports time spent in upstream fetches, detection and
+// response serialization via the Server-Timing header. The header is set
+// just before the response starts, so the response is not buffered and
+// total covers the time until then. Serialization is timed from when the
+// handler sets the status to its first write, which covers encoding for
+// c.JSON and friends; streaming handlers report the metrics recorded by
+// their first write.
+func ServerTiming() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, timings := servertiming.NewContext(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		w := &timingWriter{
+			ResponseWriter: c.Writer,
+			timings:        timings,
+			start:          time.Now(),
+		}
+		c.Writer = w
+
+		c.Next()
+
+		// Handlers that write nothing leave gin to write the status
+		// afterwards, still with the header
+		w.setHeader()
+		c.Writer = w.ResponseWriter
+	}
+}
+
+// timingWriter sets the Server-Timing header before the response starts
+type timingWriter struct {
+	gin.ResponseWriter
+	timings     *servertiming.Timings
+	start       time.Time
+	renderStart time.Time
+	headerSet   bool
+}
+
+func (w *timingWriter) WriteHeader(code int) {
+	if w.renderStart.IsZero() {
+		w.renderStart = time.Now()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timingWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timingWriter) Write(data []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timingWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timingWriter) Flush() {
+	w.setHeader()
+	w.ResponseWriter.Flush()
+}
+
+// setHeader records serialize and total and sets the Server-Timing header,
+// once and only while the headers can still change
+func (w *timingWriter) setHeader() {
+	if w.headerSet || w.ResponseWriter.Written() {
+		return
+	}
+	w.headerSet = true
+
+	if !w.renderStart.IsZero() {
+		w.timings.Add("serialize", time.Since(w.renderStart))
+	}
+	w.timings.Add("total", time.Since(w.start))
+	w.Header().Set("Server-Timing", w.timings.Header())
+}
+
+// Tracing starts a server span for each request, continuing any trace
+// context the client sent in the traceparent header
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+		ctx, span := tracing.Start(ctx, c.Request.Method+" "+route, tracing.KindServer)
+		defer span.End()
+		span.SetAttributes(
+			tracing.Attribute{Key: "http.request.method", Value: c.Request.Method},
+			tracing.Attribute{Key: "http.route", Value: route},
+		)
+		if id, ok := requestid.FromContext(ctx); ok {
+			span.SetAttribute("request.id", id)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttribute("http.response.status_code", status)
+		if status >= http.StatusInternalServerError {
+			span.SetError(http.StatusText(status))
+		}
+	}
+}
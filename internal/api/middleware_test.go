@@ -0,0 +1,184 @@
+package api
+
+import (
+	"bufio"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/servertiming"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServerTimingHeader(t *testing.T) {
+	router := gin.New()
+	router.Use(ServerTiming())
+	router.GET("/json", func(c *gin.Context) {
+		servertiming.Add(c.Request.Context(), "fetch", time.Millisecond)
+		servertiming.Add(c.Request.Context(), "detect", time.Millisecond)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.GET("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		path    string
+		status  int
+		metrics []string
+	}{
+		{path: "/json", status: http.StatusOK, metrics: []string{"fetch;dur=", "detect;dur=", "serialize;dur=", "total;dur="}},
+		{path: "/empty", status: http.StatusNoContent, metrics: []string{"total;dur="}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.TrimPrefix(tt.path, "/"), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.status {
+				t.Fatalf("got %d, want %d", w.Code, tt.status)
+			}
+			header := w.Header().Get("Server-Timing")
+			for _, metric := range tt.metrics {
+				if !strings.Contains(header, metric) {
+					t.Errorf("Server-Timing %q is missing %s", header, metric)
+				}
+			}
+		})
+	}
+}
+
+// TestServerTimingStreams checks that the response is not buffered: the
+// client reads the first flushed line while the handler is still running
+func TestServerTimingStreams(t *testing.T) {
+	release := make(chan struct{})
+	router := gin.New()
+	router.Use(ServerTiming())
+	router.GET("/stream", func(c *gin.Context) {
+		c.String(http.StatusOK, "first\n")
+		c.Writer.Flush()
+		<-release
+		c.String(http.StatusOK, "second\n")
+	})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	defer close(release)
+
+	resp, err := http.Get(srv.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if header := resp.Header.Get("Server-Timing"); !strings.Contains(header, "total;dur=") {
+		t.Errorf("Server-Timing %q is missing total", header)
+	}
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "first\n" {
+		t.Errorf("first line = %q, want %q", line, "first\n")
+	}
+}
+
+// spanRecorder is an in-memory tracing exporter
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(span tracing.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+// named returns the recorded spans called name
+func (r *spanRecorder) named(name string) []tracing.SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var spans []tracing.SpanData
+	for _, span := range r.spans {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// TestTracingSpans checks the server span a request records, and that RPC
+// calls made while handling it are recorded as its children
+func TestTracingSpans(t *testing.T) {
+	recorder := &spanRecorder{}
+	tracing.SetExporter(recorder)
+	t.Cleanup(func() { tracing.SetExporter(nil) })
+
+	a, srv := newTestAPI(t)
+	srv.SetLatest(1000)
+	srv.AddBlock(100, &models.Block{Miner: testFeeRecipient})
+
+	router := gin.New()
+	router.Use(Tracing())
+	router.GET("/mev/block/:blockNumber", a.GetBlockMEV)
+	router.GET("/fail", func(c *gin.Context) { c.Status(http.StatusBadGateway) })
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/mev/block/100", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	servers := recorder.named("GET /mev/block/:blockNumber")
+	if len(servers) != 1 {
+		t.Fatalf("got %d server spans, want 1", len(servers))
+	}
+	server := servers[0]
+	if server.Kind != tracing.KindServer || server.StatusCode != tracing.StatusUnset {
+		t.Errorf("got server span kind %d status %d", server.Kind, server.StatusCode)
+	}
+	if got := hex.EncodeToString(server.Context.TraceID[:]); got != traceID {
+		t.Errorf("server span is in trace %s, want the caller's %s", got, traceID)
+	}
+	wantAttrs := map[string]interface{}{
+		"http.request.method":       http.MethodGet,
+		"http.route":                "/mev/block/:blockNumber",
+		"http.response.status_code": http.StatusOK,
+	}
+	for key, want := range wantAttrs {
+		if got, ok := server.Attr(key); !ok || got != want {
+			t.Errorf("server span %s = %v, want %v", key, got, want)
+		}
+	}
+
+	fetches := recorder.named("eth_getBlockByNumber")
+	if len(fetches) == 0 {
+		t.Fatal("no eth_getBlockByNumber span recorded")
+	}
+	for _, fetch := range fetches {
+		if fetch.Kind != tracing.KindClient || fetch.Parent != server.Context.SpanID || fetch.Context.TraceID != server.Context.TraceID {
+			t.Errorf("got fetch span kind %d parent %x, want a client child of %x", fetch.Kind, fetch.Parent, server.Context.SpanID)
+		}
+		if got, ok := fetch.Attr("block.number"); !ok || got != 100 {
+			t.Errorf("fetch span block.number = %v, want 100", got)
+		}
+	}
+
+	// Server errors mark the span as failed
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	fails := recorder.named("GET /fail")
+	if len(fails) != 1 || fails[0].StatusCode != tracing.StatusError || fails[0].StatusMessage != "Bad Gateway" {
+		t.Errorf("got spans %+v, want one failed with Bad Gateway", fails)
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if len(recorder.named("GET unmatched")) != 1 {
+		t.Error("unmatched route not recorded as GET unmatched")
+	}
+}
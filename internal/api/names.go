@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// nameLookupConcurrency bounds concurrent ENS lookups per request
+const nameLookupConcurrency = 8
+
+// resolveNames returns a copy of opportunities with each transaction's
+// sender and recipient annotated with their ENS names. Addresses that fail to
+// resolve are left without a name.
+func (a *API) resolveNames(ctx context.Context, opportunities []models.MEVOpportunity) []models.MEVOpportunity {
+	if a.Names == nil {
+		return opportunities
+	}
+
+	var addrs []string
+	seen := make(map[string]bool)
+	for _, opp := range opportunities {
+		for _, tx := range opp.Transactions {
+			for _, addr := range []string{tx.From, tx.To} {
+				addr = strings.ToLower(addr)
+				if addr != "" && !seen[addr] {
+					seen[addr] = true
+					addrs = append(addrs, addr)
+				}
+			}
+		}
+	}
+
+	resolved := make([]string, len(addrs))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, nameLookupConcurrency)
+	for i, addr := range addrs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resolved[i] = a.Names.Name(ctx, addr)
+		}()
+	}
+	wg.Wait()
+
+	names := make(map[string]string, len(addrs))
+	for i, addr := range addrs {
+		names[addr] = resolved[i]
+	}
+
+	annotated := make([]models.MEVOpportunity, len(opportunities))
+	for i, opp := range opportunities {
+		txs := make([]models.Transaction, len(opp.Transactions))
+		for j, tx := range opp.Transactions {
+			tx.FromName = names[strings.ToLower(tx.From)]
+			tx.ToName = names[strings.ToLower(tx.To)]
+			txs[j] = tx
+		}
+		opp.Transactions = txs
+		annotated[i] = opp
+	}
+	return annotated
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// pagination is a requested page of a list
+type pagination struct {
+	page     int // 1-based
+	pageSize int
+}
+
+// parsePagination reads the page and pageSize query parameters. It returns
+// nil when neither is set, so the whole list should be returned, and writes
+// an error response and returns false when either is invalid.
+func parsePagination(c *gin.Context) (*pagination, bool) {
+	pageStr, pageSizeStr := c.Query("page"), c.Query("pageSize")
+	if pageStr == "" && pageSizeStr == "" {
+		return nil, true
+	}
+
+	p := &pagination{page: 1, pageSize: defaultPageSize}
+	if pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "page must be a positive integer",
+			})
+			return nil, false
+		}
+		p.page = page
+	}
+	if pageSizeStr != "" {
+		pageSize, err := strconv.Atoi(pageSizeStr)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: fmt.Sprintf("pageSize must be between 1 and %d", maxPageSize),
+			})
+			return nil, false
+		}
+		p.pageSize = pageSize
+	}
+	return p, true
+}
+
+// bounds returns the [start, end) indexes of the page within a list of n
+// items, and the number of pages. Pages past the end are empty.
+func (p pagination) bounds(n int) (start, end, totalPages int) {
+	totalPages = (n + p.pageSize - 1) / p.pageSize
+	start = min((p.page-1)*p.pageSize, n)
+	end = min(start+p.pageSize, n)
+	return start, end, totalPages
+}
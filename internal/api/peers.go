@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/beacon"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Compare a validator's MEV against its epoch peers
+// @Description Ranks the MEV of a validator's proposed block against every other proposer in the same epoch
+// @Tags Validator
+// @Accept json
+// @Produce json
+// @Param validatorIndex path int true "Validator index"
+// @Param epoch path int true "Beacon chain epoch"
+// @Success 200 {object} models.EpochPeerComparisonResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/validator/{validatorIndex}/epoch/{epoch}/peers [get]
+func (a *API) GetValidatorEpochPeers(c *gin.Context) {
+	validatorIndex, err := strconv.Atoi(c.Param("validatorIndex"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid validator index",
+		})
+		return
+	}
+
+	epoch, err := strconv.Atoi(c.Param("epoch"))
+	if err != nil || epoch < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid epoch",
+		})
+		return
+	}
+
+	if a.beacon == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error: "Beacon node not configured",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	duties, err := a.beacon.ProposerDuties(ctx, epoch)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get proposer duties: %v", err),
+		})
+		return
+	}
+
+	// Resolve and analyze every proposed block in the epoch
+	proposals := make([]models.PeerProposal, len(duties))
+	err = a.forEachBlock(ctx, 0, len(duties)-1, func(ctx context.Context, i int) error {
+		duty := duties[i]
+		proposals[i] = models.PeerProposal{
+			ValidatorIndex: duty.ValidatorIndex,
+			Slot:           duty.Slot,
+		}
+
+		blockNumber, err := a.beacon.ExecutionBlockNumber(ctx, duty.Slot)
+		if errors.Is(err, beacon.ErrSlotMissed) {
+			proposals[i].Missed = true
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("slot %d: %w", duty.Slot, err)
+		}
+
+		result, err := a.analyzeBlock(ctx, blockNumber)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", blockNumber, err)
+		}
+
+		proposals[i].BlockNumber = blockNumber
+		proposals[i].MEVReward = result.ValidatorReward
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing epoch: %v", err),
+		})
+		return
+	}
+
+	reward, rank, percentile, peerCount, ok := rankPeers(proposals, validatorIndex)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: fmt.Sprintf("Validator %d did not propose a block in epoch %d", validatorIndex, epoch),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.EpochPeerComparisonResponse{
+		ValidatorIndex: validatorIndex,
+		Epoch:          epoch,
+		MEVReward:      reward,
+		Rank:           rank,
+		Percentile:     percentile,
+		PeerCount:      peerCount,
+		Proposals:      proposals,
+		Currency:       a.mevDetector.NativeSymbol,
+		Timestamp:      time.Now(),
+	})
+}
+
+// rankPeers aggregates proposed-block rewards per validator and ranks the
+// target validator against the other proposers. Missed slots are ignored.
+// The percentile is the share of peers whose reward is strictly lower.
+func rankPeers(proposals []models.PeerProposal, validatorIndex int) (reward float64, rank int, percentile float64, peerCount int, ok bool) {
+	totals := make(map[int]float64)
+	for _, p := range proposals {
+		if p.Missed {
+			continue
+		}
+		totals[p.ValidatorIndex] += p.MEVReward
+	}
+
+	reward, ok = totals[validatorIndex]
+	if !ok {
+		return 0, 0, 0, 0, false
+	}
+
+	peers := make([]float64, 0, len(totals)-1)
+	for index, total := range totals {
+		if index != validatorIndex {
+			peers = append(peers, total)
+		}
+	}
+
+	var lower, higher int
+	for _, peer := range peers {
+		switch {
+		case peer < reward:
+			lower++
+		case peer > reward:
+			higher++
+		}
+	}
+
+	percentile = 100
+	if len(peers) > 0 {
+		percentile = float64(lower) / float64(len(peers)) * 100
+	}
+
+	return reward, higher + 1, percentile, len(peers), true
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/testutil"
+)
+
+const testBotAddress = "0x0000000000007f150bd6f54c40a34d7c3d5e9f56" // Flashbots builder, a known bot
+
+// addMEVBlock serves blockNumber with one known-bot transaction paying a
+// priority fee of gwei per gas over 21000 gas, or no transactions when gwei
+// is zero
+func addMEVBlock(srv *testutil.RPCServer, blockNumber, gwei int) {
+	srv.AddBlock(blockNumber, newMEVBlock(srv, blockNumber, gwei))
+}
+
+// newMEVBlock returns the block addMEVBlock serves, serving its receipts
+func newMEVBlock(srv *testutil.RPCServer, blockNumber, gwei int) *models.Block {
+	block := &models.Block{Miner: testFeeRecipient, BaseFeePerGas: "0x0"}
+	if gwei > 0 {
+		price := fmt.Sprintf("0x%x", gwei*1e9)
+		hash := fmt.Sprintf("0x%x", blockNumber)
+		block.Transactions = []models.Transaction{
+			{Hash: hash, From: testBotAddress, To: testFeeRecipient, Value: "0x0", GasPrice: price, Input: "0x"},
+		}
+		srv.AddReceipts(blockNumber, []models.Receipt{
+			{TransactionHash: hash, GasUsed: "0x5208", EffectiveGasPrice: price},
+		})
+	}
+	return block
+}
+
+func TestRankPeers(t *testing.T) {
+	proposals := []models.PeerProposal{
+		{ValidatorIndex: 1, MEVReward: 1},
+		{ValidatorIndex: 2, MEVReward: 4},
+		{ValidatorIndex: 3, MEVReward: 2},
+		{ValidatorIndex: 3, MEVReward: 3}, // Proposes twice
+		{ValidatorIndex: 4, MEVReward: 6},
+		{ValidatorIndex: 5, MEVReward: 0},
+		{ValidatorIndex: 6, Missed: true},
+		{ValidatorIndex: 7, MEVReward: 5}, // Ties with validator 3
+	}
+
+	tests := []struct {
+		name           string
+		validatorIndex int
+		reward         float64
+		rank           int
+		percentile     float64
+		peerCount      int
+	}{
+		{name: "middle", validatorIndex: 3, reward: 5, rank: 2, percentile: 60, peerCount: 5},
+		{name: "top", validatorIndex: 4, reward: 6, rank: 1, percentile: 100, peerCount: 5},
+		{name: "bottom", validatorIndex: 5, reward: 0, rank: 6, percentile: 0, peerCount: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reward, rank, percentile, peerCount, ok := rankPeers(proposals, tt.validatorIndex)
+			if !ok {
+				t.Fatal("validator not found")
+			}
+			if reward != tt.reward || rank != tt.rank || percentile != tt.percentile || peerCount != tt.peerCount {
+				t.Errorf("rankPeers = %v, %d, %v, %d; want %v, %d, %v, %d",
+					reward, rank, percentile, peerCount, tt.reward, tt.rank, tt.percentile, tt.peerCount)
+			}
+		})
+	}
+
+	if _, _, _, _, ok := rankPeers(proposals, 6); ok {
+		t.Error("validator with only a missed slot was ranked")
+	}
+	if _, rank, percentile, peerCount, _ := rankPeers(proposals[:1], 1); rank != 1 || percentile != 100 || peerCount != 0 {
+		t.Errorf("sole proposer ranked %d at %v with %d peers, want 1 at 100 with 0", rank, percentile, peerCount)
+	}
+}
+
+// TestGetValidatorEpochPeers ranks a validator within a mock epoch served
+// by fake beacon and execution nodes
+func TestGetValidatorEpochPeers(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	t.Cleanup(srv.Close)
+	beaconSrv := testutil.NewBeaconServer()
+	t.Cleanup(beaconSrv.Close)
+	a := NewAPI(srv.Detector(), beaconSrv.Client(), nil)
+
+	const epoch = 10
+	slot := epoch * 32
+	proposals := []struct {
+		validatorIndex, blockNumber, gwei int
+	}{
+		{1, 100, 1},
+		{2, 101, 4},
+		{3, 102, 2},
+		{4, 103, 6},
+		{3, 104, 3},
+		{5, 105, 0},
+		{6, -1, 0}, // Missed
+	}
+	for i, p := range proposals {
+		beaconSrv.AddProposal(slot+i, p.validatorIndex, p.blockNumber)
+		if p.blockNumber >= 0 {
+			addMEVBlock(srv, p.blockNumber, p.gwei)
+		}
+	}
+
+	w := serve(a, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/validator/3/epoch/%d/peers", epoch), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+
+	var resp models.EpochPeerComparisonResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	// Validator 3 earned 2+3 gwei per gas against peers at 1, 4, 6 and 0
+	if resp.Rank != 2 || resp.Percentile != 75 || resp.PeerCount != 4 {
+		t.Errorf("rank %d, percentile %v, peers %d; want 2, 75, 4", resp.Rank, resp.Percentile, resp.PeerCount)
+	}
+	if len(resp.Proposals) != len(proposals) || !resp.Proposals[6].Missed {
+		t.Errorf("proposals = %+v, want %d with the last missed", resp.Proposals, len(proposals))
+	}
+
+	w = serve(a, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/validator/9/epoch/%d/peers", epoch), nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("validator outside the epoch got %d, want 404", w.Code)
+	}
+}
+
+func TestGetValidatorEpochPeersWithoutBeacon(t *testing.T) {
+	a, _ := newTestAPI(t)
+	w := serve(a, httptest.NewRequest(http.MethodGet, "/validator/3/epoch/10/peers", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want 503", w.Code)
+	}
+}
+
+func TestGetValidatorEpochPeersConcurrencyLimit(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	t.Cleanup(srv.Close)
+	beaconSrv := testutil.NewBeaconServer()
+	t.Cleanup(beaconSrv.Close)
+	a := NewAPI(srv.Detector(), beaconSrv.Client(), nil)
+	a.MinConcurrency, a.MaxConcurrency = 1, 3
+
+	const epoch = 10
+	for i := range 32 {
+		beaconSrv.AddProposal(epoch*32+i, i+1, 100+i)
+		addMEVBlock(srv, 100+i, i)
+	}
+	srv.SetDelay(5 * time.Millisecond)
+
+	w := serve(a, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/validator/1/epoch/%d/peers", epoch), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	if got := srv.MaxConcurrent(); got > 3 {
+		t.Errorf("served %d requests at once, want at most the configured 3", got)
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get MEV opportunities among pending transactions
+// @Description Runs the detectors over the node's pending block. Opportunities are unconfirmed: their transactions have not been mined and may never be. Each one is removed once a poll no longer finds its transactions pending, because they were mined or dropped. Nothing is returned while polling the node fails.
+// @Tags MEV
+// @Produce json
+// @Success 200 {object} models.PendingMEVResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/mev/pending [get]
+func (a *API) GetPendingMEV(c *gin.Context) {
+	if a.Pending == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error: "Mempool monitoring is not enabled",
+		})
+		return
+	}
+
+	opps, updated := a.Pending.Opportunities()
+	if opps == nil {
+		opps = []models.PendingOpportunity{}
+	}
+
+	resp := models.PendingMEVResponse{
+		Opportunities: opps,
+		Count:         len(opps),
+		Timestamp:     time.Now(),
+	}
+	if !updated.IsZero() {
+		resp.UpdatedAt = &updated
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pprof serves the net/http/pprof handlers for routes mounted at
+// /debug/pprof/*name
+func Pprof(c *gin.Context) {
+	switch strings.TrimPrefix(c.Param("name"), "/") {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		// Serves the index and named profiles such as heap and goroutine
+		pprof.Index(c.Writer, c.Request)
+	}
+}
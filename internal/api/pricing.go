@@ -0,0 +1,26 @@
+package api
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// usdValue converts an amount of the native token to USD when the request
+// asks for currency=usd. Oracle failures are logged and yield nil so the
+// response omits the USD value rather than failing.
+func (a *API) usdValue(c *gin.Context, amount float64) *float64 {
+	if a.Prices == nil || !strings.EqualFold(c.Query("currency"), "usd") {
+		return nil
+	}
+
+	price, err := a.Prices.USDPrice(c.Request.Context(), a.mevDetector.NativeSymbol)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Failed to get price", "symbol", a.mevDetector.NativeSymbol, "error", err)
+		return nil
+	}
+
+	value := amount * price
+	return &value
+}
@@ -0,0 +1,311 @@
+package api
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Protobuf encoding of the gRPC service's messages, by hand from the field
+// numbers in proto/mev/v1/tracker.proto. Zero scalars are omitted, as in
+// proto3, except for fields declared optional.
+
+// protoValue is one decoded field value: varint and fixed-width values in
+// n, length-delimited values in b
+type protoValue struct {
+	n uint64
+	b []byte
+}
+
+// protoFields holds a decoded message's values by field number, in order
+type protoFields map[protowire.Number][]protoValue
+
+// parseProto decodes the top-level fields of a serialized message
+func parseProto(msg []byte) (protoFields, error) {
+	fields := make(protoFields)
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		msg = msg[n:]
+
+		var v protoValue
+		switch typ {
+		case protowire.VarintType:
+			v.n, n = protowire.ConsumeVarint(msg)
+		case protowire.Fixed64Type:
+			v.n, n = protowire.ConsumeFixed64(msg)
+		case protowire.Fixed32Type:
+			var x uint32
+			x, n = protowire.ConsumeFixed32(msg)
+			v.n = uint64(x)
+		case protowire.BytesType:
+			v.b, n = protowire.ConsumeBytes(msg)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, msg)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		msg = msg[n:]
+		fields[num] = append(fields[num], v)
+	}
+	return fields, nil
+}
+
+// last returns the value of a singular field; the last occurrence wins
+func (f protoFields) last(num protowire.Number) (protoValue, bool) {
+	values := f[num]
+	if len(values) == 0 {
+		return protoValue{}, false
+	}
+	return values[len(values)-1], true
+}
+
+func (f protoFields) int(num protowire.Number) (int64, bool) {
+	v, ok := f.last(num)
+	return int64(v.n), ok
+}
+
+func (f protoFields) double(num protowire.Number) (float64, bool) {
+	v, ok := f.last(num)
+	return math.Float64frombits(v.n), ok
+}
+
+func (f protoFields) bool(num protowire.Number) bool {
+	v, _ := f.last(num)
+	return v.n != 0
+}
+
+func (f protoFields) string(num protowire.Number) string {
+	v, _ := f.last(num)
+	return string(v.b)
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendStrings(b []byte, num protowire.Number, ss []string) []byte {
+	for _, s := range ss {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	return b
+}
+
+func appendInt(b []byte, num protowire.Number, v int) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(int64(v)))
+}
+
+func appendBool(b []byte, num protowire.Number, v bool) []byte {
+	if !v {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, 1)
+}
+
+func appendDouble(b []byte, num protowire.Number, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	return appendOptionalDouble(b, num, &v)
+}
+
+// appendOptionalDouble encodes a proto3 optional double, which is sent
+// even when zero; nil is omitted
+func appendOptionalDouble(b []byte, num protowire.Number, v *float64) []byte {
+	if v == nil {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(*v))
+}
+
+// appendPackedDoubles encodes a repeated double, packed as proto3 does
+func appendPackedDoubles(b []byte, num protowire.Number, vs []float64) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, v := range vs {
+		packed = protowire.AppendFixed64(packed, math.Float64bits(v))
+	}
+	return appendMessage(b, num, packed)
+}
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+// appendTimestamp encodes t as a google.protobuf.Timestamp; the zero time
+// is omitted
+func appendTimestamp(b []byte, num protowire.Number, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	var ts []byte
+	ts = appendInt(ts, 1, int(t.Unix()))
+	ts = appendInt(ts, 2, t.Nanosecond())
+	return appendMessage(b, num, ts)
+}
+
+// appendDoubleMap encodes a map<string, double> with keys in order, so
+// equal maps encode alike
+func appendDoubleMap(b []byte, num protowire.Number, m map[string]float64) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var entry []byte
+		entry = appendString(entry, 1, k)
+		entry = appendDouble(entry, 2, m[k])
+		b = appendMessage(b, num, entry)
+	}
+	return b
+}
+
+func encodeTransaction(tx models.Transaction) []byte {
+	var b []byte
+	b = appendString(b, 1, tx.Hash)
+	b = appendString(b, 2, tx.From)
+	b = appendString(b, 3, tx.To)
+	b = appendString(b, 4, tx.FromName)
+	b = appendString(b, 5, tx.ToName)
+	b = appendString(b, 6, tx.ToLabel)
+	b = appendString(b, 7, tx.Value)
+	b = appendString(b, 8, tx.GasPrice)
+	b = appendString(b, 9, tx.GasUsed)
+	b = appendString(b, 10, tx.EffectiveGasPrice)
+	b = appendString(b, 11, tx.Input)
+	b = appendString(b, 12, tx.Method)
+	b = appendString(b, 13, tx.TransactionIndex)
+	return appendString(b, 14, tx.Nonce)
+}
+
+func encodeOpportunity(opp models.MEVOpportunity) []byte {
+	var b []byte
+	b = appendString(b, 1, opp.Type)
+	b = appendDouble(b, 2, opp.Profit)
+	for _, tx := range opp.Transactions {
+		b = appendMessage(b, 3, encodeTransaction(tx))
+	}
+	b = appendInt(b, 4, opp.BlockNumber)
+	b = appendString(b, 5, opp.BaseFeePerGas)
+	b = appendDouble(b, 6, opp.Confidence)
+	return appendStrings(b, 7, opp.Protocols)
+}
+
+func appendOpportunities(b []byte, num protowire.Number, opps []models.MEVOpportunity) []byte {
+	for _, opp := range opps {
+		b = appendMessage(b, num, encodeOpportunity(opp))
+	}
+	return b
+}
+
+func encodeBlockResult(result models.BlockMEVResult) []byte {
+	var b []byte
+	b = appendInt(b, 1, result.BlockNumber)
+	b = appendOpportunities(b, 2, result.Opportunities)
+	b = appendDouble(b, 3, result.ValidatorReward)
+	b = appendInt(b, 4, result.SkippedTransactions)
+	b = appendString(b, 5, result.FeeRecipient)
+	if result.BlockTime != nil {
+		b = appendTimestamp(b, 6, *result.BlockTime)
+	}
+	return appendStrings(b, 7, result.Warnings)
+}
+
+func encodeBlockMEVResponse(resp models.MEVOpportunitiesResponse) []byte {
+	var b []byte
+	b = appendInt(b, 1, resp.BlockNumber)
+	b = appendOpportunities(b, 2, resp.Opportunities)
+	b = appendDouble(b, 3, resp.EstimatedValidatorReward)
+	b = appendDoubleMap(b, 4, resp.RewardByType)
+	b = appendOptionalDouble(b, 5, resp.ValueUSD)
+	b = appendStrings(b, 6, resp.Warnings)
+	b = appendString(b, 7, resp.Currency)
+	if resp.BlockTime != nil {
+		b = appendTimestamp(b, 8, *resp.BlockTime)
+	}
+	return appendTimestamp(b, 9, resp.Timestamp)
+}
+
+func encodeValidatorMEVResponse(resp models.ValidatorMEVResponse) []byte {
+	var b []byte
+	b = appendInt(b, 1, resp.ValidatorIndex)
+	b = appendInt(b, 2, resp.FromBlock)
+	b = appendInt(b, 3, resp.ToBlock)
+	b = appendString(b, 4, resp.FeeRecipient)
+	b = appendDouble(b, 5, resp.TotalMEVReward)
+	b = appendDoubleMap(b, 6, resp.RewardByType)
+	b = appendOptionalDouble(b, 7, resp.ValueUSD)
+	b = appendInt(b, 8, resp.DuplicateTransactions)
+	b = appendInt(b, 9, resp.MEVBlocks)
+	b = appendInt(b, 10, resp.TotalBlocks)
+	b = appendInt(b, 11, resp.AnalyzedBlocks)
+	for _, result := range resp.Blocks {
+		b = appendMessage(b, 12, encodeBlockResult(result))
+	}
+	b = appendInt(b, 13, resp.Page)
+	b = appendInt(b, 14, resp.PageSize)
+	b = appendInt(b, 15, resp.TotalPages)
+	for _, failed := range resp.FailedBlocks {
+		var fb []byte
+		fb = appendInt(fb, 1, failed.BlockNumber)
+		fb = appendString(fb, 2, failed.Error)
+		b = appendMessage(b, 16, fb)
+	}
+	if gp := resp.GasPrices; gp != nil {
+		var pb []byte
+		pb = appendInt(pb, 1, gp.Samples)
+		pb = appendInt(pb, 2, gp.MissingBlocks)
+		pb = appendDouble(pb, 3, gp.P10)
+		pb = appendDouble(pb, 4, gp.P50)
+		pb = appendDouble(pb, 5, gp.P90)
+		pb = appendDouble(pb, 6, gp.P99)
+		b = appendMessage(b, 17, pb)
+	}
+	b = appendString(b, 18, resp.Currency)
+	return appendTimestamp(b, 19, resp.Timestamp)
+}
+
+func encodeSimulationResponse(resp models.SimulationResponse) []byte {
+	var b []byte
+	b = appendInt(b, 1, resp.ValidatorIndex)
+	b = appendInt(b, 2, resp.SimulatedBlockCount)
+	b = appendInt(b, 3, resp.Runs)
+	b = appendDouble(b, 4, resp.TotalReward)
+	b = appendOptionalDouble(b, 5, resp.ValueUSD)
+	b = appendDouble(b, 6, resp.AverageReward)
+	b = appendDouble(b, 7, resp.BlocksWithMEV)
+	b = appendDouble(b, 8, resp.MEVProbability)
+	b = appendDoubleMap(b, 9, resp.Percentiles)
+	b = appendPackedDoubles(b, 10, resp.ConfidenceInterval[:])
+	for _, block := range resp.Blocks {
+		var sb []byte
+		sb = appendInt(sb, 1, block.BlockNumber)
+		sb = appendBool(sb, 2, block.HasMEV)
+		sb = appendDouble(sb, 3, block.EstimatedReward)
+		b = appendMessage(b, 11, sb)
+	}
+	b = appendString(b, 12, resp.Currency)
+	return appendTimestamp(b, 13, resp.Timestamp)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newRateLimitedRouter serves /ping behind l, trusting X-Forwarded-For
+// only from trustedProxies
+func newRateLimitedRouter(t *testing.T, l *RateLimiter, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(l.Middleware())
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+// ping requests /ping from remoteAddr, optionally forwarded for another IP
+func ping(router *gin.Engine, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiterBurstAndRecover(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := NewRateLimiter(2, 3) // 2 per second, bursts of 3
+	l.now = func() time.Time { return now }
+	router := newRateLimitedRouter(t, l, nil)
+
+	for i := range 3 {
+		if w := ping(router, "10.0.0.1:1234", ""); w.Code != http.StatusOK {
+			t.Fatalf("request %d of the burst got %d, want 200", i+1, w.Code)
+		}
+	}
+
+	w := ping(router, "10.0.0.1:1234", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over the burst got %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+
+	// Other clients have their own bucket
+	if w := ping(router, "10.0.0.2:1234", ""); w.Code != http.StatusOK {
+		t.Errorf("another client got %d, want 200", w.Code)
+	}
+
+	// Half a second refills one token, and only one
+	now = now.Add(500 * time.Millisecond)
+	if w := ping(router, "10.0.0.1:1234", ""); w.Code != http.StatusOK {
+		t.Errorf("request after refilling got %d, want 200", w.Code)
+	}
+	if w := ping(router, "10.0.0.1:1234", ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second request after one refill got %d, want 429", w.Code)
+	}
+
+	// A long pause refills to the burst, no further
+	now = now.Add(time.Hour)
+	for i := range 3 {
+		if w := ping(router, "10.0.0.1:1234", ""); w.Code != http.StatusOK {
+			t.Fatalf("request %d after recovering got %d, want 200", i+1, w.Code)
+		}
+	}
+	if w := ping(router, "10.0.0.1:1234", ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("request over the recovered burst got %d, want 429", w.Code)
+	}
+}
+
+func TestRateLimiterSweepsIdleBuckets(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := NewRateLimiter(1, 1)
+	l.now = func() time.Time { return now }
+
+	l.allow("a")
+	now = now.Add(rateLimitSweepInterval)
+	l.allow("b")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.buckets["a"]; ok {
+		t.Error("idle bucket was not evicted")
+	}
+	if _, ok := l.buckets["b"]; !ok {
+		t.Error("active bucket was evicted")
+	}
+}
+
+// TestRateLimiterForwardedFor checks that clients cannot dodge the limit
+// by varying X-Forwarded-For unless the request comes from a trusted proxy
+func TestRateLimiterForwardedFor(t *testing.T) {
+	t.Run("untrusted", func(t *testing.T) {
+		router := newRateLimitedRouter(t, NewRateLimiter(1, 1), nil)
+		if w := ping(router, "10.0.0.1:1234", "192.0.2.1"); w.Code != http.StatusOK {
+			t.Fatalf("first request got %d, want 200", w.Code)
+		}
+		if w := ping(router, "10.0.0.1:1234", "192.0.2.2"); w.Code != http.StatusTooManyRequests {
+			t.Errorf("spoofed X-Forwarded-For got %d, want 429", w.Code)
+		}
+	})
+
+	t.Run("trusted proxy", func(t *testing.T) {
+		router := newRateLimitedRouter(t, NewRateLimiter(1, 1), []string{"10.0.0.0/8"})
+		if w := ping(router, "10.0.0.1:1234", "192.0.2.1"); w.Code != http.StatusOK {
+			t.Fatalf("first client got %d, want 200", w.Code)
+		}
+		if w := ping(router, "10.0.0.1:1234", "192.0.2.2"); w.Code != http.StatusOK {
+			t.Errorf("second client behind the proxy got %d, want 200", w.Code)
+		}
+		if w := ping(router, "10.0.0.1:1234", "192.0.2.1"); w.Code != http.StatusTooManyRequests {
+			t.Errorf("first client again got %d, want 429", w.Code)
+		}
+	})
+}
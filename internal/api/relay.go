@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/relay"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get validator's actual MEV-Boost rewards
+// @Description Sums the value relays actually paid for the blocks a validator proposed, as reported by the relays' delivered-payload data
+// @Tags Validator
+// @Accept json
+// @Produce json
+// @Param validatorIndex path int true "Validator index"
+// @Param fromBlock query int false "Starting block number (default: latest - 100)"
+// @Param toBlock query int false "Ending block number (default: latest)"
+// @Success 200 {object} models.ActualRewardsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/validator/{validatorIndex}/actual-rewards [get]
+func (a *API) GetValidatorActualRewards(c *gin.Context) {
+	validatorIndex, err := strconv.Atoi(c.Param("validatorIndex"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid validator index",
+		})
+		return
+	}
+
+	if a.beacon == nil || a.Relays == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error: "Beacon node and relays must be configured",
+		})
+		return
+	}
+
+	fromBlock, toBlock, ok := a.parseBlockRange(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	fromSlot, toSlot, err := a.slotRange(ctx, fromBlock, toBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to map blocks to slots: %v", err),
+		})
+		return
+	}
+
+	slots, err := a.beacon.ProposerSlots(ctx, validatorIndex, fromSlot, toSlot)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get proposer slots: %v", err),
+		})
+		return
+	}
+
+	var (
+		payloads []models.DeliveredPayload
+		total    float64
+	)
+	for _, slot := range slots {
+		payload, err := a.Relays.GetDeliveredPayload(ctx, slot)
+		if errors.Is(err, relay.ErrNoPayload) {
+			continue // Built locally or missed
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error: fmt.Sprintf("Failed to get delivered payload for slot %d: %v", slot, err),
+			})
+			return
+		}
+
+		value := models.WeiToEth(payload.Value)
+		total += value
+		payloads = append(payloads, models.DeliveredPayload{
+			Slot:          payload.Slot,
+			BlockNumber:   payload.BlockNumber,
+			BlockHash:     payload.BlockHash,
+			Relay:         payload.Relay,
+			BuilderPubkey: payload.BuilderPubkey,
+			FeeRecipient:  payload.ProposerFeeRecipient,
+			Value:         value,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.ActualRewardsResponse{
+		ValidatorIndex: validatorIndex,
+		FromBlock:      fromBlock,
+		ToBlock:        toBlock,
+		FromSlot:       fromSlot,
+		ToSlot:         toSlot,
+		ProposerSlots:  len(slots),
+		RelayBlocks:    len(payloads),
+		TotalReward:    total,
+		Payloads:       payloads,
+		Currency:       a.mevDetector.NativeSymbol,
+		Timestamp:      time.Now(),
+	})
+}
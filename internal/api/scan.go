@@ -0,0 +1,345 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/metrics"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/throttle"
+)
+
+// blockBatchSize is how many blocks a batched scan fetches per request
+const blockBatchSize = 50
+
+// analyzeBlock returns the MEV result for a block, serving it from the store
+// when available and persisting freshly analyzed blocks. Store failures are
+// logged rather than failing the request.
+func (a *API) analyzeBlock(ctx context.Context, blockNumber int) (models.BlockMEVResult, error) {
+	if result, ok := a.storedResult(ctx, blockNumber); ok {
+		return result, nil
+	}
+
+	result, err := a.mevDetector.AnalyzeBlock(ctx, blockNumber)
+	if err != nil {
+		return models.BlockMEVResult{}, err
+	}
+
+	a.saveResult(ctx, result)
+	return result, nil
+}
+
+// analyzeBlocks analyzes blockNumbers like analyzeBlock. The blocks missing
+// from the store are fetched in a single batched request and then analyzed
+// concurrently, each taking a slot from the limiter in ctx while it fetches
+// its receipts. If the batch fails, each block is fetched on its own so one
+// bad block does not fail the others. errs[i] is set for each block that
+// could not be analyzed.
+func (a *API) analyzeBlocks(ctx context.Context, blockNumbers []int) (results []models.BlockMEVResult, errs []error) {
+	results = make([]models.BlockMEVResult, len(blockNumbers))
+	errs = make([]error, len(blockNumbers))
+
+	var missing []int // Indexes into blockNumbers
+	for i, blockNumber := range blockNumbers {
+		if result, ok := a.storedResult(ctx, blockNumber); ok {
+			results[i] = result
+			continue
+		}
+		missing = append(missing, i)
+	}
+	if len(missing) == 0 {
+		return results, errs
+	}
+
+	limiter, ok := throttle.FromContext(ctx)
+	if !ok {
+		limiter = throttle.NewLimiter(a.MinConcurrency, a.MaxConcurrency)
+		ctx = throttle.NewContext(ctx, limiter)
+	}
+
+	toFetch := make([]int, len(missing))
+	for j, i := range missing {
+		toFetch[j] = blockNumbers[i]
+	}
+
+	if err := limiter.Acquire(ctx); err != nil {
+		for _, i := range missing {
+			errs[i] = err
+		}
+		return results, errs
+	}
+	blocks, err := a.mevDetector.GetBlocksData(ctx, toFetch)
+	limiter.Release()
+	if err != nil {
+		if ctx.Err() != nil {
+			for _, i := range missing {
+				errs[i] = fmt.Errorf("failed to get block data: %w", err)
+			}
+			return results, errs
+		}
+		slog.DebugContext(ctx, "Batched block fetch failed, fetching blocks individually", "blocks", len(toFetch), "error", err)
+		blocks = nil
+	}
+
+	a.analyzeEach(ctx, limiter, blocks, blockNumbers, missing, results, errs)
+
+	// Give failed blocks another chance, fetched alone, so a transient
+	// provider error does not fail or leave a gap in the scan
+	for retry := 0; retry < a.BlockRetries && ctx.Err() == nil; retry++ {
+		var failed []int
+		for _, i := range missing {
+			if errs[i] != nil {
+				failed = append(failed, i)
+			}
+		}
+		if len(failed) == 0 {
+			break
+		}
+		slog.DebugContext(ctx, "Retrying failed blocks", "blocks", len(failed), "attempt", retry+1)
+		a.analyzeEach(ctx, limiter, nil, blockNumbers, failed, results, errs)
+	}
+
+	return results, errs
+}
+
+// analyzeEach concurrently analyzes blockNumbers[i] for each of indexes
+// under limiter, into results[i] and errs[i]. blocks, when not nil, holds
+// the fetched block for each of indexes in order.
+func (a *API) analyzeEach(ctx context.Context, limiter *throttle.Limiter, blocks []*models.Block, blockNumbers, indexes []int, results []models.BlockMEVResult, errs []error) {
+	var wg sync.WaitGroup
+	for j, i := range indexes {
+		if err := limiter.Acquire(ctx); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer limiter.Release()
+
+			results[i], errs[i] = a.analyzeFetchedBlock(ctx, blocks, j, blockNumbers[i])
+			if errs[i] == nil {
+				limiter.Success()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// analyzeFetchedBlock analyzes blockNumber using blocks[j] from a batched
+// fetch, or fetching it alone when the batch failed, and persists the result
+func (a *API) analyzeFetchedBlock(ctx context.Context, blocks []*models.Block, j, blockNumber int) (models.BlockMEVResult, error) {
+	var block *models.Block
+	if blocks != nil {
+		block = blocks[j]
+	} else {
+		var err error
+		if block, err = a.mevDetector.GetBlockData(ctx, blockNumber); err != nil {
+			return models.BlockMEVResult{}, fmt.Errorf("failed to get block data: %w", err)
+		}
+	}
+
+	opps, err := a.mevDetector.CheckBlockMEV(ctx, block, blockNumber)
+	if err != nil {
+		return models.BlockMEVResult{}, err
+	}
+
+	result := a.mevDetector.BlockResult(block, blockNumber, opps)
+	a.saveResult(ctx, result)
+	return result, nil
+}
+
+// storedResult returns a previously analyzed block from the store, if any.
+// Stored results lack the opportunities below MinConfidence, so when it is
+// set they cannot answer requests, which show every opportunity.
+func (a *API) storedResult(ctx context.Context, blockNumber int) (models.BlockMEVResult, bool) {
+	if a.store == nil || a.MinConfidence > 0 {
+		return models.BlockMEVResult{}, false
+	}
+
+	result, found, err := a.store.GetBlockResult(ctx, blockNumber)
+	switch {
+	case err != nil:
+		metrics.CacheLookups.WithLabelValues("error").Inc()
+		slog.WarnContext(ctx, "Failed to read block from store", "block", blockNumber, "error", err)
+	case found && result.FeeRecipient == "":
+		// Saved before fee recipients were recorded; analyze it again so the
+		// block can be attributed
+		metrics.CacheLookups.WithLabelValues("miss").Inc()
+	case found && result.DetectorVersion != a.mevDetector.ConfigVersion():
+		// Analyzed with other bots or settings; analyze it again so the
+		// result matches what a fresh request would return
+		metrics.CacheLookups.WithLabelValues("miss").Inc()
+	case found:
+		metrics.CacheLookups.WithLabelValues("hit").Inc()
+		return *result, true
+	default:
+		metrics.CacheLookups.WithLabelValues("miss").Inc()
+	}
+	return models.BlockMEVResult{}, false
+}
+
+// saveResult persists a freshly analyzed block without its opportunities
+// below MinConfidence, logging failures. Blocks that are not yet finalized
+// are left unsaved, since a reorg could replace them and their stored
+// result would then be served stale.
+func (a *API) saveResult(ctx context.Context, result models.BlockMEVResult) {
+	if a.store == nil || !a.isFinalized(ctx, result.BlockNumber) {
+		return
+	}
+	result = a.mevDetector.FilterResultByConfidence(result, a.MinConfidence)
+	if err := a.store.SaveBlockResult(ctx, result); err != nil {
+		slog.WarnContext(ctx, "Failed to save block to store", "block", result.BlockNumber, "error", err)
+	}
+}
+
+// forEachChunk splits blockNumbers into chunks of up to size blocks and calls
+// fn for each chunk concurrently. Chunks take no concurrency slots
+// themselves; fn takes them per provider call from the limiter carried in
+// its ctx, which adapts between MinConcurrency and MaxConcurrency like
+// forEachBlock's. The first error cancels the remaining work and is
+// returned once all chunks have exited.
+func (a *API) forEachChunk(ctx context.Context, blockNumbers []int, size int, fn func(ctx context.Context, blockNumbers []int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	limiter := throttle.NewLimiter(a.MinConcurrency, a.MaxConcurrency)
+	ctx = throttle.NewContext(ctx, limiter)
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for start := 0; start < len(blockNumbers); start += size {
+		chunk := blockNumbers[start:min(start+size, len(blockNumbers))]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(ctx, chunk); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// blockRange returns the block numbers in [fromBlock, toBlock]
+func blockRange(fromBlock, toBlock int) []int {
+	blockNumbers := make([]int, 0, toBlock-fromBlock+1)
+	for b := fromBlock; b <= toBlock; b++ {
+		blockNumbers = append(blockNumbers, b)
+	}
+	return blockNumbers
+}
+
+// forEachBlock calls fn for every block in [fromBlock, toBlock]. Concurrency
+// adapts between MinConcurrency and MaxConcurrency, backing off when the
+// provider reports overload. The first error cancels the remaining work and
+// is returned once all in-flight calls have exited.
+func (a *API) forEachBlock(ctx context.Context, fromBlock, toBlock int, fn func(ctx context.Context, blockNumber int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	limiter := throttle.NewLimiter(a.MinConcurrency, a.MaxConcurrency)
+	ctx = throttle.NewContext(ctx, limiter)
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for blockNumber := fromBlock; blockNumber <= toBlock; blockNumber++ {
+		if err := limiter.Acquire(ctx); err != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(b int) {
+			defer wg.Done()
+			defer limiter.Release()
+
+			if err := fn(ctx, b); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			limiter.Success()
+		}(blockNumber)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// rewardTally accumulates validator rewards across a scan, counting each
+// transaction hash once even if it appears in several analyzed blocks or
+// opportunities
+type rewardTally struct {
+	mu         sync.Mutex
+	detector   *models.MEVDetector
+	seen       map[string]bool
+	total      float64
+	byType     map[string]float64
+	duplicates int
+}
+
+func newRewardTally(detector *models.MEVDetector) *rewardTally {
+	return &rewardTally{
+		detector: detector,
+		seen:     make(map[string]bool),
+		byType:   make(map[string]float64),
+	}
+}
+
+// add counts the reward from result's transactions not already seen in the
+// scan and returns the amount added
+func (t *rewardTally) add(result models.BlockMEVResult) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var fresh []models.MEVOpportunity
+	for _, opp := range result.Opportunities {
+		var txs []models.Transaction
+		for _, tx := range opp.Transactions {
+			hash := strings.ToLower(tx.Hash)
+			if t.seen[hash] {
+				t.duplicates++
+				continue
+			}
+			t.seen[hash] = true
+			txs = append(txs, tx)
+		}
+		if len(txs) > 0 {
+			opp.Transactions = txs
+			fresh = append(fresh, opp)
+		}
+	}
+
+	var reward float64
+	for opp, amount := range t.detector.RewardByType(fresh) {
+		t.byType[opp] += amount
+		reward += amount
+	}
+	t.total += reward
+	return reward
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/testutil"
+)
+
+func TestAnalyzeBlocksBatchesFetches(t *testing.T) {
+	a, srv := newTestAPI(t)
+	blockNumbers := make([]int, 20)
+	for i := range blockNumbers {
+		blockNumbers[i] = 100 + i
+		srv.AddBlock(100+i, &models.Block{Miner: testFeeRecipient})
+	}
+
+	_, errs := a.analyzeBlocks(context.Background(), blockNumbers)
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("block %d: %v", blockNumbers[i], err)
+		}
+	}
+
+	// One batch of headers plus one receipts call per block
+	if got := srv.Requests("eth_getBlockByNumber"); got != len(blockNumbers) {
+		t.Errorf("requested %d blocks, want %d", got, len(blockNumbers))
+	}
+	if got := srv.Requests("eth_getBlockReceipts"); got != len(blockNumbers) {
+		t.Errorf("requested receipts %d times, want %d", got, len(blockNumbers))
+	}
+}
+
+// TestAnalyzeBlocksIsolatesFailedBlock checks that a block missing from a
+// batch fails alone rather than failing the whole chunk
+func TestAnalyzeBlocksIsolatesFailedBlock(t *testing.T) {
+	a, srv := newTestAPI(t)
+	for b := 100; b < 110; b++ {
+		if b != 105 {
+			srv.AddBlock(b, &models.Block{Miner: testFeeRecipient})
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/validator/1/mev-rewards?fromBlock=100&toBlock=109&skipErrors=true&feeRecipient="+testFeeRecipient, nil)
+	w := serve(a, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+
+	var resp models.ValidatorMEVResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.FailedBlocks) != 1 || resp.FailedBlocks[0].BlockNumber != 105 {
+		t.Errorf("failed blocks = %+v, want only block 105", resp.FailedBlocks)
+	}
+	if len(resp.Blocks) != 9 {
+		t.Errorf("got %d analyzed blocks, want 9", len(resp.Blocks))
+	}
+}
+
+func TestAnalyzeBlockSavesOnlyFinalized(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	t.Cleanup(srv.Close)
+	store := testutil.NewMemStore()
+	a := NewAPI(srv.Detector(), nil, store)
+
+	srv.SetLatest(200)
+	srv.AddBlock(100, &models.Block{Miner: testFeeRecipient})
+	srv.AddBlock(190, &models.Block{Miner: testFeeRecipient})
+
+	for _, b := range []int{100, 190} {
+		if _, err := a.analyzeBlock(context.Background(), b); err != nil {
+			t.Fatalf("block %d: %v", b, err)
+		}
+	}
+
+	ctx := context.Background()
+	if _, found, _ := store.GetBlockResult(ctx, 100); !found {
+		t.Error("finalized block 100 was not saved")
+	}
+	if _, found, _ := store.GetBlockResult(ctx, 190); found {
+		t.Error("unfinalized block 190 was saved")
+	}
+}
+
+func TestAnalyzeBlockReanalyzesStaleResult(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	t.Cleanup(srv.Close)
+	store := testutil.NewMemStore()
+	a := NewAPI(srv.Detector(), nil, store)
+
+	srv.SetLatest(200)
+	srv.AddBlock(100, &models.Block{Miner: testFeeRecipient})
+	if _, err := a.analyzeBlock(context.Background(), 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.analyzeBlock(context.Background(), 100); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.Requests("eth_getBlockByNumber"); got != 1 {
+		t.Fatalf("fetched block %d times before the config changed, want 1", got)
+	}
+
+	a.mevDetector.KnownMEVBots.AddBot("0x2222222222222222222222222222222222222222")
+	if _, err := a.analyzeBlock(context.Background(), 100); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.Requests("eth_getBlockByNumber"); got != 2 {
+		t.Errorf("fetched block %d times after the config changed, want 2", got)
+	}
+	stored, _, _ := store.GetBlockResult(context.Background(), 100)
+	if got := stored.DetectorVersion; got != a.mevDetector.ConfigVersion() {
+		t.Errorf("stored version %s, want %s", got, a.mevDetector.ConfigVersion())
+	}
+}
+
+// TestSaveResultPersistsOnlyConfidentOpportunities checks that
+// MinConfidence narrows what is stored but not what is returned
+func TestSaveResultPersistsOnlyConfidentOpportunities(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	t.Cleanup(srv.Close)
+	store := testutil.NewMemStore()
+	a := NewAPI(srv.Detector(), nil, store)
+	a.MinConfidence = 0.5
+
+	srv.SetLatest(200)
+	srv.AddBlock(100, &models.Block{
+		Miner: testFeeRecipient,
+		Transactions: []models.Transaction{
+			{Hash: "0x01", From: "0x0000000000007f150bd6f54c40a34d7c3d5e9f56", To: "0x2222222222222222222222222222222222222222", Value: "0x0", GasPrice: "0x3b9aca00", Input: "0x"},
+			{Hash: "0x02", From: "0x1111111111111111111111111111111111111111", To: "0x2222222222222222222222222222222222222222", Value: "0x3635c9adc5dea00000", GasPrice: "0x3b9aca00", Input: "0x"}, // 1000 ETH
+		},
+	})
+
+	w := serve(a, httptest.NewRequest(http.MethodGet, "/mev/block/100", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+	var resp models.BlockMEVResult
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if got := opportunityTypes(resp.Opportunities); len(got) != 2 {
+		t.Errorf("response opportunities %v, want known_bot and high_value", got)
+	}
+
+	stored, found, _ := store.GetBlockResult(context.Background(), 100)
+	if !found {
+		t.Fatal("block 100 was not saved")
+	}
+	if got := opportunityTypes(stored.Opportunities); len(got) != 1 || got[0] != "known_bot" {
+		t.Errorf("stored opportunities %v, want only known_bot", got)
+	}
+
+	// Stored rows are incomplete, so a repeat request still shows everything
+	w = serve(a, httptest.NewRequest(http.MethodGet, "/mev/block/100", nil))
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if got := opportunityTypes(resp.Opportunities); len(got) != 2 {
+		t.Errorf("repeat response opportunities %v, want known_bot and high_value", got)
+	}
+}
+
+func opportunityTypes(opps []models.MEVOpportunity) []string {
+	types := make([]string, len(opps))
+	for i, opp := range opps {
+		types[i] = opp.Type
+	}
+	return types
+}
+
+// TestGetValidatorMEVRewardsRetriesFailedBlock checks that a block whose
+// analysis fails once is retried within the scan and ends up in the results
+func TestGetValidatorMEVRewardsRetriesFailedBlock(t *testing.T) {
+	tests := []struct {
+		name       string
+		retries    int
+		failures   int
+		wantFailed int
+	}{
+		{name: "fails once", retries: DefaultBlockRetries, failures: 1, wantFailed: 0},
+		{name: "fails on retry too", retries: DefaultBlockRetries, failures: 2, wantFailed: 1},
+		{name: "retries disabled", retries: 0, failures: 1, wantFailed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, srv := newTestAPI(t)
+			a.BlockRetries = tt.retries
+			for b := 100; b < 110; b++ {
+				srv.AddBlock(b, &models.Block{Miner: testFeeRecipient})
+			}
+			srv.FailReceipts(105, tt.failures)
+
+			req := httptest.NewRequest(http.MethodGet, "/validator/1/mev-rewards?fromBlock=100&toBlock=109&skipErrors=true&feeRecipient="+testFeeRecipient, nil)
+			w := serve(a, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+			}
+
+			var resp models.ValidatorMEVResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.FailedBlocks) != tt.wantFailed {
+				t.Errorf("failed blocks = %+v, want %d", resp.FailedBlocks, tt.wantFailed)
+			}
+			if got, want := len(resp.Blocks), 10-tt.wantFailed; got != want {
+				t.Errorf("got %d analyzed blocks, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeBlocksRetriesFailedBlock(t *testing.T) {
+	a, srv := newTestAPI(t)
+	srv.AddBlock(100, &models.Block{Miner: testFeeRecipient})
+	srv.AddBlock(101, &models.Block{Miner: testFeeRecipient})
+	srv.FailReceipts(101, 1)
+
+	results, errs := a.analyzeBlocks(context.Background(), []int{100, 101})
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("block %d: %v", 100+i, err)
+		}
+	}
+	if results[1].BlockNumber != 101 {
+		t.Errorf("result for block 101 has block number %d", results[1].BlockNumber)
+	}
+	if got := srv.Requests("eth_getBlockReceipts"); got != 3 {
+		t.Errorf("requested receipts %d times, want 3", got)
+	}
+}
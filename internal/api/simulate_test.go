@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+func simulate(t *testing.T, a *API, body string) models.SimulationResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/simulate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := serve(a, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body.String())
+	}
+
+	var resp models.SimulationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestSimulateBlocksWithMEVSingleRun(t *testing.T) {
+	a, _ := newTestAPI(t)
+	resp := simulate(t, a, `{"validatorIndex":1,"blockCount":50,"seed":7,"customAvgReward":0.1,"customMEVProbability":0.3}`)
+
+	var withMEV int
+	for _, block := range resp.Blocks {
+		if block.HasMEV {
+			withMEV++
+		}
+	}
+	if resp.BlocksWithMEV != float64(withMEV) {
+		t.Errorf("blocksWithMEV = %v, want %d from the returned blocks", resp.BlocksWithMEV, withMEV)
+	}
+}
+
+// TestSimulateBlocksWithMEVAveragesRuns checks that blocksWithMEV is the mean
+// across runs, like totalReward, rather than the first run's count
+func TestSimulateBlocksWithMEVAveragesRuns(t *testing.T) {
+	a, _ := newTestAPI(t)
+	resp := simulate(t, a, `{"validatorIndex":1,"blockCount":10,"runs":1000,"seed":7,"customAvgReward":0.1,"customMEVProbability":0.3}`)
+
+	if math.Abs(resp.BlocksWithMEV-3) > 0.3 {
+		t.Errorf("blocksWithMEV = %v, want about 3", resp.BlocksWithMEV)
+	}
+	if resp.BlocksWithMEV == math.Trunc(resp.BlocksWithMEV) {
+		t.Errorf("blocksWithMEV = %v is a single run's count", resp.BlocksWithMEV)
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// Reward magnitude distributions supported by the simulation
+const (
+	distributionExponential = "exponential"
+	distributionLogNormal   = "lognormal"
+)
+
+// rewardDistribution parameterizes the per-block MEV reward simulation
+type rewardDistribution struct {
+	kind           string // distributionExponential (default) or distributionLogNormal
+	avgReward      float64
+	mevProbability float64
+	maxReward      float64 // Largest observed reward
+	rewardCap      float64 // Largest sampled reward; +Inf leaves rewards uncapped
+
+	// Log-normal parameters fitted to the logs of the non-zero rewards
+	mu, sigma float64
+}
+
+// summarizeRewards derives a simulation distribution from observed rewards
+func summarizeRewards(rewards []float64) rewardDistribution {
+	var (
+		dist      rewardDistribution
+		total     float64
+		mevBlocks int
+		logSum    float64
+	)
+	if len(rewards) == 0 {
+		return dist
+	}
+
+	for _, reward := range rewards {
+		total += reward
+		if reward > 0 {
+			mevBlocks++
+			logSum += math.Log(reward)
+		}
+		dist.maxReward = max(dist.maxReward, reward)
+	}
+
+	// Allow simulated rewards somewhat beyond the largest one observed
+	dist.rewardCap = dist.maxReward * 2
+	dist.avgReward = total / float64(len(rewards))
+	dist.mevProbability = float64(mevBlocks) / float64(len(rewards))
+
+	if mevBlocks > 0 {
+		dist.mu = logSum / float64(mevBlocks)
+		var variance float64
+		for _, reward := range rewards {
+			if reward > 0 {
+				d := math.Log(reward) - dist.mu
+				variance += d * d
+			}
+		}
+		dist.sigma = math.Sqrt(variance / float64(mevBlocks))
+	}
+	return dist
+}
+
+// customDistribution returns the distribution given explicitly in req, and
+// false when req should be simulated from recent blocks instead
+func customDistribution(req models.SimulationRequest) (rewardDistribution, bool, error) {
+	if req.CustomAvgReward == nil && req.CustomMEVProbability == nil && req.CustomMaxReward == nil {
+		return rewardDistribution{}, false, nil
+	}
+
+	if req.CustomAvgReward == nil || req.CustomMEVProbability == nil {
+		return rewardDistribution{}, false, errors.New("customAvgReward and customMEVProbability must be set together")
+	}
+	if req.Distribution == distributionLogNormal {
+		return rewardDistribution{}, false, errors.New("custom rewards support only the exponential distribution")
+	}
+
+	dist := rewardDistribution{
+		kind:           distributionExponential,
+		avgReward:      *req.CustomAvgReward,
+		mevProbability: *req.CustomMEVProbability,
+		rewardCap:      math.Inf(1),
+	}
+	if req.CustomMaxReward != nil {
+		dist.maxReward = *req.CustomMaxReward
+		dist.rewardCap = dist.maxReward
+	}
+
+	switch {
+	case dist.avgReward < 0 || math.IsNaN(dist.avgReward):
+		return rewardDistribution{}, false, errors.New("customAvgReward must not be negative")
+	case !(dist.mevProbability >= 0 && dist.mevProbability <= 1):
+		return rewardDistribution{}, false, errors.New("customMEVProbability must be between 0 and 1")
+	case dist.rewardCap < 0 || math.IsNaN(dist.rewardCap):
+		return rewardDistribution{}, false, errors.New("customMaxReward must not be negative")
+	}
+	return dist, true, nil
+}
+
+// newRand returns a generator seeded with seed, or randomly seeded when
+// seed is nil
+func newRand(seed *int64) *rand.Rand {
+	if seed == nil {
+		return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())) //nolint:gosec
+	}
+	return rand.New(rand.NewPCG(uint64(*seed), 0)) //nolint:gosec
+}
+
+// simulateBlocks draws count future blocks starting after startBlock
+func simulateBlocks(rng *rand.Rand, startBlock, count int, dist rewardDistribution) (blocks []models.SimulatedBlock, total float64, withMEV int) {
+	for i := 0; i < count; i++ {
+		var reward float64
+		hasMEV := rng.Float64() < dist.mevProbability
+
+		if hasMEV {
+			reward = min(dist.sample(rng), dist.rewardCap)
+			total += reward
+			withMEV++
+		}
+
+		blocks = append(blocks, models.SimulatedBlock{
+			BlockNumber:     startBlock + i + 1,
+			HasMEV:          hasMEV,
+			EstimatedReward: reward,
+		})
+	}
+
+	return blocks, total, withMEV
+}
+
+// sample draws a single MEV reward magnitude
+func (dist rewardDistribution) sample(rng *rand.Rand) float64 {
+	if dist.kind == distributionLogNormal {
+		return math.Exp(dist.mu + dist.sigma*rng.NormFloat64())
+	}
+	// Use exponential distribution for MEV rewards
+	return rng.ExpFloat64() * dist.avgReward
+}
+
+// percentile returns the p-th percentile (0-100) of sorted values using
+// linear interpolation between closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := rank - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statsTopBlocks is how many of the highest-reward blocks stats report
+const statsTopBlocks = 5
+
+// @Summary Get MEV statistics for a block range
+// @Description Summarizes the distribution of per-block estimated validator rewards over a block range
+// @Tags MEV
+// @Accept json
+// @Produce json
+// @Param fromBlock query int false "Starting block number (default: latest - 100)"
+// @Param toBlock query int false "Ending block number (default: latest)"
+// @Param from query string false "Range start as a UTC date, RFC 3339 or Unix seconds; replaces fromBlock and toBlock"
+// @Param to query string false "Range end (exclusive) as a UTC date, RFC 3339 or Unix seconds; required with from"
+// @Success 200 {object} models.MEVStatsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/mev/stats [get]
+func (a *API) GetMEVStats(c *gin.Context) {
+	var (
+		fromBlock, toBlock int
+		ok                 bool
+	)
+	if c.Query("from") != "" || c.Query("to") != "" {
+		fromBlock, toBlock, ok = a.parseTimeRange(c)
+	} else {
+		fromBlock, toBlock, ok = a.parseBlockRange(c)
+	}
+	if !ok {
+		return
+	}
+
+	rewards, err := a.blockRewards(c.Request.Context(), fromBlock, toBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing blocks: %v", err),
+		})
+		return
+	}
+
+	stats := rewardStats(rewards)
+	stats.FromBlock = fromBlock
+	stats.ToBlock = toBlock
+	stats.Currency = a.mevDetector.NativeSymbol
+	stats.Timestamp = time.Now()
+	c.JSON(http.StatusOK, stats)
+}
+
+// blockRewards scans [fromBlock, toBlock] and returns each block's reward in
+// block order
+func (a *API) blockRewards(ctx context.Context, fromBlock, toBlock int) ([]models.BlockReward, error) {
+	rewards := make([]models.BlockReward, toBlock-fromBlock+1)
+	err := a.forEachChunk(ctx, blockRange(fromBlock, toBlock), blockBatchSize, func(ctx context.Context, blockNumbers []int) error {
+		results, errs := a.analyzeBlocks(ctx, blockNumbers)
+		for i, b := range blockNumbers {
+			if errs[i] != nil {
+				return fmt.Errorf("block %d: %w", b, errs[i])
+			}
+			rewards[b-fromBlock] = models.BlockReward{BlockNumber: b, Reward: results[i].ValidatorReward}
+		}
+		return nil
+	})
+	return rewards, err
+}
+
+// parseTimeRange resolves the from and to query parameters to the blocks
+// produced in [from, to)
+func (a *API) parseTimeRange(c *gin.Context) (int, int, bool) {
+	if c.Query("fromBlock") != "" || c.Query("toBlock") != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "from and to cannot be combined with fromBlock and toBlock",
+		})
+		return 0, 0, false
+	}
+
+	from, err := parseTimeParam(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid from parameter",
+		})
+		return 0, 0, false
+	}
+
+	to, err := parseTimeParam(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid to parameter",
+		})
+		return 0, 0, false
+	}
+
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "from must be before to",
+		})
+		return 0, 0, false
+	}
+
+	ctx := c.Request.Context()
+	latestBlock, err := a.getLatestBlockNumber(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get latest block: %v", err),
+		})
+		return 0, 0, false
+	}
+
+	fromBlock, err := a.mevDetector.BlockAtOrAfter(ctx, from, latestBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to resolve from: %v", err),
+		})
+		return 0, 0, false
+	}
+
+	toBlock, err := a.mevDetector.BlockAtOrAfter(ctx, to, latestBlock)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to resolve to: %v", err),
+		})
+		return 0, 0, false
+	}
+	toBlock-- // to is exclusive
+
+	if toBlock < fromBlock {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "No blocks were produced in the requested time range",
+		})
+		return 0, 0, false
+	}
+
+	// Limit the range for performance
+	if toBlock-fromBlock > a.MaxBlockRange {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("Time range too large (max %d blocks)", a.MaxBlockRange),
+		})
+		return 0, 0, false
+	}
+
+	return fromBlock, toBlock, true
+}
+
+// rewardStats computes the distribution of block rewards, ranking the top
+// blocks by reward and then by block number
+func rewardStats(rewards []models.BlockReward) models.MEVStatsResponse {
+	stats := models.MEVStatsResponse{TotalBlocks: len(rewards)}
+	if len(rewards) == 0 {
+		return stats
+	}
+
+	values := make([]float64, len(rewards))
+	var sum float64
+	for i, r := range rewards {
+		values[i] = r.Reward
+		sum += r.Reward
+		if r.Reward > 0 {
+			stats.MEVBlocks++
+		}
+	}
+	sort.Float64s(values)
+
+	stats.Min = values[0]
+	stats.Max = values[len(values)-1]
+	stats.Mean = sum / float64(len(values))
+	stats.Median = percentile(values, 50)
+
+	var sumSquares float64
+	for _, v := range values {
+		sumSquares += (v - stats.Mean) * (v - stats.Mean)
+	}
+	stats.StdDev = math.Sqrt(sumSquares / float64(len(values)))
+
+	ranked := make([]models.BlockReward, len(rewards))
+	copy(ranked, rewards)
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Reward != ranked[j].Reward {
+			return ranked[i].Reward > ranked[j].Reward
+		}
+		return ranked[i].BlockNumber < ranked[j].BlockNumber
+	})
+	stats.TopBlocks = ranked[:min(statsTopBlocks, len(ranked))]
+
+	return stats
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Stream validator's estimated MEV rewards
+// @Description Streams each analyzed block as a Server-Sent "block" event as soon as it completes, followed by a "summary" event with totals or an "error" event
+// @Tags Validator
+// @Produce text/event-stream
+// @Param validatorIndex path int true "Validator index"
+// @Param fromBlock query int false "Starting block number (default: latest - 100)"
+// @Param toBlock query int false "Ending block number (default: latest)"
+// @Param feeRecipient query string false "Validator's fee recipient address when no beacon node is configured (default: from blockchain.fee_recipients)"
+// @Success 200 {object} models.BlockMEVResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/v1/validator/{validatorIndex}/mev-rewards/stream [get]
+func (a *API) StreamValidatorMEVRewards(c *gin.Context) {
+	validatorIndex, err := strconv.Atoi(c.Param("validatorIndex"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid validator index",
+		})
+		return
+	}
+
+	fromBlock, toBlock, ok := a.parseBlockRange(c)
+	if !ok {
+		return
+	}
+
+	blockNumbers, feeRecipient, ok := a.validatorBlocks(c, validatorIndex, fromBlock, toBlock)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	gasPrices := &gasPriceCollector{}
+	results := make(chan models.BlockMEVResult)
+	done := make(chan error, 1)
+	go func() {
+		defer close(results)
+		done <- a.forEachBlock(ctx, 0, len(blockNumbers)-1, func(ctx context.Context, i int) error {
+			b := blockNumbers[i]
+			result, err := a.analyzeBlock(ctx, b)
+			if err != nil {
+				return fmt.Errorf("block %d: %w", b, err)
+			}
+			if feeRecipient != "" && result.FeeRecipient != feeRecipient {
+				return nil // Proposed by another validator
+			}
+
+			gasPrices.add(result)
+			result.Warnings = a.mevDetector.PlausibilityWarnings(result.ValidatorReward)
+
+			select {
+			case results <- result:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	// Drain every result so no worker is left blocked on a send
+	tally := newRewardTally(a.mevDetector)
+	var mevBlocks, analyzedBlocks int
+	for result := range results {
+		tally.add(result)
+		analyzedBlocks++
+		if result.ValidatorReward > 0 {
+			mevBlocks++
+		}
+
+		c.SSEvent("block", result)
+		c.Writer.Flush()
+	}
+
+	if err := <-done; err != nil {
+		c.SSEvent("error", models.ErrorResponse{
+			Error: fmt.Sprintf("Error processing blocks: %v", err),
+		})
+		c.Writer.Flush()
+		return
+	}
+
+	c.SSEvent("summary", models.ValidatorMEVResponse{
+		ValidatorIndex:        validatorIndex,
+		FromBlock:             fromBlock,
+		ToBlock:               toBlock,
+		FeeRecipient:          feeRecipient,
+		TotalMEVReward:        tally.total,
+		RewardByType:          tally.byType,
+		DuplicateTransactions: tally.duplicates,
+		MEVBlocks:             mevBlocks,
+		TotalBlocks:           toBlock - fromBlock + 1,
+		AnalyzedBlocks:        analyzedBlocks,
+		GasPrices:             gasPrices.percentiles(),
+		Currency:              a.mevDetector.NativeSymbol,
+		Timestamp:             time.Now(),
+	})
+	c.Writer.Flush()
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIPage renders Swagger UI for the spec served at /openapi.json.
+// The UI assets are loaded from a CDN rather than bundled.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>MEV Staking Tracker API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
+
+// OpenAPISpec serves the raw OpenAPI specification
+func OpenAPISpec(spec []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", spec)
+	}
+}
+
+// SwaggerUI serves the Swagger UI page for /swagger/*any, redirecting the
+// bare prefix to index.html
+func SwaggerUI(c *gin.Context) {
+	switch strings.TrimPrefix(c.Param("any"), "/") {
+	case "":
+		c.Redirect(http.StatusMovedPermanently, "/swagger/index.html")
+	case "index.html":
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+	default:
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Not found"})
+	}
+}
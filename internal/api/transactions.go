@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get a block's decoded transactions
+// @Description Returns every transaction in a block with its decoded method, value in ETH and the detectors that flagged it
+// @Tags MEV
+// @Produce json
+// @Param blockNumber path string true "Block number: decimal, 0x-prefixed hex, latest, pending or earliest"
+// @Success 200 {object} models.BlockTransactionsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/block/{blockNumber}/transactions [get]
+func (a *API) GetBlockTransactions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), a.RequestTimeout)
+	defer cancel()
+
+	blockNumber, err := a.resolveBlockParam(ctx, c.Param("blockNumber"))
+	if errors.Is(err, errInvalidBlockParam) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid block number",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get latest block: %v", err),
+		})
+		return
+	}
+
+	block, err := a.mevDetector.GetBlockData(ctx, blockNumber)
+	if err != nil {
+		writeProviderError(c, "Failed to get block data", err)
+		return
+	}
+
+	// Detection also fills gas used and effective gas price from receipts
+	opportunities, err := a.mevDetector.CheckBlockMEV(ctx, block, blockNumber)
+	if err != nil {
+		writeProviderError(c, "Failed to analyze block", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.BlockTransactionsResponse{
+		BlockNumber:  blockNumber,
+		Transactions: models.DecodeTransactions(block, opportunities),
+		Currency:     a.mevDetector.NativeSymbol,
+		Timestamp:    time.Now(),
+	})
+}
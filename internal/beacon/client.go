@@ -0,0 +1,262 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/servertiming"
+)
+
+// SlotsPerEpoch is the number of slots in a beacon chain epoch
+const SlotsPerEpoch = 32
+
+// SecondsPerSlot is the beacon chain slot duration
+const SecondsPerSlot = 12
+
+// ErrSlotMissed is returned when no block was proposed for a slot
+var ErrSlotMissed = errors.New("no block proposed for slot")
+
+var errNotFound = errors.New("resource not found")
+
+// ProposerDuty represents a single proposer assignment within an epoch
+type ProposerDuty struct {
+	ValidatorIndex int
+	Slot           int
+}
+
+// Client queries a beacon node's standard REST API
+type Client struct {
+	BaseURL    string
+	HttpClient *http.Client
+
+	genesisMu sync.Mutex
+	genesis   time.Time // Cached once fetched
+}
+
+// NewClient creates a new beacon node client
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		HttpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// ProposerDuties returns the proposer assignments for every slot in an epoch
+func (c *Client) ProposerDuties(ctx context.Context, epoch int) ([]ProposerDuty, error) {
+	var result struct {
+		Data []struct {
+			ValidatorIndex string `json:"validator_index"`
+			Slot           string `json:"slot"`
+		} `json:"data"`
+	}
+
+	path := fmt.Sprintf("/eth/v1/validator/duties/proposer/%d", epoch)
+	if err := c.get(ctx, path, &result); err != nil {
+		return nil, err
+	}
+
+	duties := make([]ProposerDuty, 0, len(result.Data))
+	for _, d := range result.Data {
+		validatorIndex, err := strconv.Atoi(d.ValidatorIndex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validator index %q: %w", d.ValidatorIndex, err)
+		}
+		slot, err := strconv.Atoi(d.Slot)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slot %q: %w", d.Slot, err)
+		}
+		duties = append(duties, ProposerDuty{
+			ValidatorIndex: validatorIndex,
+			Slot:           slot,
+		})
+	}
+
+	return duties, nil
+}
+
+// ProposedBlocks returns the execution block numbers proposed by a validator
+// in slots [fromSlot, toSlot], in ascending order. Slots the validator missed
+// are omitted.
+func (c *Client) ProposedBlocks(ctx context.Context, validatorIndex, fromSlot, toSlot int) ([]int, error) {
+	slots, err := c.ProposerSlots(ctx, validatorIndex, fromSlot, toSlot)
+	if err != nil {
+		return nil, err
+	}
+
+	var blockNumbers []int
+	for _, slot := range slots {
+		blockNumber, err := c.ExecutionBlockNumber(ctx, slot)
+		if errors.Is(err, ErrSlotMissed) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("slot %d: %w", slot, err)
+		}
+		blockNumbers = append(blockNumbers, blockNumber)
+	}
+
+	sort.Ints(blockNumbers)
+	return blockNumbers, nil
+}
+
+// BlockProposers maps the execution block numbers proposed in slots
+// [fromSlot, toSlot] to the proposing validators' indexes. Missed slots are
+// omitted.
+func (c *Client) BlockProposers(ctx context.Context, fromSlot, toSlot int) (map[int]int, error) {
+	proposers := make(map[int]int)
+	for epoch := fromSlot / SlotsPerEpoch; epoch <= toSlot/SlotsPerEpoch; epoch++ {
+		duties, err := c.ProposerDuties(ctx, epoch)
+		if err != nil {
+			return nil, fmt.Errorf("epoch %d: %w", epoch, err)
+		}
+
+		for _, duty := range duties {
+			if duty.Slot < fromSlot || duty.Slot > toSlot {
+				continue
+			}
+			blockNumber, err := c.ExecutionBlockNumber(ctx, duty.Slot)
+			if errors.Is(err, ErrSlotMissed) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("slot %d: %w", duty.Slot, err)
+			}
+			proposers[blockNumber] = duty.ValidatorIndex
+		}
+	}
+
+	return proposers, nil
+}
+
+// ProposerSlots returns the slots in [fromSlot, toSlot] a validator was
+// assigned to propose, in ascending order, including any it missed
+func (c *Client) ProposerSlots(ctx context.Context, validatorIndex, fromSlot, toSlot int) ([]int, error) {
+	var slots []int
+	for epoch := fromSlot / SlotsPerEpoch; epoch <= toSlot/SlotsPerEpoch; epoch++ {
+		duties, err := c.ProposerDuties(ctx, epoch)
+		if err != nil {
+			return nil, fmt.Errorf("epoch %d: %w", epoch, err)
+		}
+
+		for _, duty := range duties {
+			if duty.ValidatorIndex == validatorIndex && duty.Slot >= fromSlot && duty.Slot <= toSlot {
+				slots = append(slots, duty.Slot)
+			}
+		}
+	}
+
+	sort.Ints(slots)
+	return slots, nil
+}
+
+// GenesisTime returns the time of the chain's first slot
+func (c *Client) GenesisTime(ctx context.Context) (time.Time, error) {
+	c.genesisMu.Lock()
+	defer c.genesisMu.Unlock()
+
+	if !c.genesis.IsZero() {
+		return c.genesis, nil
+	}
+
+	var result struct {
+		Data struct {
+			GenesisTime string `json:"genesis_time"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/beacon/genesis", &result); err != nil {
+		return time.Time{}, err
+	}
+
+	seconds, err := strconv.ParseInt(result.Data.GenesisTime, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid genesis time %q: %w", result.Data.GenesisTime, err)
+	}
+
+	c.genesis = time.Unix(seconds, 0).UTC()
+	return c.genesis, nil
+}
+
+// SlotAt returns the slot containing t, or 0 for times before genesis
+func (c *Client) SlotAt(ctx context.Context, t time.Time) (int, error) {
+	genesis, err := c.GenesisTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if t.Before(genesis) {
+		return 0, nil
+	}
+	return int(t.Sub(genesis) / (SecondsPerSlot * time.Second)), nil
+}
+
+// ExecutionBlockNumber returns the execution block number included at a slot.
+// ErrSlotMissed is returned when the slot has no block.
+func (c *Client) ExecutionBlockNumber(ctx context.Context, slot int) (int, error) {
+	var result struct {
+		Data struct {
+			Message struct {
+				Body struct {
+					ExecutionPayload struct {
+						BlockNumber string `json:"block_number"`
+					} `json:"execution_payload"`
+				} `json:"body"`
+			} `json:"message"`
+		} `json:"data"`
+	}
+
+	path := fmt.Sprintf("/eth/v2/beacon/blocks/%d", slot)
+	if err := c.get(ctx, path, &result); err != nil {
+		if errors.Is(err, errNotFound) {
+			return 0, ErrSlotMissed
+		}
+		return 0, err
+	}
+
+	blockNumber, err := strconv.Atoi(result.Data.Message.Body.ExecutionPayload.BlockNumber)
+	if err != nil {
+		return 0, fmt.Errorf("slot %d has no execution payload", slot)
+	}
+
+	return blockNumber, nil
+}
+
+// get performs a GET request against the beacon node and decodes the JSON body
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	defer servertiming.Since(ctx, "fetch", start)
+
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/beacon"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/testutil"
+)
+
+// newBeaconServer serves two epochs of duties: validator 7 proposes slots
+// 1, 33 and 40 but misses 33, and validator 9 proposes slot 2
+func newBeaconServer(t *testing.T) *testutil.BeaconServer {
+	s := testutil.NewBeaconServer()
+	t.Cleanup(s.Close)
+	s.AddProposal(40, 7, 1040)
+	s.AddProposal(1, 7, 1001)
+	s.AddProposal(2, 9, 1002)
+	s.AddProposal(33, 7, -1)
+	return s
+}
+
+func TestProposerDuties(t *testing.T) {
+	c := newBeaconServer(t).Client()
+
+	duties, err := c.ProposerDuties(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("ProposerDuties: %v", err)
+	}
+	want := []beacon.ProposerDuty{{ValidatorIndex: 7, Slot: 1}, {ValidatorIndex: 9, Slot: 2}}
+	if !reflect.DeepEqual(duties, want) {
+		t.Errorf("got %+v, want %+v", duties, want)
+	}
+
+	duties, err = c.ProposerDuties(context.Background(), 5)
+	if err != nil || len(duties) != 0 {
+		t.Errorf("got %+v, %v for an epoch without duties", duties, err)
+	}
+}
+
+func TestProposerSlots(t *testing.T) {
+	c := newBeaconServer(t).Client()
+
+	slots, err := c.ProposerSlots(context.Background(), 7, 0, 63)
+	if err != nil {
+		t.Fatalf("ProposerSlots: %v", err)
+	}
+	if want := []int{1, 33, 40}; !reflect.DeepEqual(slots, want) {
+		t.Errorf("got %v, want %v including the missed slot", slots, want)
+	}
+
+	slots, err = c.ProposerSlots(context.Background(), 7, 2, 39)
+	if err != nil {
+		t.Fatalf("ProposerSlots: %v", err)
+	}
+	if want := []int{33}; !reflect.DeepEqual(slots, want) {
+		t.Errorf("got %v, want %v within the range", slots, want)
+	}
+}
+
+func TestProposedBlocks(t *testing.T) {
+	c := newBeaconServer(t).Client()
+
+	blocks, err := c.ProposedBlocks(context.Background(), 7, 0, 63)
+	if err != nil {
+		t.Fatalf("ProposedBlocks: %v", err)
+	}
+	if want := []int{1001, 1040}; !reflect.DeepEqual(blocks, want) {
+		t.Errorf("got %v, want %v without the missed slot", blocks, want)
+	}
+}
+
+func TestBlockProposers(t *testing.T) {
+	c := newBeaconServer(t).Client()
+
+	proposers, err := c.BlockProposers(context.Background(), 2, 40)
+	if err != nil {
+		t.Fatalf("BlockProposers: %v", err)
+	}
+	if want := map[int]int{1002: 9, 1040: 7}; !reflect.DeepEqual(proposers, want) {
+		t.Errorf("got %v, want %v", proposers, want)
+	}
+}
+
+func TestExecutionBlockNumber(t *testing.T) {
+	c := newBeaconServer(t).Client()
+
+	blockNumber, err := c.ExecutionBlockNumber(context.Background(), 40)
+	if err != nil || blockNumber != 1040 {
+		t.Errorf("got %d, %v; want 1040", blockNumber, err)
+	}
+	if _, err := c.ExecutionBlockNumber(context.Background(), 33); !errors.Is(err, beacon.ErrSlotMissed) {
+		t.Errorf("got %v for a missed slot, want ErrSlotMissed", err)
+	}
+}
+
+func TestGenesisTimeAndSlotAt(t *testing.T) {
+	s := newBeaconServer(t)
+	genesis := time.Unix(1700000000, 0)
+	s.SetGenesis(genesis)
+	c := s.Client()
+
+	for i := 0; i < 2; i++ {
+		got, err := c.GenesisTime(context.Background())
+		if err != nil || !got.Equal(genesis) {
+			t.Errorf("got %v, %v; want %v", got, err, genesis)
+		}
+	}
+	if n := s.Requests("/eth/v1/beacon/genesis"); n != 1 {
+		t.Errorf("fetched genesis %d times, want it cached after once", n)
+	}
+
+	tests := map[time.Duration]int{
+		-time.Hour:       0,
+		0:                0,
+		11 * time.Second: 0,
+		12 * time.Second: 1,
+		time.Hour:        300,
+	}
+	for offset, want := range tests {
+		if got, err := c.SlotAt(context.Background(), genesis.Add(offset)); err != nil || got != want {
+			t.Errorf("SlotAt(genesis%+v) = %d, %v; want %d", offset, got, err, want)
+		}
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		call    func(*beacon.Client) error
+		want    string
+	}{
+		{
+			name:    "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) },
+			call:    func(c *beacon.Client) error { _, err := c.ProposerDuties(context.Background(), 0); return err },
+			want:    "unexpected status code: 503",
+		},
+		{
+			name:    "malformed body",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("{")) },
+			call:    func(c *beacon.Client) error { _, err := c.ProposerDuties(context.Background(), 0); return err },
+			want:    "failed to decode response",
+		},
+		{
+			name: "invalid validator index",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"data": [{"validator_index": "seven", "slot": "1"}]}`))
+			},
+			call: func(c *beacon.Client) error { _, err := c.ProposerDuties(context.Background(), 0); return err },
+			want: `invalid validator index "seven"`,
+		},
+		{
+			name:    "pre-merge block",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{"data": {"message": {"body": {}}}}`)) },
+			call:    func(c *beacon.Client) error { _, err := c.ExecutionBlockNumber(context.Background(), 1); return err },
+			want:    "slot 1 has no execution payload",
+		},
+		{
+			name:    "invalid genesis time",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{"data": {"genesis_time": "soon"}}`)) },
+			call:    func(c *beacon.Client) error { _, err := c.GenesisTime(context.Background()); return err },
+			want:    `invalid genesis time "soon"`,
+		},
+		{
+			name:    "duties fail within a range",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+			call:    func(c *beacon.Client) error { _, err := c.ProposedBlocks(context.Background(), 7, 32, 40); return err },
+			want:    "epoch 1: unexpected status code: 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			err := tt.call(beacon.NewClient(srv.URL + "/"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
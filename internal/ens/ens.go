@@ -0,0 +1,207 @@
+// Package ens reverse-resolves Ethereum addresses to their primary ENS names.
+package ens
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// RegistryAddress is the ENS registry, deployed at the same address on
+// Ethereum mainnet and its testnets
+const RegistryAddress = "0x00000000000c2e074ec69a0dfb2997ba6c7d2e1e"
+
+// DefaultTTL is how long resolved names, including the absence of one, are cached
+const DefaultTTL = time.Hour
+
+// Method selectors called on the registry and resolvers
+const (
+	resolverSelector = "0178b8bf" // resolver(bytes32)
+	nameSelector     = "691f3431" // name(bytes32)
+	addrSelector     = "3b3b57de" // addr(bytes32)
+)
+
+type cachedName struct {
+	name       string
+	resolvedAt time.Time
+}
+
+// Resolver looks up primary ENS names through eth_call. A reverse record is
+// only trusted when the name it claims resolves back to the same address.
+type Resolver struct {
+	Provider models.RPCProvider
+	Registry string
+	TTL      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedName
+}
+
+// NewResolver creates a resolver querying the ENS registry through provider
+func NewResolver(provider models.RPCProvider) *Resolver {
+	return &Resolver{
+		Provider: provider,
+		Registry: RegistryAddress,
+		TTL:      DefaultTTL,
+		cache:    make(map[string]cachedName),
+	}
+}
+
+// Name returns the primary ENS name of addr, or "" when it has none or the
+// lookup fails. Failed lookups are not cached, so they are retried.
+func (r *Resolver) Name(ctx context.Context, addr string) string {
+	addr, ok := models.NormalizeAddress(addr)
+	if !ok {
+		return ""
+	}
+
+	r.mu.Lock()
+	cached, ok := r.cache[addr]
+	r.mu.Unlock()
+	if ok && time.Since(cached.resolvedAt) < r.TTL {
+		return cached.name
+	}
+
+	name, err := r.lookup(ctx, addr)
+	if err != nil {
+		return ""
+	}
+
+	r.mu.Lock()
+	r.cache[addr] = cachedName{name: name, resolvedAt: time.Now()}
+	r.mu.Unlock()
+	return name
+}
+
+// lookup reads addr's reverse record and verifies it with a forward lookup
+func (r *Resolver) lookup(ctx context.Context, addr string) (string, error) {
+	reverseNode := Namehash(strings.TrimPrefix(addr, "0x") + ".addr.reverse")
+	resolver, err := r.resolver(ctx, reverseNode)
+	if err != nil || resolver == "" {
+		return "", err
+	}
+
+	result, err := r.call(ctx, resolver, nameSelector, reverseNode)
+	if err != nil {
+		return "", err
+	}
+	name, err := decodeString(result)
+	if err != nil || name == "" {
+		return "", err
+	}
+
+	forwardNode := Namehash(name)
+	resolver, err = r.resolver(ctx, forwardNode)
+	if err != nil || resolver == "" {
+		return "", err
+	}
+	result, err = r.call(ctx, resolver, addrSelector, forwardNode)
+	if err != nil {
+		return "", err
+	}
+	if forward, err := decodeAddress(result); err != nil || forward != addr {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// resolver returns the resolver contract set for node, or "" if none is
+func (r *Resolver) resolver(ctx context.Context, node [32]byte) (string, error) {
+	result, err := r.call(ctx, r.Registry, resolverSelector, node)
+	if err != nil {
+		return "", err
+	}
+	resolver, err := decodeAddress(result)
+	if err != nil || resolver == zeroAddress {
+		return "", err
+	}
+	return resolver, nil
+}
+
+// call invokes a single-argument view method on contract at the latest block
+func (r *Resolver) call(ctx context.Context, contract, selector string, node [32]byte) ([]byte, error) {
+	params := []interface{}{
+		map[string]string{
+			"to":   contract,
+			"data": "0x" + selector + hex.EncodeToString(node[:]),
+		},
+		"latest",
+	}
+
+	var result string
+	found, err := r.Provider.Call(ctx, "eth_call", params, &result)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("empty eth_call result")
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid eth_call result %q", result)
+	}
+	return data, nil
+}
+
+// Namehash computes the ENS node of a dot-separated name
+func Namehash(name string) [32]byte {
+	var node [32]byte
+	if name == "" {
+		return node
+	}
+
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := keccak256([]byte(labels[i]))
+		copy(node[:], keccak256(node[:], label))
+	}
+	return node
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+const zeroAddress = "0x0000000000000000000000000000000000000000"
+
+// decodeAddress decodes an ABI-encoded address return value
+func decodeAddress(data []byte) (string, error) {
+	if len(data) < 32 {
+		return "", fmt.Errorf("address result too short: %d bytes", len(data))
+	}
+	return "0x" + hex.EncodeToString(data[12:32]), nil
+}
+
+// decodeString decodes an ABI-encoded string return value
+func decodeString(data []byte) (string, error) {
+	if len(data) < 64 {
+		return "", fmt.Errorf("string result too short: %d bytes", len(data))
+	}
+
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsInt64() || offset.Int64() > int64(len(data)-32) {
+		return "", errors.New("string offset out of range")
+	}
+	start := int(offset.Int64())
+
+	length := new(big.Int).SetBytes(data[start : start+32])
+	if !length.IsInt64() || length.Int64() > int64(len(data)-start-32) {
+		return "", errors.New("string length out of range")
+	}
+	return string(data[start+32 : start+32+int(length.Int64())]), nil
+}
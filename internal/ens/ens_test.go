@@ -0,0 +1,206 @@
+package ens
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+const (
+	testAddr     = "0xd8da6bf26964af9d7eed9e10e08a1b5ea4d6fcc3"
+	testResolver = "0x231b0ee14048e9dccd1d247744d114a4eb5e8e63"
+)
+
+// fakeProvider answers eth_call from canned results keyed by contract and
+// calldata; unknown calls return zero, as a contract without the record does
+type fakeProvider struct {
+	mu      sync.Mutex
+	results map[string]string
+	calls   int
+	err     error
+}
+
+func newFakeProvider() *fakeProvider {
+	return &fakeProvider{results: make(map[string]string)}
+}
+
+// set answers calls of selector on contract for node with result
+func (p *fakeProvider) set(contract, selector string, node [32]byte, result []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.results[contract+"/0x"+selector+hex.EncodeToString(node[:])] = "0x" + hex.EncodeToString(result)
+}
+
+func (p *fakeProvider) Call(ctx context.Context, method string, params []interface{}, out interface{}) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.err != nil {
+		return false, p.err
+	}
+	if method != "eth_call" {
+		return false, fmt.Errorf("unexpected method %s", method)
+	}
+
+	call := params[0].(map[string]string)
+	result, ok := p.results[call["to"]+"/"+call["data"]]
+	if !ok {
+		result = "0x" + strings.Repeat("0", 64)
+	}
+	*out.(*string) = result
+	return true, nil
+}
+
+func (p *fakeProvider) GetBlockByNumber(ctx context.Context, blockNumber int, fullTransactions bool) (*models.Block, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *fakeProvider) BlockNumber(ctx context.Context) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (p *fakeProvider) callCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+// addressResult ABI-encodes an address return value
+func addressResult(addr string) []byte {
+	data, _ := hex.DecodeString(strings.Repeat("0", 24) + strings.TrimPrefix(addr, "0x"))
+	return data
+}
+
+// stringResult ABI-encodes a string return value
+func stringResult(s string) []byte {
+	data := make([]byte, 64, 64+(len(s)+31)/32*32)
+	data[31] = 32
+	data[63] = byte(len(s))
+	return append(data, append([]byte(s), make([]byte, (32-len(s)%32)%32)...)...)
+}
+
+// setName registers name as addr's reverse record and points name's
+// forward record at forward
+func setName(p *fakeProvider, addr, name, forward string) {
+	reverseNode := Namehash(strings.TrimPrefix(addr, "0x") + ".addr.reverse")
+	p.set(RegistryAddress, resolverSelector, reverseNode, addressResult(testResolver))
+	p.set(testResolver, nameSelector, reverseNode, stringResult(name))
+
+	forwardNode := Namehash(name)
+	p.set(RegistryAddress, resolverSelector, forwardNode, addressResult(testResolver))
+	p.set(testResolver, addrSelector, forwardNode, addressResult(forward))
+}
+
+func TestNamehash(t *testing.T) {
+	tests := map[string]string{
+		"":        "0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":     "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"foo.eth": "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+		"FOO.eth": "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+	}
+	for name, want := range tests {
+		if got := Namehash(name); hex.EncodeToString(got[:]) != want {
+			t.Errorf("Namehash(%q) = %x, want %s", name, got, want)
+		}
+	}
+}
+
+func TestResolverName(t *testing.T) {
+	p := newFakeProvider()
+	setName(p, testAddr, "vitalik.eth", testAddr)
+	r := NewResolver(p)
+
+	if got := r.Name(context.Background(), "0xD8DA6BF26964AF9D7EED9E10E08A1B5EA4D6FCC3"); got != "vitalik.eth" {
+		t.Errorf("got %q, want vitalik.eth", got)
+	}
+
+	// Cached, including under another spelling of the address
+	calls := p.callCount()
+	if got := r.Name(context.Background(), testAddr); got != "vitalik.eth" || p.callCount() != calls {
+		t.Errorf("got %q after %d more calls, want the cached name", got, p.callCount()-calls)
+	}
+}
+
+func TestResolverNameUnverified(t *testing.T) {
+	p := newFakeProvider()
+	setName(p, testAddr, "vitalik.eth", "0x1111111111111111111111111111111111111111")
+
+	if got := NewResolver(p).Name(context.Background(), testAddr); got != "" {
+		t.Errorf("got %q for a name resolving to another address", got)
+	}
+}
+
+func TestResolverNameWithoutRecord(t *testing.T) {
+	p := newFakeProvider()
+	r := NewResolver(p)
+
+	if got := r.Name(context.Background(), testAddr); got != "" {
+		t.Errorf("got %q for an address without a reverse record", got)
+	}
+	calls := p.callCount()
+	r.Name(context.Background(), testAddr)
+	if p.callCount() != calls {
+		t.Error("absence of a name was not cached")
+	}
+
+	if got := r.Name(context.Background(), "not an address"); got != "" {
+		t.Errorf("got %q for an invalid address", got)
+	}
+}
+
+func TestResolverNameFailure(t *testing.T) {
+	p := newFakeProvider()
+	setName(p, testAddr, "vitalik.eth", testAddr)
+	p.err = errors.New("rpc unavailable")
+	r := NewResolver(p)
+
+	if got := r.Name(context.Background(), testAddr); got != "" {
+		t.Errorf("got %q from a failing provider", got)
+	}
+
+	// Failures are retried rather than cached
+	p.mu.Lock()
+	p.err = nil
+	p.mu.Unlock()
+	if got := r.Name(context.Background(), testAddr); got != "vitalik.eth" {
+		t.Errorf("got %q after the provider recovered, want vitalik.eth", got)
+	}
+}
+
+func TestResolverTTL(t *testing.T) {
+	p := newFakeProvider()
+	r := NewResolver(p)
+	r.TTL = 0
+
+	r.Name(context.Background(), testAddr)
+	setName(p, testAddr, "vitalik.eth", testAddr)
+	if got := r.Name(context.Background(), testAddr); got != "vitalik.eth" {
+		t.Errorf("got %q, want the name looked up again once expired", got)
+	}
+}
+
+func TestDecodeString(t *testing.T) {
+	if got, err := decodeString(stringResult("a-long-name-spanning-more-than-one-word.eth")); err != nil || got != "a-long-name-spanning-more-than-one-word.eth" {
+		t.Errorf("got %q, %v", got, err)
+	}
+
+	tooLong := stringResult("vitalik.eth")
+	tooLong[63] = 200
+	badOffset := stringResult("vitalik.eth")
+	badOffset[31] = 96
+	for name, data := range map[string][]byte{
+		"short":          make([]byte, 40),
+		"length too big": tooLong,
+		"offset too big": badOffset,
+	} {
+		if got, err := decodeString(data); err == nil {
+			t.Errorf("%s: got %q, want an error", name, got)
+		}
+	}
+}
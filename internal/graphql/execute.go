@@ -0,0 +1,208 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// FieldError reports a root field that could not be resolved
+type FieldError struct {
+	Key string // Response key of the field
+	Err error
+}
+
+func (e FieldError) Error() string {
+	return e.Err.Error()
+}
+
+// Object is a JSON object that keeps its fields in selection order
+type Object []ObjectField
+
+// ObjectField is a field of an Object
+type ObjectField struct {
+	Key   string
+	Value interface{}
+}
+
+// MarshalJSON encodes o with its fields in order
+func (o Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Resolver returns the value of a root field as decoded JSON, given its
+// arguments with variables substituted
+type Resolver func(args map[string]interface{}) (interface{}, error)
+
+// Execute runs doc's selection against the root fields in resolvers. Each
+// resolved value is narrowed to the field's selection; fields missing from
+// a value, such as omitted empty fields, resolve to null. A field without a
+// selection returns its whole value. Resolver failures are reported as
+// FieldErrors alongside the other fields' data, with the failed fields set
+// to null. An error is returned instead if the query cannot run at all.
+func Execute(doc *Document, variables map[string]interface{}, resolvers map[string]Resolver) (Object, []FieldError, error) {
+	vars, err := coerceVariables(doc.Variables, variables)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, field := range doc.Selection {
+		if _, ok := resolvers[field.Name]; !ok && field.Name != "__typename" {
+			return nil, nil, fmt.Errorf("cannot query field %q on type %q", field.Name, rootType(doc.Operation))
+		}
+	}
+
+	var errs []FieldError
+	data := Object{}
+	for _, field := range doc.Selection {
+		if field.Name == "__typename" {
+			data = append(data, ObjectField{Key: field.Key(), Value: rootType(doc.Operation)})
+			continue
+		}
+
+		args, err := field.argumentValues(vars)
+		var value interface{}
+		if err == nil {
+			value, err = resolvers[field.Name](args)
+		}
+		if err != nil {
+			errs = append(errs, FieldError{Key: field.Key(), Err: err})
+			data = append(data, ObjectField{Key: field.Key()})
+			continue
+		}
+		data = append(data, ObjectField{Key: field.Key(), Value: project(value, field.Selection)})
+	}
+	return data, errs, nil
+}
+
+func rootType(operation string) string {
+	if operation == "mutation" {
+		return "Mutation"
+	}
+	return "Query"
+}
+
+// project narrows a decoded JSON value to selection, applying it to every
+// element of lists
+func project(value interface{}, selection []Field) interface{} {
+	if len(selection) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			out[i] = project(elem, selection)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(Object, 0, len(selection))
+		for _, field := range selection {
+			out = append(out, ObjectField{Key: field.Key(), Value: project(v[field.Name], field.Selection)})
+		}
+		return out
+	}
+	return value
+}
+
+// coerceVariables applies the operation's defaults to the supplied
+// variables and checks required ones are set
+func coerceVariables(defs []VariableDefinition, supplied map[string]interface{}) (map[string]interface{}, error) {
+	vars := make(map[string]interface{}, len(defs))
+	for _, def := range defs {
+		v, ok := supplied[def.Name]
+		if !ok && def.Default != nil {
+			var err error
+			if v, err = resolveValue(def.Default, nil); err != nil {
+				return nil, err
+			}
+			ok = true
+		}
+		if def.Required && (!ok || v == nil) {
+			return nil, fmt.Errorf("variable $%s of required type %s was not provided", def.Name, def.Type)
+		}
+		if ok {
+			vars[def.Name] = v
+		}
+	}
+	return vars, nil
+}
+
+// argumentValues resolves f's arguments, substituting variables. Arguments
+// set to unprovided variables are left out.
+func (f Field) argumentValues(vars map[string]interface{}) (map[string]interface{}, error) {
+	args := make(map[string]interface{}, len(f.Arguments))
+	for _, arg := range f.Arguments {
+		if name, ok := arg.Value.(Variable); ok {
+			if _, set := vars[string(name)]; !set {
+				continue
+			}
+		}
+		v, err := resolveValue(arg.Value, vars)
+		if err != nil {
+			return nil, err
+		}
+		args[arg.Name] = v
+	}
+	return args, nil
+}
+
+// resolveValue converts a literal to the value JSON decoding would produce,
+// substituting variables
+func resolveValue(v Value, vars map[string]interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case Variable:
+		value, ok := vars[string(v)]
+		if !ok {
+			return nil, fmt.Errorf("variable $%s is not defined", v)
+		}
+		return value, nil
+	case Enum:
+		return string(v), nil
+	case []Value:
+		list := make([]interface{}, len(v))
+		for i, elem := range v {
+			var err error
+			if list[i], err = resolveValue(elem, vars); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case ObjectValue:
+		obj := make(map[string]interface{}, len(v))
+		for _, field := range v {
+			if name, ok := field.Value.(Variable); ok {
+				if _, set := vars[string(name)]; !set {
+					continue
+				}
+			}
+			value, err := resolveValue(field.Value, vars)
+			if err != nil {
+				return nil, err
+			}
+			obj[field.Name] = value
+		}
+		return obj, nil
+	}
+	return v, nil
+}
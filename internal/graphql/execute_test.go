@@ -0,0 +1,152 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decode parses a JSON document as a resolver would return it
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// execute parses and runs query, returning the data encoded as JSON
+func execute(t *testing.T, query string, variables map[string]interface{}, resolvers map[string]Resolver) (string, []FieldError) {
+	t.Helper()
+	doc, err := Parse(query, "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	data, errs, err := Execute(doc, variables, resolvers)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	out, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), errs
+}
+
+func TestExecuteProjectsSelection(t *testing.T) {
+	block := `{
+		"blockNumber": 100,
+		"estimatedValidatorReward": 0.5,
+		"opportunities": [
+			{"type": "sandwich", "profit": 0.1, "transactions": [{"hash": "0x01", "from": "0xa"}]},
+			{"type": "arbitrage", "profit": 0.2, "transactions": []}
+		],
+		"rewardByType": {"sandwich": 0.1}
+	}`
+	resolvers := map[string]Resolver{
+		"blockMEV": func(args map[string]interface{}) (interface{}, error) {
+			return decode(t, block), nil
+		},
+	}
+
+	got, errs := execute(t, `{
+		__typename
+		blockMEV(blockNumber: 100) {
+			reward: estimatedValidatorReward
+			opportunities { type transactions { hash } }
+			rewardByType
+			missing
+		}
+	}`, nil, resolvers)
+	if len(errs) != 0 {
+		t.Fatalf("got errors %v", errs)
+	}
+
+	// Keys follow the selection order, not the value's
+	want := `{"__typename":"Query","blockMEV":{"reward":0.5,"opportunities":[` +
+		`{"type":"sandwich","transactions":[{"hash":"0x01"}]},{"type":"arbitrage","transactions":[]}],` +
+		`"rewardByType":{"sandwich":0.1},"missing":null}}`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestExecuteAliasesAndArguments(t *testing.T) {
+	var calls []map[string]interface{}
+	resolvers := map[string]Resolver{
+		"blockMEV": func(args map[string]interface{}) (interface{}, error) {
+			calls = append(calls, args)
+			return map[string]interface{}{"blockNumber": args["blockNumber"]}, nil
+		},
+	}
+
+	got, _ := execute(t, `query ($b: Int!, $min: Float = 0.5, $unset: String) {
+		first: blockMEV(blockNumber: $b, minConfidence: $min, currency: $unset) { blockNumber }
+		second: blockMEV(blockNumber: 2, input: {n: $b, m: $unset, tags: [A, $b]}) { blockNumber }
+	}`, map[string]interface{}{"b": float64(1)}, resolvers)
+
+	if want := `{"first":{"blockNumber":1},"second":{"blockNumber":2}}`; got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	// Unset variables are left out rather than passed as null
+	want := []map[string]interface{}{
+		{"blockNumber": float64(1), "minConfidence": 0.5},
+		{"blockNumber": int64(2), "input": map[string]interface{}{"n": float64(1), "tags": []interface{}{"A", float64(1)}}},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("got arguments %#v\nwant %#v", calls, want)
+	}
+}
+
+func TestExecuteFieldErrors(t *testing.T) {
+	resolvers := map[string]Resolver{
+		"ok": func(args map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"value": 1.0}, nil
+		},
+		"failing": func(args map[string]interface{}) (interface{}, error) {
+			return nil, errors.New("block not found")
+		},
+	}
+
+	got, errs := execute(t, `{ a: failing { value } ok { value } b: failing }`, nil, resolvers)
+	if want := `{"a":null,"ok":{"value":1},"b":null}`; got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if len(errs) != 2 || errs[0].Key != "a" || errs[0].Error() != "block not found" || errs[1].Key != "b" {
+		t.Errorf("got errors %+v", errs)
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	resolvers := map[string]Resolver{
+		"blockMEV": func(args map[string]interface{}) (interface{}, error) { return nil, nil },
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		wantErr   string
+	}{
+		{"unknown field", `{ blockMEV(blockNumber: 1) unknown }`, nil, `cannot query field "unknown" on type "Query"`},
+		{"missing required variable", `query ($b: Int!) { blockMEV(blockNumber: $b) }`, nil, "variable $b of required type Int! was not provided"},
+		{"null required variable", `query ($b: Int!) { blockMEV(blockNumber: $b) }`, map[string]interface{}{"b": nil}, "was not provided"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.query, "")
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			_, _, err = Execute(doc, tt.variables, resolvers)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,555 @@
+// Package graphql implements the subset of GraphQL needed to query the API's
+// JSON responses: single query operations with fields, aliases, arguments
+// and variables. Fragments, directives and schema introspection are not
+// supported.
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document is a parsed query operation
+type Document struct {
+	Operation string // "query" or "mutation"
+	Name      string
+	Variables []VariableDefinition
+	Selection []Field
+}
+
+// VariableDefinition declares an operation variable
+type VariableDefinition struct {
+	Name     string
+	Type     string // As written, e.g. "Int!"
+	Default  Value  // nil when there is no default
+	Required bool
+}
+
+// Field is a selected field
+type Field struct {
+	Alias     string // Empty when not aliased
+	Name      string
+	Arguments []Argument
+	Selection []Field // Empty for leaf fields
+}
+
+// Key is the name the field's value is returned under
+func (f Field) Key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Argument is a field argument
+type Argument struct {
+	Name  string
+	Value Value
+}
+
+// Value is an argument or default value literal: nil, bool, int64, float64,
+// string, Enum, Variable, []Value or ObjectValue
+type Value interface{}
+
+// Enum is an enum value literal
+type Enum string
+
+// Variable refers to an operation variable by name
+type Variable string
+
+// ObjectValue is an input object literal
+type ObjectValue []Argument
+
+// Parse parses a document holding a single operation, or selects
+// operationName among several
+func Parse(query, operationName string) (*Document, error) {
+	p := &parser{lex: lexer{src: query}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var docs []*Document
+	for p.tok.kind != tokEOF {
+		doc, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	switch {
+	case len(docs) == 0:
+		return nil, fmt.Errorf("document has no operations")
+	case operationName != "":
+		for _, doc := range docs {
+			if doc.Name == operationName {
+				return doc, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown operation %q", operationName)
+	case len(docs) > 1:
+		return nil, fmt.Errorf("operationName is required for documents with several operations")
+	}
+	return docs[0], nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind tokenKind
+	text string // Unquoted for strings
+	pos  int
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+// next scans the next token, skipping whitespace, commas and comments
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\uFEFF"): // Byte order mark
+			l.pos += len("\uFEFF")
+		default:
+			return l.scan()
+		}
+	}
+	return token{kind: tokEOF, pos: l.pos}, nil
+}
+
+func (l *lexer) scan() (token, error) {
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		return token{}, fmt.Errorf("fragments are not supported (at offset %d)", start)
+	case strings.ContainsRune("{}()[]:!$=@|&", rune(c)):
+		l.pos++
+		return token{kind: tokPunct, text: string(c), pos: start}, nil
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokName, text: l.src[start:l.pos], pos: start}, nil
+	case c == '-' || isDigit(c):
+		return l.scanNumber()
+	case c == '"':
+		return l.scanString()
+	}
+	return token{}, fmt.Errorf("unexpected character %q at offset %d", c, start)
+}
+
+func (l *lexer) scanNumber() (token, error) {
+	start := l.pos
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	digits := func() int {
+		n := 0
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return token{}, fmt.Errorf("invalid number at offset %d", start)
+	}
+
+	kind := tokInt
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		l.pos++
+		kind = tokFloat
+		if digits() == 0 {
+			return token{}, fmt.Errorf("invalid number at offset %d", start)
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.pos++
+		kind = tokFloat
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if digits() == 0 {
+			return token{}, fmt.Errorf("invalid number at offset %d", start)
+		}
+	}
+	return token{kind: kind, text: l.src[start:l.pos], pos: start}, nil
+}
+
+func (l *lexer) scanString() (token, error) {
+	start := l.pos
+	if strings.HasPrefix(l.src[l.pos:], `"""`) {
+		return token{}, fmt.Errorf("block strings are not supported (at offset %d)", start)
+	}
+	l.pos++
+
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokString, text: sb.String(), pos: start}, nil
+		case c == '\n' || c == '\r':
+			return token{}, fmt.Errorf("unterminated string at offset %d", start)
+		case c == '\\':
+			if l.pos+1 >= len(l.src) {
+				return token{}, fmt.Errorf("unterminated string at offset %d", start)
+			}
+			esc := l.src[l.pos+1]
+			l.pos += 2
+			switch esc {
+			case '"', '\\', '/':
+				sb.WriteByte(esc)
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.src) {
+					return token{}, fmt.Errorf("invalid unicode escape at offset %d", l.pos-2)
+				}
+				r, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return token{}, fmt.Errorf("invalid unicode escape at offset %d", l.pos-2)
+				}
+				sb.WriteRune(rune(r))
+				l.pos += 4
+			default:
+				return token{}, fmt.Errorf("invalid escape \\%c at offset %d", esc, l.pos-2)
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			sb.WriteRune(r)
+			l.pos += size
+		}
+	}
+	return token{}, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+type parser struct {
+	lex lexer
+	tok token
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// peek reports whether the current token is the punctuator or name s
+func (p *parser) peek(s string) bool {
+	return (p.tok.kind == tokPunct || p.tok.kind == tokName) && p.tok.text == s
+}
+
+func (p *parser) expect(punct string) error {
+	if p.tok.kind != tokPunct || p.tok.text != punct {
+		return p.unexpected(fmt.Sprintf("%q", punct))
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokName {
+		return "", p.unexpected("a name")
+	}
+	name := p.tok.text
+	return name, p.advance()
+}
+
+func (p *parser) unexpected(want string) error {
+	if p.tok.kind == tokEOF {
+		return fmt.Errorf("expected %s, found end of document", want)
+	}
+	return fmt.Errorf("expected %s, found %q at offset %d", want, p.tok.text, p.tok.pos)
+}
+
+func (p *parser) parseOperation() (*Document, error) {
+	doc := &Document{Operation: "query"}
+	if p.peek("{") {
+		var err error
+		doc.Selection, err = p.parseSelectionSet()
+		return doc, err
+	}
+
+	if p.tok.kind != tokName {
+		return nil, p.unexpected("an operation")
+	}
+	switch p.tok.text {
+	case "query", "mutation", "subscription":
+		doc.Operation = p.tok.text
+	case "fragment":
+		return nil, fmt.Errorf("fragments are not supported")
+	default:
+		return nil, p.unexpected("an operation")
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokName {
+		doc.Name = p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("(") {
+		var err error
+		if doc.Variables, err = p.parseVariableDefinitions(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("@") {
+		return nil, fmt.Errorf("directives are not supported")
+	}
+
+	var err error
+	doc.Selection, err = p.parseSelectionSet()
+	return doc, err
+}
+
+func (p *parser) parseVariableDefinitions() ([]VariableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var defs []VariableDefinition
+	for !p.peek(")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+
+		def := VariableDefinition{Name: name, Type: typ, Required: strings.HasSuffix(typ, "!")}
+		if p.peek("=") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if def.Default, err = p.parseValue(true); err != nil {
+				return nil, err
+			}
+		}
+		defs = append(defs, def)
+	}
+	return defs, p.advance()
+}
+
+func (p *parser) parseType() (string, error) {
+	var typ string
+	if p.peek("[") {
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		typ = "[" + inner + "]"
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		typ = name
+	}
+
+	if p.peek("!") {
+		typ += "!"
+		return typ, p.advance()
+	}
+	return typ, nil
+}
+
+func (p *parser) parseSelectionSet() ([]Field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var fields []Field
+	for !p.peek("}") {
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty selection set at offset %d", p.tok.pos)
+	}
+	return fields, p.advance()
+}
+
+func (p *parser) parseField() (Field, error) {
+	var field Field
+	name, err := p.name()
+	if err != nil {
+		return field, err
+	}
+	field.Name = name
+
+	if p.peek(":") {
+		if err := p.advance(); err != nil {
+			return field, err
+		}
+		field.Alias = name
+		if field.Name, err = p.name(); err != nil {
+			return field, err
+		}
+	}
+
+	if p.peek("(") {
+		if err := p.advance(); err != nil {
+			return field, err
+		}
+		for !p.peek(")") {
+			arg, err := p.parseArgument(false)
+			if err != nil {
+				return field, err
+			}
+			field.Arguments = append(field.Arguments, arg)
+		}
+		if err := p.advance(); err != nil {
+			return field, err
+		}
+	}
+	if p.peek("@") {
+		return field, fmt.Errorf("directives are not supported")
+	}
+
+	if p.peek("{") {
+		field.Selection, err = p.parseSelectionSet()
+	}
+	return field, err
+}
+
+func (p *parser) parseArgument(constant bool) (Argument, error) {
+	name, err := p.name()
+	if err != nil {
+		return Argument{}, err
+	}
+	if err := p.expect(":"); err != nil {
+		return Argument{}, err
+	}
+	value, err := p.parseValue(constant)
+	return Argument{Name: name, Value: value}, err
+}
+
+// parseValue parses a value literal; constant values may not refer to
+// variables
+func (p *parser) parseValue(constant bool) (Value, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokInt:
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %s at offset %d", tok.text, tok.pos)
+		}
+		return n, p.advance()
+	case tokFloat:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %s at offset %d", tok.text, tok.pos)
+		}
+		return f, p.advance()
+	case tokString:
+		return tok.text, p.advance()
+	case tokName:
+		var v Value
+		switch tok.text {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			v = Enum(tok.text)
+		}
+		return v, p.advance()
+	}
+
+	switch {
+	case p.peek("$"):
+		if constant {
+			return nil, fmt.Errorf("unexpected variable at offset %d", tok.pos)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		return Variable(name), err
+	case p.peek("["):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		list := []Value{}
+		for !p.peek("]") {
+			v, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, p.advance()
+	case p.peek("{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		obj := ObjectValue{}
+		for !p.peek("}") {
+			arg, err := p.parseArgument(constant)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, arg)
+		}
+		return obj, p.advance()
+	}
+	return nil, p.unexpected("a value")
+}
@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	query := `
+		# Rewards for a validator, with the block it started at
+		query Rewards($index: Int!, $from: Int = 100, $types: [String!]) {
+			validatorRewards(index: $index, fromBlock: $from, skipErrors: true, currency: USD) {
+				total: totalMevReward
+				blocks { blockNumber, opportunities { type } }
+			}
+			simulate(input: {validatorIndex: 1, blockCount: -5, customAvgReward: 0.5e-1, distribution: "log\"normalé", tags: [a, null]}) {
+				runs
+			}
+		}`
+
+	doc, err := Parse(query, "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := &Document{
+		Operation: "query",
+		Name:      "Rewards",
+		Variables: []VariableDefinition{
+			{Name: "index", Type: "Int!", Required: true},
+			{Name: "from", Type: "Int", Default: int64(100)},
+			{Name: "types", Type: "[String!]"},
+		},
+		Selection: []Field{
+			{
+				Name: "validatorRewards",
+				Arguments: []Argument{
+					{Name: "index", Value: Variable("index")},
+					{Name: "fromBlock", Value: Variable("from")},
+					{Name: "skipErrors", Value: true},
+					{Name: "currency", Value: Enum("USD")},
+				},
+				Selection: []Field{
+					{Alias: "total", Name: "totalMevReward"},
+					{Name: "blocks", Selection: []Field{
+						{Name: "blockNumber"},
+						{Name: "opportunities", Selection: []Field{{Name: "type"}}},
+					}},
+				},
+			},
+			{
+				Name: "simulate",
+				Arguments: []Argument{{Name: "input", Value: ObjectValue{
+					{Name: "validatorIndex", Value: int64(1)},
+					{Name: "blockCount", Value: int64(-5)},
+					{Name: "customAvgReward", Value: 0.05},
+					{Name: "distribution", Value: `log"normalé`},
+					{Name: "tags", Value: []Value{Enum("a"), nil}},
+				}}},
+				Selection: []Field{{Name: "runs"}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("got  %#v\nwant %#v", doc, want)
+	}
+}
+
+func TestParseShorthand(t *testing.T) {
+	doc, err := Parse(`{ blockMEV(blockNumber: 1) { blockNumber } }`, "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if doc.Operation != "query" || doc.Name != "" || len(doc.Selection) != 1 || doc.Selection[0].Key() != "blockMEV" {
+		t.Errorf("got %#v", doc)
+	}
+}
+
+func TestParseOperationName(t *testing.T) {
+	const query = `query A { a } query B { b } mutation C { c }`
+
+	for _, name := range []string{"A", "B", "C"} {
+		doc, err := Parse(query, name)
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", name, err)
+		}
+		if doc.Name != name || doc.Selection[0].Name != strings.ToLower(name) {
+			t.Errorf("Parse(%s) selected %s { %s }", name, doc.Name, doc.Selection[0].Name)
+		}
+	}
+	if doc, _ := Parse(query, "C"); doc.Operation != "mutation" {
+		t.Errorf("got operation %s, want mutation", doc.Operation)
+	}
+
+	if _, err := Parse(query, ""); err == nil || !strings.Contains(err.Error(), "operationName is required") {
+		t.Errorf("got %v, want operationName required", err)
+	}
+	if _, err := Parse(query, "D"); err == nil || !strings.Contains(err.Error(), `unknown operation "D"`) {
+		t.Errorf("got %v, want unknown operation", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"empty", "  # nothing\n", "document has no operations"},
+		{"fragment spread", `{ a { ...F } }`, "fragments are not supported"},
+		{"fragment definition", `fragment F on Query { a }`, "fragments are not supported"},
+		{"field directive", `{ a @skip(if: true) }`, "directives are not supported"},
+		{"operation directive", `query Q @live { a }`, "directives are not supported"},
+		{"empty selection", `{ a { } }`, "empty selection set"},
+		{"unterminated selection", `{ a { b }`, "found end of document"},
+		{"unterminated string", `{ a(s: "abc) }`, "unterminated string"},
+		{"string with newline", "{ a(s: \"ab\nc\") }", "unterminated string"},
+		{"block string", `{ a(s: """abc""") }`, "block strings are not supported"},
+		{"bad escape", `{ a(s: "\q") }`, `invalid escape \q`},
+		{"bad unicode escape", `{ a(s: "\u12g4") }`, "invalid unicode escape"},
+		{"bad number", `{ a(n: 1.) }`, "invalid number"},
+		{"integer overflow", `{ a(n: 99999999999999999999) }`, "invalid integer"},
+		{"variable in default", `query ($a: Int = $b) { a }`, "unexpected variable"},
+		{"unexpected character", `{ a; }`, `unexpected character ';'`},
+		{"missing argument value", `{ a(n: ) }`, "expected a value"},
+		{"not an operation", `select { a }`, "expected an operation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
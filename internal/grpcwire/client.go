@@ -0,0 +1,89 @@
+package grpcwire
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// NewH2CClient returns an HTTP client that speaks cleartext HTTP/2, for
+// calling a server's H2C handler without TLS
+func NewH2CClient() *http.Client {
+	return &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+}
+
+// Invoke makes a unary call to fullMethod on the server at baseURL and
+// returns the serialized response. A failed call returns a *Status.
+func Invoke(ctx context.Context, client *http.Client, baseURL, fullMethod string, req []byte) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+fullMethod, bytes.NewReader(frame(req)))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/grpc")
+	httpReq.Header.Set("TE", "trailers")
+	if deadline, ok := ctx.Deadline(); ok {
+		httpReq.Header.Set("Grpc-Timeout", formatTimeout(time.Until(deadline)))
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, Errorf(Unavailable, "%v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, Errorf(Unknown, "unexpected HTTP status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, Errorf(Unavailable, "%v", err)
+	}
+
+	// Trailers arrive after the body; a call failing early may send its
+	// status in the headers instead
+	trailer := resp.Trailer
+	if trailer.Get("Grpc-Status") == "" {
+		trailer = resp.Header
+	}
+	code, err := strconv.Atoi(trailer.Get("Grpc-Status"))
+	if err != nil {
+		return nil, Errorf(Internal, "missing grpc-status")
+	}
+	if Code(code) != OK {
+		msg, _ := url.PathUnescape(trailer.Get("Grpc-Message"))
+		return nil, &Status{Code: Code(code), Message: msg}
+	}
+
+	msg, err := readMessage(bytes.NewReader(body), len(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return msg, nil
+}
+
+// formatTimeout formats d as a grpc-timeout header value, which allows at
+// most 8 digits
+func formatTimeout(d time.Duration) string {
+	if ms := d.Milliseconds(); ms < 1e8 {
+		return strconv.FormatInt(max(ms, 1), 10) + "m"
+	}
+	return strconv.FormatInt(min(int64(d/time.Second), 1e8-1), 10) + "S"
+}
@@ -0,0 +1,168 @@
+// Package grpcwire serves unary gRPC methods over HTTP/2, including
+// cleartext HTTP/2 (h2c), without generated stubs. Handlers receive and
+// return serialized protobuf messages; framing, timeouts and status
+// trailers follow the gRPC over HTTP/2 protocol, so standard gRPC clients
+// can call them.
+package grpcwire
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// DefaultMaxMessageSize bounds request messages, matching grpc-go
+const DefaultMaxMessageSize = 4 << 20
+
+// Handler answers a unary call. req and the returned message are
+// serialized protobuf. Errors that are not a *Status are reported as
+// Internal.
+type Handler func(ctx context.Context, req []byte) ([]byte, error)
+
+// Server routes calls by full method name, e.g. "/pkg.Service/Method"
+type Server struct {
+	MaxMessageSize int // Largest accepted request message; 0 uses DefaultMaxMessageSize
+
+	methods map[string]Handler
+}
+
+// NewServer creates a server with no methods
+func NewServer() *Server {
+	return &Server{methods: make(map[string]Handler)}
+}
+
+// Register serves fullMethod with h
+func (s *Server) Register(fullMethod string, h Handler) {
+	s.methods[fullMethod] = h
+}
+
+// H2C wraps h to also accept cleartext HTTP/2, which gRPC clients use
+// when dialing without TLS
+func H2C(h http.Handler) http.Handler {
+	return h2c.NewHandler(h, &http2.Server{})
+}
+
+// ServeHTTP answers a gRPC call. Protocol errors are reported through
+// the status trailers like handler errors.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ProtoMajor != 2 {
+		http.Error(w, "gRPC requires POST over HTTP/2", http.StatusMethodNotAllowed)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "application/grpc" && !strings.HasPrefix(ct, "application/grpc+proto") {
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+
+	resp, err := s.call(r)
+	w.WriteHeader(http.StatusOK)
+	if err == nil {
+		if _, err := w.Write(frame(resp)); err != nil {
+			return // Client gone; trailers cannot be sent either
+		}
+	}
+
+	st := statusOf(err)
+	w.Header().Set("Grpc-Status", strconv.Itoa(int(st.Code)))
+	if st.Message != "" {
+		w.Header().Set("Grpc-Message", url.PathEscape(st.Message))
+	}
+}
+
+// call reads the request message and runs the method's handler
+func (s *Server) call(r *http.Request) ([]byte, error) {
+	h, ok := s.methods[r.URL.Path]
+	if !ok {
+		return nil, Errorf(Unimplemented, "unknown method %s", r.URL.Path)
+	}
+
+	ctx := r.Context()
+	if timeout := r.Header.Get("Grpc-Timeout"); timeout != "" {
+		d, err := parseTimeout(timeout)
+		if err != nil {
+			return nil, Errorf(InvalidArgument, "%v", err)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	maxSize := s.MaxMessageSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxMessageSize
+	}
+	req, err := readMessage(r.Body, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h(ctx, req)
+	if err != nil && ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, Errorf(DeadlineExceeded, "deadline exceeded")
+		}
+		return nil, Errorf(Canceled, "call cancelled")
+	}
+	return resp, err
+}
+
+// readMessage reads one length-prefixed message of at most maxSize bytes
+func readMessage(r io.Reader, maxSize int) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, Errorf(InvalidArgument, "missing request message")
+	}
+	if header[0] != 0 {
+		return nil, Errorf(Unimplemented, "compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > uint32(maxSize) {
+		return nil, Errorf(ResourceExhausted, "request message of %d bytes exceeds the %d byte limit", size, maxSize)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, Errorf(InvalidArgument, "truncated request message")
+	}
+	return msg, nil
+}
+
+// frame prefixes msg with the uncompressed flag and its length
+func frame(msg []byte) []byte {
+	out := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(out[1:], uint32(len(msg)))
+	return append(out, msg...)
+}
+
+// parseTimeout parses a grpc-timeout header value, e.g. "500m"
+func parseTimeout(s string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	if len(s) < 2 || len(s) > 9 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", s)
+	}
+	unit, ok := units[s[len(s)-1]]
+	n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if !ok || err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}
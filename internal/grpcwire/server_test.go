@@ -0,0 +1,165 @@
+package grpcwire
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const echoMethod = "/test.Echo/Echo"
+
+// newTestServer serves s over in-process h2c
+func newTestServer(t *testing.T, s *Server) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(H2C(s))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestInvoke(t *testing.T) {
+	s := NewServer()
+	s.MaxMessageSize = 16
+	s.Register(echoMethod, func(ctx context.Context, req []byte) ([]byte, error) {
+		switch string(req) {
+		case "missing":
+			return nil, Errorf(NotFound, "no such thing: %s", req)
+		case "broken":
+			return nil, errors.New("disk on fire")
+		}
+		return append([]byte("echo "), req...), nil
+	})
+	srv := newTestServer(t, s)
+
+	tests := []struct {
+		name     string
+		method   string
+		req      string
+		want     string
+		wantCode Code
+		wantMsg  string
+	}{
+		{name: "ok", method: echoMethod, req: "hello", want: "echo hello"},
+		{name: "empty message", method: echoMethod, req: "", want: "echo "},
+		{name: "status error", method: echoMethod, req: "missing", wantCode: NotFound, wantMsg: "no such thing: missing"},
+		{name: "plain error", method: echoMethod, req: "broken", wantCode: Internal, wantMsg: "disk on fire"},
+		{name: "unknown method", method: "/test.Echo/Shout", req: "hello", wantCode: Unimplemented},
+		{name: "oversized message", method: echoMethod, req: "0123456789abcdefg", wantCode: ResourceExhausted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := Invoke(context.Background(), NewH2CClient(), srv.URL, tt.method, []byte(tt.req))
+			if tt.wantCode == OK {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(resp) != tt.want {
+					t.Errorf("response = %q, want %q", resp, tt.want)
+				}
+				return
+			}
+
+			var st *Status
+			if !errors.As(err, &st) {
+				t.Fatalf("got error %v, want a status", err)
+			}
+			if st.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", st.Code, tt.wantCode)
+			}
+			if tt.wantMsg != "" && st.Message != tt.wantMsg {
+				t.Errorf("message = %q, want %q", st.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestInvokePropagatesDeadline(t *testing.T) {
+	s := NewServer()
+	s.Register(echoMethod, func(ctx context.Context, req []byte) ([]byte, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return nil, Errorf(InvalidArgument, "no deadline")
+		}
+		return []byte(time.Until(deadline).Round(time.Second).String()), nil
+	})
+	srv := newTestServer(t, s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	resp, err := Invoke(ctx, NewH2CClient(), srv.URL, echoMethod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp) != "10s" {
+		t.Errorf("server deadline in %s, want 10s", resp)
+	}
+}
+
+func TestServeHTTPDeadlineExceeded(t *testing.T) {
+	s := NewServer()
+	s.Register(echoMethod, func(ctx context.Context, req []byte) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	req := httptest.NewRequest(http.MethodPost, echoMethod, bytes.NewReader(frame(nil)))
+	req.ProtoMajor, req.ProtoMinor = 2, 0
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("Grpc-Timeout", "10m")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+
+	if got := w.Result().Trailer.Get("Grpc-Status"); got != strconv.Itoa(int(DeadlineExceeded)) {
+		t.Errorf("grpc-status = %q, want %d", got, DeadlineExceeded)
+	}
+}
+
+func TestServeHTTPRejectsNonGRPC(t *testing.T) {
+	s := NewServer()
+	s.Register(echoMethod, func(ctx context.Context, req []byte) ([]byte, error) { return req, nil })
+
+	req := httptest.NewRequest(http.MethodPost, echoMethod, strings.NewReader("{}"))
+	req.ProtoMajor, req.ProtoMinor = 2, 0
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("got %d, want 415", w.Code)
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "500m", want: 500 * time.Millisecond},
+		{in: "2S", want: 2 * time.Second},
+		{in: "1H", want: time.Hour},
+		{in: "100n", want: 100},
+		{in: "m", wantErr: true},
+		{in: "10x", wantErr: true},
+		{in: "123456789S", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.in), func(t *testing.T) {
+			got, err := parseTimeout(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTimeout(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseTimeout(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package grpcwire
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code is a gRPC status code
+type Code int
+
+// Status codes used by the server and its handlers
+const (
+	OK                Code = 0
+	Canceled          Code = 1
+	Unknown           Code = 2
+	InvalidArgument   Code = 3
+	DeadlineExceeded  Code = 4
+	NotFound          Code = 5
+	ResourceExhausted Code = 8
+	Unimplemented     Code = 12
+	Internal          Code = 13
+	Unavailable       Code = 14
+)
+
+// Status is a failed call's code and message, sent in its trailers
+type Status struct {
+	Code    Code
+	Message string
+}
+
+// Errorf returns a *Status error with code and a formatted message
+func Errorf(code Code, format string, args ...interface{}) error {
+	return &Status{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Status) Error() string {
+	return fmt.Sprintf("grpc status %d: %s", s.Code, s.Message)
+}
+
+// statusOf converts a handler result to the status sent to the client
+func statusOf(err error) *Status {
+	if err == nil {
+		return &Status{Code: OK}
+	}
+	var st *Status
+	if errors.As(err, &st) {
+		return st
+	}
+	return &Status{Code: Internal, Message: err.Error()}
+}
@@ -0,0 +1,92 @@
+package live
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// subscriberBuffer is how many results a subscriber may fall behind by
+// before further results are dropped for it
+const subscriberBuffer = 16
+
+// Feed analyzes each new head from a source and publishes the result to all
+// subscribers. The source is only followed while there are subscribers.
+type Feed struct {
+	detector *models.MEVDetector
+	source   HeadSource
+
+	mu          sync.Mutex
+	subscribers map[chan models.BlockMEVResult]struct{}
+	cancel      context.CancelFunc
+}
+
+// NewFeed creates a feed analyzing heads from source with detector
+func NewFeed(detector *models.MEVDetector, source HeadSource) *Feed {
+	return &Feed{
+		detector:    detector,
+		source:      source,
+		subscribers: make(map[chan models.BlockMEVResult]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving each newly analyzed block and a
+// function that ends the subscription. Results are dropped rather than
+// delaying other subscribers when the channel is full.
+func (f *Feed) Subscribe() (<-chan models.BlockMEVResult, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan models.BlockMEVResult, subscriberBuffer)
+	f.subscribers[ch] = struct{}{}
+	if f.cancel == nil {
+		var ctx context.Context
+		ctx, f.cancel = context.WithCancel(context.Background())
+		go f.follow(ctx)
+	}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() { f.unsubscribe(ch) })
+	}
+}
+
+func (f *Feed) unsubscribe(ch chan models.BlockMEVResult) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.subscribers, ch)
+	if len(f.subscribers) == 0 && f.cancel != nil {
+		f.cancel()
+		f.cancel = nil
+	}
+}
+
+// follow analyzes heads until ctx is cancelled
+func (f *Feed) follow(ctx context.Context) {
+	f.source.Heads(ctx, func(blockNumber int) { //nolint:errcheck // only ends with ctx
+		result, err := f.detector.AnalyzeBlock(ctx, blockNumber)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.WarnContext(ctx, "Failed to analyze new block", "block", blockNumber, "error", err)
+			}
+			return
+		}
+		f.publish(result)
+	})
+}
+
+func (f *Feed) publish(result models.BlockMEVResult) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for ch := range f.subscribers {
+		select {
+		case ch <- result:
+		default:
+			slog.Warn("Dropping block for slow live subscriber", "block", result.BlockNumber)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package live
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/testutil"
+)
+
+const testMiner = "0x388c818ca8b9251b393131c08a736a67ccb19297"
+
+// fakeSource reports heads sent on its channel and signals when it is
+// followed and when it stops
+type fakeSource struct {
+	heads   chan int
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{
+		heads:   make(chan int),
+		started: make(chan struct{}, 4),
+		stopped: make(chan struct{}, 4),
+	}
+}
+
+func (s *fakeSource) Heads(ctx context.Context, fn func(blockNumber int)) error {
+	s.started <- struct{}{}
+	defer func() { s.stopped <- struct{}{} }()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case b := <-s.heads:
+			fn(b)
+		}
+	}
+}
+
+// wait waits for a signal on ch
+func wait(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the source to be %s", what)
+	}
+}
+
+// receive waits for the next result on ch
+func receive(t *testing.T, ch <-chan models.BlockMEVResult) models.BlockMEVResult {
+	t.Helper()
+	select {
+	case result := <-ch:
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a result")
+		return models.BlockMEVResult{}
+	}
+}
+
+func TestFeed(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	defer srv.Close()
+	srv.AddBlock(100, &models.Block{Miner: testMiner})
+	srv.AddBlock(101, &models.Block{Miner: testMiner})
+
+	source := newFakeSource()
+	f := NewFeed(srv.Detector(), source)
+
+	a, unsubscribeA := f.Subscribe()
+	b, unsubscribeB := f.Subscribe()
+	wait(t, source.started, "followed")
+
+	source.heads <- 100
+	if got := receive(t, a); got.BlockNumber != 100 {
+		t.Errorf("subscriber a got block %d, want 100", got.BlockNumber)
+	}
+	if got := receive(t, b); got.BlockNumber != 100 {
+		t.Errorf("subscriber b got block %d, want 100", got.BlockNumber)
+	}
+
+	// Blocks that fail to analyze are skipped
+	source.heads <- 999
+	source.heads <- 101
+	if got := receive(t, a); got.BlockNumber != 101 {
+		t.Errorf("got block %d, want 101 after the failed block", got.BlockNumber)
+	}
+	receive(t, b)
+
+	unsubscribeA()
+	unsubscribeA() // Safe to call twice
+	select {
+	case <-source.stopped:
+		t.Fatal("source stopped with a subscriber left")
+	default:
+	}
+
+	source.heads <- 100
+	if got := receive(t, b); got.BlockNumber != 100 {
+		t.Errorf("remaining subscriber got block %d, want 100", got.BlockNumber)
+	}
+
+	// The last subscriber leaving stops the source, and a new one restarts it
+	unsubscribeB()
+	wait(t, source.stopped, "stopped")
+	_, unsubscribe := f.Subscribe()
+	defer unsubscribe()
+	wait(t, source.started, "followed again")
+}
+
+func TestFeedSlowSubscriber(t *testing.T) {
+	source := newFakeSource()
+	f := NewFeed(nil, source)
+
+	slow, unsubscribeSlow := f.Subscribe()
+	defer unsubscribeSlow()
+	fast, unsubscribeFast := f.Subscribe()
+	defer unsubscribeFast()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < subscriberBuffer+5; i++ {
+			f.publish(models.BlockMEVResult{BlockNumber: i})
+			<-fast
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a full subscriber blocked publishing")
+	}
+
+	if len(slow) != subscriberBuffer {
+		t.Errorf("slow subscriber has %d results, want a full buffer of %d", len(slow), subscriberBuffer)
+	}
+	if got := <-slow; got.BlockNumber != 0 {
+		t.Errorf("slow subscriber's first result is block %d, want the oldest kept", got.BlockNumber)
+	}
+}
@@ -0,0 +1,215 @@
+// Package live follows the chain head and publishes each new block's MEV
+// analysis to subscribers as it arrives. It also watches pending
+// transactions for unconfirmed opportunities.
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// HeadSource reports new chain heads
+type HeadSource interface {
+	// Heads calls fn with each new head block number until ctx is done.
+	// Calls are sequential.
+	Heads(ctx context.Context, fn func(blockNumber int)) error
+}
+
+// Polling defaults
+const (
+	DefaultPollInterval = 12 * time.Second
+	maxCatchUpBlocks    = 16 // Heads reported at most per poll; older missed blocks are skipped
+)
+
+// Poller finds new heads by polling the latest block number. It is the
+// fallback when no WebSocket endpoint is configured.
+type Poller struct {
+	provider models.RPCProvider
+	Interval time.Duration
+}
+
+// NewPoller creates a poller reading the head from provider
+func NewPoller(provider models.RPCProvider) *Poller {
+	return &Poller{provider: provider, Interval: DefaultPollInterval}
+}
+
+// Heads reports the current head and then every block after it, polling
+// each Interval. Failed polls are logged and retried on the next tick.
+func (p *Poller) Heads(ctx context.Context, fn func(blockNumber int)) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	last := -1
+	for {
+		latest, err := p.provider.BlockNumber(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			slog.WarnContext(ctx, "Failed to poll for new blocks", "error", err)
+		case err == nil && latest > last:
+			from := max(last+1, latest-maxCatchUpBlocks+1)
+			if last < 0 {
+				from = latest
+			}
+			for b := from; b <= latest; b++ {
+				fn(b)
+			}
+			last = latest
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconnection backoff defaults for dropped subscriptions
+const (
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = 30 * time.Second
+	dialTimeout       = 10 * time.Second
+)
+
+// Subscriber follows heads through an eth_subscribe newHeads subscription on
+// a WebSocket JSON-RPC endpoint, resubscribing with exponential backoff when
+// the connection drops
+type Subscriber struct {
+	URL        string
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// NewSubscriber creates a subscriber for the WebSocket endpoint at url
+func NewSubscriber(url string) *Subscriber {
+	return &Subscriber{URL: url, MinBackoff: DefaultMinBackoff, MaxBackoff: DefaultMaxBackoff}
+}
+
+// Heads reports every head announced by the endpoint until ctx is done
+func (s *Subscriber) Heads(ctx context.Context, fn func(blockNumber int)) error {
+	backoff := s.MinBackoff
+	for {
+		subscribed, err := s.subscribe(ctx, fn)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if subscribed {
+			backoff = s.MinBackoff
+		}
+		slog.WarnContext(ctx, "Head subscription dropped", "error", err, "retryIn", backoff)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, s.MaxBackoff)
+	}
+}
+
+// subscribe opens one subscription and reads heads from it until it fails,
+// reporting whether the subscription was established
+func (s *Subscriber) subscribe(ctx context.Context, fn func(blockNumber int)) (bool, error) {
+	config, err := websocket.NewConfig(s.URL, originFor(s.URL))
+	if err != nil {
+		return false, fmt.Errorf("invalid WebSocket URL: %w", err)
+	}
+	config.Dialer = &net.Dialer{Timeout: dialTimeout}
+
+	conn, err := config.DialContext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	// Unblock reads when ctx is done
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	request := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_subscribe",
+		"params":  []string{"newHeads"},
+	}
+	if err := websocket.JSON.Send(conn, request); err != nil {
+		return false, fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	var subscription string
+	for {
+		var msg subscriptionMessage
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			return subscription != "", err
+		}
+
+		switch {
+		case subscription == "" && msg.ID != nil:
+			if len(msg.Error) > 0 && string(msg.Error) != "null" {
+				return false, fmt.Errorf("subscription rejected: %s", msg.Error)
+			}
+			if err := json.Unmarshal(msg.Result, &subscription); err != nil || subscription == "" {
+				return false, fmt.Errorf("unexpected subscription result: %s", msg.Result)
+			}
+		case msg.Method == "eth_subscription" && msg.Params.Subscription == subscription && subscription != "":
+			blockNumber, ok := parseQuantity(msg.Params.Result.Number)
+			if !ok {
+				slog.WarnContext(ctx, "Ignoring head with invalid number", "number", msg.Params.Result.Number)
+				continue
+			}
+			fn(blockNumber)
+		}
+	}
+}
+
+// subscriptionMessage is a response or notification on a subscription
+// connection
+type subscriptionMessage struct {
+	ID     *json.RawMessage `json:"id"`
+	Result json.RawMessage  `json:"result"`
+	Error  json.RawMessage  `json:"error"`
+	Method string           `json:"method"`
+	Params struct {
+		Subscription string `json:"subscription"`
+		Result       struct {
+			Number string `json:"number"`
+		} `json:"result"`
+	} `json:"params"`
+}
+
+// originFor returns the Origin sent when dialing rawURL. Nodes do not
+// require one, but the handshake does.
+func originFor(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "http://localhost"
+	}
+	scheme := "http"
+	if u.Scheme == "wss" {
+		scheme = "https"
+	}
+	return scheme + "://" + u.Host
+}
+
+// parseQuantity parses a hex JSON-RPC quantity such as a block number
+func parseQuantity(s string) (int, bool) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, false
+	}
+	n, ok := new(big.Int).SetString(s[2:], 16)
+	if !ok || !n.IsInt64() {
+		return 0, false
+	}
+	return int(n.Int64()), true
+}
@@ -0,0 +1,231 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/testutil"
+)
+
+// collectHeads runs source in the background, returning a channel of the
+// heads it reports and a function that stops it and returns its error
+func collectHeads(source HeadSource) (<-chan int, func() error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	heads := make(chan int, 64)
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Heads(ctx, func(blockNumber int) { heads <- blockNumber })
+	}()
+	return heads, func() error {
+		cancel()
+		return <-done
+	}
+}
+
+// nextHeads waits for the next n heads
+func nextHeads(t *testing.T, heads <-chan int, n int) []int {
+	t.Helper()
+	got := make([]int, 0, n)
+	for len(got) < n {
+		select {
+		case b := <-heads:
+			got = append(got, b)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got heads %v, timed out waiting for %d", got, n)
+		}
+	}
+	return got
+}
+
+// span returns the block numbers from through to
+func span(from, to int) []int {
+	var blocks []int
+	for b := from; b <= to; b++ {
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+func TestPollerHeads(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	defer srv.Close()
+	srv.SetLatest(100)
+	srv.SetError("eth_blockNumber", -32603, "internal error")
+
+	p := NewPoller(srv.Provider())
+	p.Interval = time.Millisecond
+	heads, stop := collectHeads(p)
+
+	// Failed polls are retried
+	for srv.Requests("eth_blockNumber") < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	srv.SetError("eth_blockNumber", 0, "")
+
+	// The first poll reports only the current head
+	if got := nextHeads(t, heads, 1); got[0] != 100 {
+		t.Errorf("got first head %d, want 100", got[0])
+	}
+
+	srv.SetLatest(103)
+	if got := nextHeads(t, heads, 3); !reflect.DeepEqual(got, span(101, 103)) {
+		t.Errorf("got %v, want each block after the last head", got)
+	}
+
+	// Long gaps catch up on the most recent blocks only
+	srv.SetLatest(150)
+	if got := nextHeads(t, heads, maxCatchUpBlocks); !reflect.DeepEqual(got, span(150-maxCatchUpBlocks+1, 150)) {
+		t.Errorf("got %v, want the last %d blocks", got, maxCatchUpBlocks)
+	}
+
+	if err := stop(); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	select {
+	case b := <-heads:
+		t.Errorf("got head %d without a new block", b)
+	default:
+	}
+}
+
+// wsNode is a WebSocket JSON-RPC endpoint running serve for each connection
+type wsNode struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	conns int
+}
+
+func newWSNode(t *testing.T, serve func(conn *websocket.Conn, n int)) *wsNode {
+	node := &wsNode{}
+	node.Server = httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		node.mu.Lock()
+		node.conns++
+		n := node.conns
+		node.mu.Unlock()
+
+		var req struct {
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+		}
+		if err := websocket.JSON.Receive(conn, &req); err != nil || req.Method != "eth_subscribe" || !reflect.DeepEqual(req.Params, []string{"newHeads"}) {
+			return
+		}
+		serve(conn, n)
+	}))
+	t.Cleanup(node.Close)
+	return node
+}
+
+func (n *wsNode) connections() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.conns
+}
+
+func (n *wsNode) url() string {
+	return "ws" + n.URL[len("http"):]
+}
+
+// notify sends a newHeads notification for subscription
+func notify(conn *websocket.Conn, subscription, number string) {
+	websocket.Message.Send(conn, fmt.Sprintf(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":%q,"result":{"number":%q}}}`, subscription, number)) //nolint:errcheck
+}
+
+func TestSubscriberHeads(t *testing.T) {
+	node := newWSNode(t, func(conn *websocket.Conn, n int) {
+		websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":1,"result":"0xsub"}`) //nolint:errcheck
+		if n == 1 {
+			notify(conn, "0xother", "0x1")
+			notify(conn, "0xsub", "latest")
+			notify(conn, "0xsub", "0x10")
+			notify(conn, "0xsub", "0x11")
+			return // Drops the connection
+		}
+		notify(conn, "0xsub", "0x12")
+		var discard string
+		websocket.Message.Receive(conn, &discard) //nolint:errcheck // Until the client closes
+	})
+
+	s := NewSubscriber(node.url())
+	s.MinBackoff = time.Millisecond
+	heads, stop := collectHeads(s)
+
+	if got := nextHeads(t, heads, 3); !reflect.DeepEqual(got, []int{0x10, 0x11, 0x12}) {
+		t.Errorf("got %v, want heads from both subscriptions", got)
+	}
+	if n := node.connections(); n != 2 {
+		t.Errorf("connected %d times, want a reconnect after the drop", n)
+	}
+	if err := stop(); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestSubscriberRejected(t *testing.T) {
+	node := newWSNode(t, func(conn *websocket.Conn, n int) {
+		websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"notifications not supported"}}`) //nolint:errcheck
+		notify(conn, "", "0x10")
+	})
+
+	s := NewSubscriber(node.url())
+	s.MinBackoff = time.Millisecond
+	s.MaxBackoff = 2 * time.Millisecond
+	heads, stop := collectHeads(s)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for node.connections() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("rejected subscription was not retried")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	stop() //nolint:errcheck
+	select {
+	case b := <-heads:
+		t.Errorf("got head %d from a rejected subscription", b)
+	default:
+	}
+}
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int
+		wantOK bool
+	}{
+		{"0x0", 0, true},
+		{"0x12a05f200", 5000000000, true},
+		{"0xABC", 0xabc, true},
+		{"12", 0, false},
+		{"0x", 0, false},
+		{"0xzz", 0, false},
+		{"0x10000000000000000", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseQuantity(tt.in); got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseQuantity(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestOriginFor(t *testing.T) {
+	tests := map[string]string{
+		"ws://localhost:8546":                    "http://localhost:8546",
+		"wss://eth-mainnet.g.alchemy.com/v2/key": "https://eth-mainnet.g.alchemy.com",
+		"://bad":                                 "http://localhost",
+	}
+	for rawURL, want := range tests {
+		if got := originFor(rawURL); got != want {
+			t.Errorf("originFor(%q) = %q, want %q", rawURL, got, want)
+		}
+	}
+}
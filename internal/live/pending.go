@@ -0,0 +1,168 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// Defaults for the pending transaction monitor
+const (
+	DefaultPendingInterval = 2 * time.Second
+	DefaultPendingMaxAge   = time.Minute
+)
+
+// PendingMonitor runs the detectors over the node's pending block. An
+// opportunity is kept while its transactions stay pending and expires once
+// a poll no longer finds it, because they were mined or dropped.
+type PendingMonitor struct {
+	detector *models.MEVDetector
+
+	Interval time.Duration // How often to fetch the pending block
+	MaxAge   time.Duration // Opportunities are withheld when the last successful poll is older
+
+	mu            sync.Mutex
+	opportunities map[string]models.PendingOpportunity // By opportunityKey
+	updated       time.Time
+	cancel        context.CancelFunc
+	done          chan struct{}
+}
+
+// NewPendingMonitor creates a monitor detecting opportunities with detector
+func NewPendingMonitor(detector *models.MEVDetector) *PendingMonitor {
+	return &PendingMonitor{
+		detector:      detector,
+		Interval:      DefaultPendingInterval,
+		MaxAge:        DefaultPendingMaxAge,
+		opportunities: make(map[string]models.PendingOpportunity),
+	}
+}
+
+// Start begins polling in the background until ctx is cancelled or Stop is
+// called
+func (m *PendingMonitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
+		return errors.New("pending monitor already started")
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+	go m.run(ctx, m.done)
+	return nil
+}
+
+// Stop cancels polling and waits for the current poll to finish
+func (m *PendingMonitor) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// run polls every Interval, closing done on exit
+func (m *PendingMonitor) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Poll(ctx); err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "Failed to poll pending transactions", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches the pending block once and replaces the tracked
+// opportunities with those detected in it
+func (m *PendingMonitor) Poll(ctx context.Context) error {
+	var block models.Block
+	found, err := m.detector.Provider.Call(ctx, "eth_getBlockByNumber", []interface{}{"pending", true}, &block)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("node returned no pending block")
+	}
+
+	// Pending blocks may omit their number; opportunities then report 0
+	blockNumber, _ := parseQuantity(block.Number)
+	detected := m.detector.DetectOpportunities(&block, blockNumber)
+
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := make(map[string]models.PendingOpportunity, len(detected))
+	for _, opp := range detected {
+		key := opportunityKey(opp)
+		pending := models.PendingOpportunity{
+			MEVOpportunity: opp,
+			Status:         models.StatusUnconfirmed,
+			FirstSeen:      now,
+			LastSeen:       now,
+		}
+		if prev, ok := m.opportunities[key]; ok {
+			pending.FirstSeen = prev.FirstSeen
+		}
+		current[key] = pending
+	}
+	m.opportunities = current
+	m.updated = now
+	return nil
+}
+
+// Opportunities returns the opportunities found by the last poll, oldest
+// first, and when that poll ran. Nothing is returned once the last
+// successful poll is older than MaxAge.
+func (m *PendingMonitor) Opportunities() ([]models.PendingOpportunity, time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.updated.IsZero() || time.Since(m.updated) > m.MaxAge {
+		return nil, m.updated
+	}
+
+	opps := make([]models.PendingOpportunity, 0, len(m.opportunities))
+	for _, opp := range m.opportunities {
+		opps = append(opps, opp)
+	}
+	sort.Slice(opps, func(i, j int) bool {
+		if !opps[i].FirstSeen.Equal(opps[j].FirstSeen) {
+			return opps[i].FirstSeen.Before(opps[j].FirstSeen)
+		}
+		return opportunityKey(opps[i].MEVOpportunity) < opportunityKey(opps[j].MEVOpportunity)
+	})
+	return opps, m.updated
+}
+
+// opportunityKey identifies an opportunity across polls by its type and
+// transactions
+func opportunityKey(opp models.MEVOpportunity) string {
+	hashes := make([]string, len(opp.Transactions))
+	for i, tx := range opp.Transactions {
+		hashes[i] = strings.ToLower(tx.Hash)
+	}
+	return opp.Type + ":" + strings.Join(hashes, ",")
+}
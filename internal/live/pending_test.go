@@ -0,0 +1,179 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/testutil"
+)
+
+const (
+	testAttacker = "0x2222222222222222222222222222222222222222"
+	testVictim   = "0x1111111111111111111111111111111111111111"
+	testPool     = "0x88e6a0c2ddd26feeb64f039a2c41296fcb3f5640"
+	testPool2    = "0xb4e16d0168e52d35cacd2c6185b44281ec28c9dc"
+)
+
+// sandwich returns a frontrun, victim and backrun on pool, starting at
+// transaction index i
+func sandwich(i int, pool string) []models.Transaction {
+	tx := func(i int, from string, gwei int) models.Transaction {
+		return models.Transaction{
+			Hash:             fmt.Sprintf("0x%02x", i),
+			From:             from,
+			To:               pool,
+			Value:            "0x0",
+			GasPrice:         fmt.Sprintf("0x%x", gwei*1e9),
+			Input:            "0x022c0d9f",
+			TransactionIndex: fmt.Sprintf("0x%x", i),
+		}
+	}
+	return []models.Transaction{tx(i, testAttacker, 50), tx(i+1, testVictim, 20), tx(i+2, testAttacker, 10)}
+}
+
+// pendingSandwiches returns the sandwiches the monitor holds, by their
+// frontrun's hash
+func pendingSandwiches(m *PendingMonitor) map[string]models.PendingOpportunity {
+	opps, _ := m.Opportunities()
+	sandwiches := make(map[string]models.PendingOpportunity)
+	for _, opp := range opps {
+		if opp.Type == "sandwich" {
+			sandwiches[opp.Transactions[0].Hash] = opp
+		}
+	}
+	return sandwiches
+}
+
+func TestPendingMonitorPoll(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	defer srv.Close()
+	m := NewPendingMonitor(srv.Detector())
+
+	if err := m.Poll(context.Background()); err == nil {
+		t.Error("expected an error without a pending block")
+	}
+	if opps, updated := m.Opportunities(); opps != nil || !updated.IsZero() {
+		t.Errorf("got %v updated %v before a successful poll", opps, updated)
+	}
+
+	first := sandwich(0, testPool)
+	srv.SetPending(&models.Block{Number: "0x65", Transactions: first})
+	if err := m.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	got := pendingSandwiches(m)
+	s1, ok := got["0x00"]
+	if len(got) != 1 || !ok {
+		t.Fatalf("got sandwiches %v, want the one pending", got)
+	}
+	if s1.Status != models.StatusUnconfirmed || s1.BlockNumber != 101 || !s1.FirstSeen.Equal(s1.LastSeen) {
+		t.Errorf("got %+v", s1)
+	}
+
+	// Still pending alongside a new one
+	time.Sleep(2 * time.Millisecond)
+	second := sandwich(3, testPool2)
+	srv.SetPending(&models.Block{Transactions: append(append([]models.Transaction{}, first...), second...)})
+	if err := m.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	got = pendingSandwiches(m)
+	if len(got) != 2 {
+		t.Fatalf("got sandwiches %v, want both", got)
+	}
+	if kept := got["0x00"]; !kept.FirstSeen.Equal(s1.FirstSeen) || !kept.LastSeen.After(s1.LastSeen) {
+		t.Errorf("got first seen %v last seen %v, want first seen kept at %v", kept.FirstSeen, kept.LastSeen, s1.FirstSeen)
+	}
+	if s2 := got["0x03"]; !s2.FirstSeen.After(s1.FirstSeen) || s2.BlockNumber != 0 {
+		t.Errorf("got new sandwich %+v, want it seen later in an unnumbered block", s2)
+	}
+	opps, _ := m.Opportunities()
+	var order []string
+	for _, opp := range opps {
+		if opp.Type == "sandwich" {
+			order = append(order, opp.Transactions[0].Hash)
+		}
+	}
+	if len(order) != 2 || order[0] != "0x00" {
+		t.Errorf("got sandwiches in order %v, want the oldest first", order)
+	}
+
+	// Mined or dropped transactions expire
+	srv.SetPending(&models.Block{Transactions: second})
+	if err := m.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if got := pendingSandwiches(m); len(got) != 1 || got["0x03"].Transactions == nil {
+		t.Errorf("got sandwiches %v, want only the one still pending", got)
+	}
+}
+
+func TestPendingMonitorMaxAge(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	defer srv.Close()
+	srv.SetPending(&models.Block{Transactions: sandwich(0, testPool)})
+
+	m := NewPendingMonitor(srv.Detector())
+	m.MaxAge = time.Millisecond
+	if err := m.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	// A failing node leaves the last results to go stale
+	srv.SetError("eth_getBlockByNumber", -32603, "internal error")
+	if err := m.Poll(context.Background()); err == nil {
+		t.Fatal("expected the failing poll to report an error")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if opps, updated := m.Opportunities(); opps != nil || updated.IsZero() {
+		t.Errorf("got %v updated %v, want stale results withheld", opps, updated)
+	}
+}
+
+func TestPendingMonitorStartStop(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	defer srv.Close()
+	srv.SetPending(&models.Block{Transactions: sandwich(0, testPool)})
+
+	m := NewPendingMonitor(srv.Detector())
+	m.Interval = time.Millisecond
+	m.Stop() // Not started
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := m.Start(context.Background()); err == nil {
+		t.Error("expected an error starting twice")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Requests("eth_getBlockByNumber") < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("monitor did not keep polling")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	m.Stop()
+	m.Stop()
+
+	// A request cancelled by Stop may still reach the server, so count
+	// once it has had time to arrive
+	time.Sleep(10 * time.Millisecond)
+	polls := srv.Requests("eth_getBlockByNumber")
+	time.Sleep(10 * time.Millisecond)
+	if srv.Requests("eth_getBlockByNumber") != polls {
+		t.Error("monitor polled after Stop returned")
+	}
+	if len(pendingSandwiches(m)) != 1 {
+		t.Error("background polls found no sandwich")
+	}
+
+	// Restartable once stopped
+	if err := m.Start(context.Background()); err != nil {
+		t.Errorf("Start after Stop: %v", err)
+	}
+	m.Stop()
+}
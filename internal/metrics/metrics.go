@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds the service's collectors along with the standard Go and
+// process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	// RPCRequests counts JSON-RPC requests sent to the provider
+	RPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mev_tracker_rpc_requests_total",
+		Help: "JSON-RPC requests sent to the provider.",
+	}, []string{"method"})
+
+	// RPCFailures counts JSON-RPC requests that returned an error
+	RPCFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mev_tracker_rpc_failures_total",
+		Help: "JSON-RPC requests that failed.",
+	}, []string{"method"})
+
+	// RPCRateLimited counts provider responses throttling requests with HTTP 429
+	RPCRateLimited = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "mev_tracker_rpc_rate_limited_total",
+		Help: "Provider responses rejecting requests with HTTP 429.",
+	})
+
+	// RPCEndpointServed counts requests served by each failover endpoint
+	RPCEndpointServed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mev_tracker_rpc_endpoint_served_total",
+		Help: "Requests served by each RPC failover endpoint.",
+	}, []string{"endpoint"})
+
+	// RPCBreakerState reports each provider's circuit breaker state
+	RPCBreakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mev_tracker_rpc_breaker_state",
+		Help: "RPC circuit breaker state: 0 closed, 1 half-open, 2 open.",
+	}, []string{"endpoint"})
+
+	// HTTPRequestDuration observes API request latency by route
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mev_tracker_http_request_duration_seconds",
+		Help:    "API request latency.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// Opportunities counts detected MEV opportunities by type
+	Opportunities = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mev_tracker_opportunities_total",
+		Help: "MEV opportunities detected.",
+	}, []string{"type"})
+
+	// CacheLookups counts stored block result lookups by outcome
+	CacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mev_tracker_cache_lookups_total",
+		Help: "Stored block result lookups.",
+	}, []string{"result"}) // hit, miss or error
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		RPCRequests,
+		RPCFailures,
+		RPCRateLimited,
+		RPCEndpointServed,
+		RPCBreakerState,
+		HTTPRequestDuration,
+		Opportunities,
+		CacheLookups,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"encoding/hex"
+	"math/big"
+)
+
+// swapRoute is the token route and amounts decoded from a router swap call.
+// For exact-output swaps amountIn is the maximum input; for exact-input
+// swaps amountOut is the minimum output.
+type swapRoute struct {
+	path      []string
+	amountIn  *big.Int
+	amountOut *big.Int
+}
+
+// swapDecoder decodes the arguments of a router swap call. value is the
+// transaction's ETH value for calls that swap native ETH.
+type swapDecoder func(args []byte, value string) (swapRoute, bool)
+
+// Router swap selectors whose token route can be decoded
+var swapDecoders = map[string]swapDecoder{
+	"0x38ed1739": decodeV2ExactIn,       // swapExactTokensForTokens(uint256,uint256,address[],address,uint256)
+	"0x18cbafe5": decodeV2ExactIn,       // swapExactTokensForETH(uint256,uint256,address[],address,uint256)
+	"0x8803dbee": decodeV2ExactOut,      // swapTokensForExactTokens(uint256,uint256,address[],address,uint256)
+	"0x7ff36ab5": decodeV2ExactETHIn,    // swapExactETHForTokens(uint256,address[],address,uint256)
+	"0xc04b8d59": decodeV3ExactInput,    // exactInput((bytes,address,uint256,uint256,uint256))
+	"0xf28c0498": decodeV3ExactOutput,   // exactOutput((bytes,address,uint256,uint256,uint256))
+	"0xb858183f": decodeV3ExactInput02,  // exactInput((bytes,address,uint256,uint256)) on SwapRouter02
+	"0x09b81346": decodeV3ExactOutput02, // exactOutput((bytes,address,uint256,uint256)) on SwapRouter02
+}
+
+// detectArbitrage finds transactions whose router swaps, taken in call
+// order, start and end on the same token across at least ArbitrageMinSwaps
+// hops. Profit is taken from the net delta where the route is denominated in
+// the chain's WrappedNative token, otherwise from the transaction fee.
+// Inputs longer than maxCallDataBytes are not decoded and decoding stops at
+// maxDecodedCalls calls, so crafted calldata cannot stall detection.
+func (d *MEVDetector) detectArbitrage(block *Block) ([]Transaction, float64) {
+	var (
+		arbTxs []Transaction
+		profit float64
+	)
+
+	for _, tx := range block.Transactions {
+		var routes []swapRoute
+		for _, call := range decodeCalls(tx.Input, d.CallDecodeDepth) {
+			decode, ok := swapDecoders[call.selector]
+			if !ok {
+				continue
+			}
+
+			value := ""
+			if call.depth == 0 {
+				value = tx.Value
+			}
+
+			if route, ok := decode(call.args, value); ok && len(route.path) >= 2 {
+				routes = append(routes, route)
+			}
+		}
+
+		if len(routes) == 0 {
+			continue
+		}
+
+		var hops int
+		for _, route := range routes {
+			hops += len(route.path) - 1
+		}
+
+		first, last := routes[0], routes[len(routes)-1]
+		start, end := first.path[0], last.path[len(last.path)-1]
+		if start != end || hops < d.ArbitrageMinSwaps {
+			continue
+		}
+
+		arbTxs = append(arbTxs, tx)
+		if d.WrappedNative != "" && start == d.WrappedNative && first.amountIn != nil && last.amountOut != nil {
+			if delta := new(big.Int).Sub(last.amountOut, first.amountIn); delta.Sign() > 0 {
+				profit += WeiToEth(delta)
+				continue
+			}
+		}
+		profit += txFeeEth(tx)
+	}
+
+	return arbTxs, profit
+}
+
+// txFeeEth returns gasPrice * gasUsed in ETH, or 0 if either is unavailable
+func txFeeEth(tx Transaction) float64 {
+	gasPrice, err := parseHexWei(paidGasPrice(tx))
+	if err != nil {
+		return 0
+	}
+	gasUsed, err := parseHexWei(tx.GasUsed)
+	if err != nil {
+		return 0
+	}
+	return WeiToEth(new(big.Int).Mul(gasPrice, gasUsed))
+}
+
+func decodeV2ExactIn(args []byte, _ string) (swapRoute, bool) {
+	amountIn, ok1 := abiUint(args, 0)
+	amountOut, ok2 := abiUint(args, 32)
+	path, ok3 := abiAddressArray(args, 2)
+	return swapRoute{path: path, amountIn: amountIn, amountOut: amountOut}, ok1 && ok2 && ok3
+}
+
+func decodeV2ExactOut(args []byte, _ string) (swapRoute, bool) {
+	amountOut, ok1 := abiUint(args, 0)
+	amountIn, ok2 := abiUint(args, 32)
+	path, ok3 := abiAddressArray(args, 2)
+	return swapRoute{path: path, amountIn: amountIn, amountOut: amountOut}, ok1 && ok2 && ok3
+}
+
+func decodeV2ExactETHIn(args []byte, value string) (swapRoute, bool) {
+	amountOut, ok1 := abiUint(args, 0)
+	path, ok2 := abiAddressArray(args, 1)
+	amountIn, _ := parseHexWei(value)
+	return swapRoute{path: path, amountIn: amountIn, amountOut: amountOut}, ok1 && ok2
+}
+
+// decodeV3Params decodes a Uniswap V3 params tuple whose first member is the
+// packed path. inSlot and outSlot are the tuple slots of the amounts.
+func decodeV3Params(args []byte, inSlot, outSlot int, reversed bool) (swapRoute, bool) {
+	tuple, ok := abiWord(args, 0)
+	if !ok {
+		return swapRoute{}, false
+	}
+	pathOffset, ok := abiWord(args, tuple)
+	if !ok {
+		return swapRoute{}, false
+	}
+	packed, ok := abiBytesAt(args, tuple+pathOffset)
+	if !ok {
+		return swapRoute{}, false
+	}
+	path, ok := v3PathTokens(packed)
+	if !ok {
+		return swapRoute{}, false
+	}
+	if reversed {
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+	}
+
+	amountIn, ok1 := abiUint(args, tuple+inSlot*32)
+	amountOut, ok2 := abiUint(args, tuple+outSlot*32)
+	return swapRoute{path: path, amountIn: amountIn, amountOut: amountOut}, ok1 && ok2
+}
+
+func decodeV3ExactInput(args []byte, _ string) (swapRoute, bool) {
+	return decodeV3Params(args, 3, 4, false)
+}
+
+func decodeV3ExactOutput(args []byte, _ string) (swapRoute, bool) {
+	return decodeV3Params(args, 4, 3, true)
+}
+
+func decodeV3ExactInput02(args []byte, _ string) (swapRoute, bool) {
+	return decodeV3Params(args, 2, 3, false)
+}
+
+func decodeV3ExactOutput02(args []byte, _ string) (swapRoute, bool) {
+	return decodeV3Params(args, 3, 2, true)
+}
+
+// v3PathTokens splits a packed V3 path (token, fee, token, ...) into tokens
+func v3PathTokens(packed []byte) ([]string, bool) {
+	const addrLen, hopLen = 20, 23
+	if len(packed) < addrLen+hopLen || (len(packed)-addrLen)%hopLen != 0 {
+		return nil, false
+	}
+
+	var tokens []string
+	for i := 0; i+addrLen <= len(packed); i += hopLen {
+		tokens = append(tokens, "0x"+hex.EncodeToString(packed[i:i+addrLen]))
+	}
+	return tokens, true
+}
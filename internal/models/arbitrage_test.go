@@ -0,0 +1,168 @@
+package models
+
+import (
+	"encoding/hex"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// Mainnet tokens used in the arbitrage routes
+const (
+	testWETH = "c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	testUSDC = "a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	testDAI  = "6b175474e89094c44da98b954eedeac495271d0f"
+)
+
+// milliEther returns n thousandths of an ether in wei
+func milliEther(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e15))
+}
+
+// addressWord left-pads a hex address without 0x into an ABI word
+func addressWord(t testing.TB, addr string) []byte {
+	return append(make([]byte, 12), mustHex(t, addr)...)
+}
+
+// encodeV2Swap encodes swapExactTokensForTokens(amountIn, amountOutMin,
+// path, to, deadline) as sent to the Uniswap V2 router
+func encodeV2Swap(t testing.TB, amountIn, amountOutMin *big.Int, path ...string) []byte {
+	data := mustHex(t, "38ed1739")
+	data = append(data, amountIn.FillBytes(make([]byte, 32))...)
+	data = append(data, amountOutMin.FillBytes(make([]byte, 32))...)
+	data = append(data, abiUintWord(5*32)...) // Offset of path
+	data = append(data, addressWord(t, "1111111111111111111111111111111111111111")...)
+	data = append(data, abiUintWord(1700000000)...)
+	data = append(data, abiUintWord(len(path))...)
+	for _, addr := range path {
+		data = append(data, addressWord(t, addr)...)
+	}
+	return data
+}
+
+// encodeV3ExactInput encodes SwapRouter exactInput((path, recipient,
+// deadline, amountIn, amountOutMinimum)) over a packed path with 0.05% fees
+func encodeV3ExactInput(t testing.TB, amountIn, amountOutMin *big.Int, tokens ...string) []byte {
+	var packed []byte
+	for i, token := range tokens {
+		if i > 0 {
+			packed = append(packed, 0x00, 0x01, 0xf4) // Fee tier 500
+		}
+		packed = append(packed, mustHex(t, token)...)
+	}
+
+	data := mustHex(t, "c04b8d59")
+	data = append(data, abiUintWord(32)...)   // Offset of the params tuple
+	data = append(data, abiUintWord(5*32)...) // Offset of path within the tuple
+	data = append(data, addressWord(t, "1111111111111111111111111111111111111111")...)
+	data = append(data, abiUintWord(1700000000)...)
+	data = append(data, amountIn.FillBytes(make([]byte, 32))...)
+	data = append(data, amountOutMin.FillBytes(make([]byte, 32))...)
+	data = append(data, abiUintWord(len(packed))...)
+	data = append(data, packed...)
+	return append(data, make([]byte, 32-len(packed)%32)...)
+}
+
+func arbitrageTx(input []byte) Transaction {
+	return Transaction{
+		Hash:     "0x01",
+		Input:    "0x" + hex.EncodeToString(input),
+		Value:    "0x0",
+		GasPrice: "0x3b9aca00", // 1 gwei
+		GasUsed:  "0x30d40",    // 200000
+	}
+}
+
+func TestDetectArbitrage(t *testing.T) {
+	const txFee = 0.0002 // 1 gwei * 200000 gas
+
+	tests := []struct {
+		name       string
+		input      []byte
+		chainToken string // WrappedNative; empty keeps the mainnet default
+		want       bool
+		profit     float64
+	}{
+		{
+			name:   "v2 cycle through weth",
+			input:  encodeV2Swap(t, milliEther(1000), milliEther(1020), testWETH, testUSDC, testWETH),
+			want:   true,
+			profit: 0.02,
+		},
+		{
+			name:   "v3 cycle through weth",
+			input:  encodeV3ExactInput(t, milliEther(1000), milliEther(1005), testWETH, testUSDC, testDAI, testWETH),
+			want:   true,
+			profit: 0.005,
+		},
+		{
+			name: "cycle split across a multicall",
+			input: encodeMulticall(t,
+				encodeV2Swap(t, milliEther(1000), big.NewInt(2000e6), testWETH, testUSDC),
+				encodeV2Swap(t, big.NewInt(2000e6), milliEther(1010), testUSDC, testWETH),
+			),
+			want:   true,
+			profit: 0.01,
+		},
+		{
+			name:   "stablecoin cycle valued by fee",
+			input:  encodeV2Swap(t, big.NewInt(1000e6), big.NewInt(1001e6), testUSDC, testDAI, testUSDC),
+			want:   true,
+			profit: txFee,
+		},
+		{
+			name:       "weth cycle on another chain valued by fee",
+			input:      encodeV2Swap(t, milliEther(1000), milliEther(1020), testWETH, testUSDC, testWETH),
+			chainToken: "0x0d500b1d8e8ef31e21c99d1db9a6444d3adf1270",
+			want:       true,
+			profit:     txFee,
+		},
+		{
+			name:  "one-way swap",
+			input: encodeV2Swap(t, milliEther(1000), big.NewInt(2000e6), testWETH, testUSDC),
+			want:  false,
+		},
+		{
+			name:  "transfer",
+			input: mustHex(t, "a9059cbb"+hex.EncodeToString(addressWord(t, testUSDC))+hex.EncodeToString(abiUintWord(1))),
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewMEVDetector(nil)
+			if tt.chainToken != "" {
+				d.WrappedNative = tt.chainToken
+			}
+
+			txs, profit := d.detectArbitrage(&Block{Transactions: []Transaction{arbitrageTx(tt.input)}})
+			if got := len(txs) == 1; got != tt.want {
+				t.Fatalf("detected %d arbitrage transactions, want arbitrage %v", len(txs), tt.want)
+			}
+			if math.Abs(profit-tt.profit) > 1e-9 {
+				t.Errorf("profit = %v, want %v", profit, tt.profit)
+			}
+		})
+	}
+}
+
+func TestDetectArbitrageMinSwaps(t *testing.T) {
+	input := encodeV2Swap(t, milliEther(1000), milliEther(1020), testWETH, testUSDC, testWETH)
+	d := NewMEVDetector(nil)
+	d.ArbitrageMinSwaps = 3
+
+	if txs, _ := d.detectArbitrage(&Block{Transactions: []Transaction{arbitrageTx(input)}}); len(txs) != 0 {
+		t.Errorf("two-hop cycle detected with ArbitrageMinSwaps 3")
+	}
+}
+
+func TestWrappedNativeDefault(t *testing.T) {
+	want, ok := WrappedNative(DefaultChain)
+	if !ok || want != "0x"+testWETH {
+		t.Fatalf("WrappedNative(%q) = %q, %v", DefaultChain, want, ok)
+	}
+	if got := NewMEVDetector(nil).WrappedNative; got != want {
+		t.Errorf("detector WrappedNative = %q, want %q", got, want)
+	}
+}
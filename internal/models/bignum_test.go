@@ -0,0 +1,124 @@
+package models
+
+import (
+	"math/big"
+	"testing"
+)
+
+// The baselines below are the detection and reward math as written before
+// the big number constants were precomputed; run with -benchmem to compare
+// allocations per block.
+
+// highValueByEth is detectHighValueTransactions converting each value to
+// ETH before comparing, as it used to
+func highValueByEth(block *Block) []Transaction {
+	var highValueTxs []Transaction
+	for _, tx := range block.Transactions {
+		value, err := parseHexWei(tx.Value)
+		if err != nil {
+			continue
+		}
+		ethValue := new(big.Float).Quo(
+			new(big.Float).SetInt(value),
+			new(big.Float).SetInt(big.NewInt(1e18)),
+		)
+		if ethValue.Cmp(big.NewFloat(highValueThresholdETH)) >= 0 {
+			highValueTxs = append(highValueTxs, tx)
+		}
+	}
+	return highValueTxs
+}
+
+// rewardsAllocatingTips is opportunityRewards allocating a tip per
+// transaction and rebuilding the wei divisor per conversion, as it used to
+func (d *MEVDetector) rewardsAllocatingTips(opportunities []MEVOpportunity) []float64 {
+	rewards := make([]float64, len(opportunities))
+	counted := make(map[string]bool)
+	for i, opp := range opportunities {
+		baseFee, err := parseHexWei(opp.BaseFeePerGas)
+		if err != nil {
+			baseFee = new(big.Int)
+		}
+		for _, tx := range opp.Transactions {
+			if counted[tx.Hash] {
+				continue
+			}
+			counted[tx.Hash] = true
+
+			gasPrice, err := parseHexWei(paidGasPrice(tx))
+			if err != nil {
+				continue
+			}
+			gasUsed, err := parseHexWei(tx.GasUsed)
+			if err != nil {
+				continue
+			}
+			tip := new(big.Int).Sub(gasPrice, baseFee)
+			if tip.Sign() <= 0 {
+				continue
+			}
+			tip.Mul(tip, gasUsed)
+			eth, _ := new(big.Float).Quo(
+				new(big.Float).SetInt(tip),
+				new(big.Float).SetInt(big.NewInt(1e18)),
+			).Float64()
+			rewards[i] += eth * d.ValidatorMEVShare
+		}
+	}
+	return rewards
+}
+
+func TestBignumBaselinesAgree(t *testing.T) {
+	d := newBusyDetector()
+	block := busyBlock(t, 90)
+
+	highValue, wantHighValue := d.detectHighValueTransactions(block), highValueByEth(block)
+	if len(wantHighValue) == 0 || !sameHashes(highValue, wantHighValue) {
+		t.Errorf("got high value transactions %v, want %v", hashes(highValue), hashes(wantHighValue))
+	}
+
+	opps := d.DetectOpportunities(block, 100)
+	got, _ := d.opportunityRewards(opps)
+	want := d.rewardsAllocatingTips(opps)
+	for i := range want {
+		if diff := got[i] - want[i]; diff > 1e-12 || diff < -1e-12 {
+			t.Errorf("opportunity %d: got reward %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func BenchmarkHighValueTransactions(b *testing.B) {
+	d := newBusyDetector()
+	block := busyBlock(b, 300)
+
+	b.Run("eth", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			highValueByEth(block)
+		}
+	})
+	b.Run("wei", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.detectHighValueTransactions(block)
+		}
+	})
+}
+
+func BenchmarkOpportunityRewards(b *testing.B) {
+	d := newBusyDetector()
+	opps := d.DetectOpportunities(busyBlock(b, 300), 100)
+
+	b.Run("allocating", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.rewardsAllocatingTips(opps)
+		}
+	})
+	b.Run("reused", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.opportunityRewards(opps)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var addressPattern = regexp.MustCompile(`^0x[0-9a-f]{40}$`)
+
+// NormalizeAddress lowercases addr and reports whether it is a 20-byte
+// 0x-prefixed hex address
+func NormalizeAddress(addr string) (string, bool) {
+	addr = strings.ToLower(strings.TrimSpace(addr))
+	return addr, addressPattern.MatchString(addr)
+}
+
+// BotSet is a concurrency-safe set of known MEV bot addresses, stored
+// lowercased
+type BotSet struct {
+	mu    sync.RWMutex
+	addrs map[string]bool
+}
+
+// NewBotSet returns a set holding addrs
+func NewBotSet(addrs ...string) *BotSet {
+	s := &BotSet{addrs: make(map[string]bool, len(addrs))}
+	for _, addr := range addrs {
+		s.addrs[strings.ToLower(addr)] = true
+	}
+	return s
+}
+
+// IsKnownBot reports whether addr is in the set
+func (s *BotSet) IsKnownBot(addr string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.addrs[strings.ToLower(addr)]
+}
+
+// AddBot adds addr to the set, reporting false if it was already present
+func (s *BotSet) AddBot(addr string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addr = strings.ToLower(addr)
+	if s.addrs[addr] {
+		return false
+	}
+	s.addrs[addr] = true
+	return true
+}
+
+// RemoveBot removes addr from the set, reporting false if it was not present
+func (s *BotSet) RemoveBot(addr string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addr = strings.ToLower(addr)
+	if !s.addrs[addr] {
+		return false
+	}
+	delete(s.addrs, addr)
+	return true
+}
+
+// Len returns the number of addresses in the set
+func (s *BotSet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.addrs)
+}
+
+// List returns the addresses in the set in sorted order
+func (s *BotSet) List() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addrs := make([]string, 0, len(s.addrs))
+	for addr := range s.addrs {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// LoadKnownBots reads MEV bot addresses from a JSON array of strings or a
+// newline-separated file. Blank lines and lines starting with # are ignored
+// in newline files. Addresses are lowercased and deduplicated.
+func LoadKnownBots(path string) (*BotSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known bots file: %w", err)
+	}
+
+	var entries []string
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse known bots file: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			entries = append(entries, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read known bots file: %w", err)
+		}
+	}
+
+	bots := NewBotSet()
+	for i, entry := range entries {
+		addr, ok := NormalizeAddress(entry)
+		if !ok {
+			return nil, fmt.Errorf("invalid address %q at entry %d in known bots file", entry, i+1)
+		}
+		bots.AddBot(addr)
+	}
+
+	return bots, nil
+}
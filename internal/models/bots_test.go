@@ -0,0 +1,46 @@
+package models_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// TestBotSetConcurrentAccess mutates the known bot set while detection reads
+// it. Run with -race.
+func TestBotSetConcurrentAccess(t *testing.T) {
+	detector := models.NewMEVDetector(nil)
+	block := &models.Block{Transactions: []models.Transaction{
+		{Hash: "0x01", From: flashbotsBuilder, To: poolAddress, Value: "0x0", Input: "0x"},
+	}}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			addr := fmt.Sprintf("0x%040x", i+1)
+			for range 100 {
+				detector.KnownMEVBots.AddBot(addr)
+				detector.KnownMEVBots.RemoveBot(addr)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				if len(detector.DetectOpportunities(block, 1)) == 0 {
+					t.Error("known bot transaction not detected")
+					return
+				}
+				detector.KnownMEVBots.List()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := detector.KnownMEVBots.Len(); n != 1 {
+		t.Errorf("set has %d addresses after balanced adds and removes, want 1", n)
+	}
+}
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/metrics"
+)
+
+// Circuit breaker defaults
+const (
+	DefaultBreakerFailureRatio = 0.5
+	DefaultBreakerMinRequests  = 20
+	DefaultBreakerInterval     = time.Minute
+	DefaultBreakerOpenTimeout  = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without contacting the provider while its
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("RPC provider unavailable: circuit breaker open")
+
+// Breaker states, as reported by the mev_tracker_rpc_breaker_state gauge
+const (
+	breakerClosed   = 0
+	breakerHalfOpen = 1
+	breakerOpen     = 2
+)
+
+// BreakerProvider stops sending requests to a failing provider. While
+// closed it counts outcomes over Interval and opens once at least
+// MinRequests were made and FailureRatio of them failed. While open every
+// call fails fast with ErrCircuitOpen; after OpenTimeout a single probe is
+// let through, closing the breaker on success and reopening it on failure.
+//
+// Only transport failures count: JSON-RPC errors mean the provider is
+// answering, and cancelled requests say nothing about its health.
+type BreakerProvider struct {
+	Name         string // Labels metrics, so it should not contain credentials
+	Provider     RPCProvider
+	FailureRatio float64
+	MinRequests  int
+	Interval     time.Duration
+	OpenTimeout  time.Duration
+
+	mu        sync.Mutex
+	state     int
+	requests  int
+	failures  int
+	windowEnd time.Time
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// NewBreakerProvider wraps p in a circuit breaker with the default settings
+func NewBreakerProvider(name string, p RPCProvider) *BreakerProvider {
+	metrics.RPCBreakerState.WithLabelValues(name).Set(breakerClosed)
+	return &BreakerProvider{
+		Name:         name,
+		Provider:     p,
+		FailureRatio: DefaultBreakerFailureRatio,
+		MinRequests:  DefaultBreakerMinRequests,
+		Interval:     DefaultBreakerInterval,
+		OpenTimeout:  DefaultBreakerOpenTimeout,
+		now:          time.Now,
+	}
+}
+
+// GetBlockByNumber retrieves a block unless the breaker is open
+func (b *BreakerProvider) GetBlockByNumber(ctx context.Context, blockNumber int, fullTransactions bool) (*Block, error) {
+	var block *Block
+	err := b.do(ctx, func() error {
+		var err error
+		block, err = b.Provider.GetBlockByNumber(ctx, blockNumber, fullTransactions)
+		return err
+	})
+	return block, err
+}
+
+// GetBlocksByNumber retrieves several blocks unless the breaker is open,
+// batching where the wrapped provider supports it
+func (b *BreakerProvider) GetBlocksByNumber(ctx context.Context, blockNumbers []int, fullTransactions bool) ([]*Block, error) {
+	var blocks []*Block
+	err := b.do(ctx, func() error {
+		var err error
+		blocks, err = getBlocks(ctx, b.Provider, blockNumbers, fullTransactions)
+		return err
+	})
+	return blocks, err
+}
+
+// BlockNumber returns the latest block number unless the breaker is open
+func (b *BreakerProvider) BlockNumber(ctx context.Context) (int, error) {
+	var blockNumber int
+	err := b.do(ctx, func() error {
+		var err error
+		blockNumber, err = b.Provider.BlockNumber(ctx)
+		return err
+	})
+	return blockNumber, err
+}
+
+// Call performs a JSON-RPC request unless the breaker is open
+func (b *BreakerProvider) Call(ctx context.Context, method string, params []interface{}, out interface{}) (bool, error) {
+	var found bool
+	err := b.do(ctx, func() error {
+		var err error
+		found, err = b.Provider.Call(ctx, method, params, out)
+		return err
+	})
+	return found, err
+}
+
+// do runs fn if the breaker allows it and records the outcome
+func (b *BreakerProvider) do(ctx context.Context, fn func() error) error {
+	probe, err := b.allow()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	b.record(probe, err == nil || !isProviderFailure(ctx, err))
+	return err
+}
+
+// allow reports whether a request may proceed and whether it is the
+// half-open probe
+func (b *BreakerProvider) allow() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case breakerOpen:
+		if now.Before(b.openUntil) {
+			return false, fmt.Errorf("%s: %w", b.Name, ErrCircuitOpen)
+		}
+		b.setState(breakerHalfOpen)
+		fallthrough
+	case breakerHalfOpen:
+		if b.probing {
+			return false, fmt.Errorf("%s: %w", b.Name, ErrCircuitOpen)
+		}
+		b.probing = true
+		return true, nil
+	}
+
+	if now.After(b.windowEnd) {
+		b.requests, b.failures = 0, 0
+		b.windowEnd = now.Add(b.Interval)
+	}
+	return false, nil
+}
+
+// record counts a finished request, tripping or resetting the breaker
+func (b *BreakerProvider) record(probe, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+		if ok {
+			b.requests, b.failures = 0, 0
+			b.windowEnd = b.now().Add(b.Interval)
+			b.setState(breakerClosed)
+		} else {
+			b.trip()
+		}
+		return
+	}
+	if b.state != breakerClosed {
+		// Finished after the breaker changed state; the outcome is stale
+		return
+	}
+
+	b.requests++
+	if !ok {
+		b.failures++
+	}
+	if b.requests >= b.MinRequests && float64(b.failures) >= b.FailureRatio*float64(b.requests) {
+		b.trip()
+	}
+}
+
+func (b *BreakerProvider) trip() {
+	b.openUntil = b.now().Add(b.OpenTimeout)
+	b.setState(breakerOpen)
+}
+
+func (b *BreakerProvider) setState(state int) {
+	b.state = state
+	metrics.RPCBreakerState.WithLabelValues(b.Name).Set(float64(state))
+}
+
+// isProviderFailure reports whether err reflects on the provider's health
+func isProviderFailure(ctx context.Context, err error) bool {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	return ctx.Err() == nil
+}
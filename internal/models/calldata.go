@@ -0,0 +1,258 @@
+package models
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+)
+
+// DefaultCallDecodeDepth is how many levels of multicall wrappers are unwrapped
+const DefaultCallDecodeDepth = 2
+
+// MaxCallDecodeDepth bounds recursion into nested multicall wrappers
+const MaxCallDecodeDepth = 8
+
+// maxCallDataBytes bounds the transaction input decodeCalls will unwrap.
+// Router multicalls are a few kilobytes; larger inputs are not decoded.
+const maxCallDataBytes = 32 * 1024
+
+// maxDecodedCalls bounds the calls decoded from a single transaction, so
+// deeply or widely nested wrappers cannot make decoding blow up
+const maxDecodedCalls = 256
+
+// Multicall wrapper selectors whose single bytes[] argument holds inner calls
+var multicallSelectors = map[string]int{
+	"0xac9650d8": 0, // multicall(bytes[])
+	"0x5ae401dc": 1, // multicall(uint256 deadline, bytes[])
+	"0x1f0464d1": 1, // multicall(bytes32 previousBlockhash, bytes[])
+}
+
+// Universal Router execute selectors: execute(bytes commands, bytes[] inputs[, uint256 deadline])
+var executeSelectors = map[string]bool{
+	"0x24856bc3": true, // execute(bytes,bytes[])
+	"0x3593564c": true, // execute(bytes,bytes[],uint256)
+}
+
+// Universal Router command types that perform a swap
+var swapCommands = map[byte]bool{
+	0x00: true, // V3_SWAP_EXACT_IN
+	0x01: true, // V3_SWAP_EXACT_OUT
+	0x08: true, // V2_SWAP_EXACT_IN
+	0x09: true, // V2_SWAP_EXACT_OUT
+}
+
+// Known DEX router swap selectors
+var swapSelectors = map[string]bool{
+	"0x38ed1739": true, // swapExactTokensForTokens
+	"0x8803dbee": true, // swapTokensForExactTokens
+	"0x7ff36ab5": true, // swapExactETHForTokens
+	"0x18cbafe5": true, // swapExactTokensForETH
+	"0x414bf389": true, // exactInputSingle (SwapRouter)
+	"0xc04b8d59": true, // exactInput (SwapRouter)
+	"0xdb3e2198": true, // exactOutputSingle (SwapRouter)
+	"0xf28c0498": true, // exactOutput (SwapRouter)
+	"0x04e45aaf": true, // exactInputSingle (SwapRouter02)
+	"0xb858183f": true, // exactInput (SwapRouter02)
+	"0x09b81346": true, // exactOutput (SwapRouter02)
+}
+
+// decodedCall is a single call found in transaction input, possibly nested
+// inside one or more multicall wrappers
+type decodedCall struct {
+	selector string
+	args     []byte
+	depth    int
+	swap     bool
+}
+
+// decodeCalls returns the top-level call in input plus any inner calls found
+// by unwrapping multicall and execute wrappers up to maxDepth levels deep.
+// At most maxDecodedCalls calls are returned; decoding stops at the limit.
+// Inputs over maxCallDataBytes decode to nothing.
+func decodeCalls(input string, maxDepth int) []decodedCall {
+	if len(input) > 2+2*maxCallDataBytes {
+		return nil
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(input, "0x"))
+	if err != nil {
+		return nil
+	}
+
+	if maxDepth > MaxCallDecodeDepth {
+		maxDepth = MaxCallDecodeDepth
+	}
+
+	var calls []decodedCall
+	var walk func(data []byte, depth int)
+	walk = func(data []byte, depth int) {
+		if len(data) < 4 || len(calls) >= maxDecodedCalls {
+			return
+		}
+
+		selector := "0x" + hex.EncodeToString(data[:4])
+		args := data[4:]
+		calls = append(calls, decodedCall{
+			selector: selector,
+			args:     args,
+			depth:    depth,
+			swap:     swapSelectors[selector],
+		})
+
+		if depth >= maxDepth {
+			return
+		}
+
+		if head, ok := multicallSelectors[selector]; ok {
+			inner, ok := abiBytesArray(args, head)
+			if !ok {
+				return
+			}
+			for _, call := range inner {
+				walk(call, depth+1)
+			}
+			return
+		}
+
+		if executeSelectors[selector] {
+			commands, ok := abiBytes(args, 0)
+			if !ok {
+				return
+			}
+			inputs, ok := abiBytesArray(args, 1)
+			if !ok {
+				return
+			}
+			for i, command := range commands {
+				if i >= len(inputs) || len(calls) >= maxDecodedCalls {
+					break
+				}
+				calls = append(calls, decodedCall{
+					args:  inputs[i],
+					depth: depth + 1,
+					swap:  swapCommands[command&0x3f],
+				})
+			}
+		}
+	}
+	walk(data, 0)
+
+	return calls
+}
+
+// countSwaps returns the number of swap calls in input, including those
+// nested inside multicall wrappers
+func (d *MEVDetector) countSwaps(input string) int {
+	var swaps int
+	for _, call := range decodeCalls(input, d.CallDecodeDepth) {
+		if call.swap {
+			swaps++
+		}
+	}
+	return swaps
+}
+
+// abiWord reads the 32-byte word at offset as a non-negative int
+func abiWord(data []byte, offset int) (int, bool) {
+	if offset < 0 || offset+32 > len(data) {
+		return 0, false
+	}
+	word := new(big.Int).SetBytes(data[offset : offset+32])
+	if !word.IsInt64() || word.Int64() > int64(len(data)) {
+		return 0, false
+	}
+	return int(word.Int64()), true
+}
+
+// abiBytes decodes the dynamic bytes argument whose offset is in head slot i
+func abiBytes(data []byte, i int) ([]byte, bool) {
+	offset, ok := abiWord(data, i*32)
+	if !ok {
+		return nil, false
+	}
+	return abiBytesAt(data, offset)
+}
+
+// abiBytesAt decodes a length-prefixed bytes value starting at offset
+func abiBytesAt(data []byte, offset int) ([]byte, bool) {
+	length, ok := abiWord(data, offset)
+	if !ok || offset+32+length > len(data) {
+		return nil, false
+	}
+	return data[offset+32 : offset+32+length], true
+}
+
+// abiBytesArray decodes the dynamic bytes[] argument whose offset is in head
+// slot i. Elements must follow the offsets and each other without
+// overlapping, as the ABI encoder lays them out; arrays whose offsets alias
+// one payload are rejected, since unwrapping them would decode that payload
+// once per alias.
+func abiBytesArray(data []byte, i int) ([][]byte, bool) {
+	offset, ok := abiWord(data, i*32)
+	if !ok {
+		return nil, false
+	}
+
+	count, ok := abiWord(data, offset)
+	if !ok {
+		return nil, false
+	}
+
+	base := offset + 32
+	next := base + count*32 // First byte an element may start at
+	if next > len(data) {
+		return nil, false
+	}
+	elements := make([][]byte, 0, count)
+	for j := 0; j < count; j++ {
+		elemOffset, ok := abiWord(data, base+j*32)
+		if !ok || base+elemOffset < next {
+			return nil, false
+		}
+		elem, ok := abiBytesAt(data, base+elemOffset)
+		if !ok {
+			return nil, false
+		}
+		elements = append(elements, elem)
+		next = base + elemOffset + 32 + len(elem)
+	}
+
+	return elements, true
+}
+
+// abiUint reads the 32-byte word at offset as an unsigned integer
+func abiUint(data []byte, offset int) (*big.Int, bool) {
+	if offset < 0 || offset+32 > len(data) {
+		return nil, false
+	}
+	return new(big.Int).SetBytes(data[offset : offset+32]), true
+}
+
+// abiAddress reads the address stored in the 32-byte word at offset
+func abiAddress(data []byte, offset int) (string, bool) {
+	if offset < 0 || offset+32 > len(data) {
+		return "", false
+	}
+	return strings.ToLower("0x" + hex.EncodeToString(data[offset+12:offset+32])), true
+}
+
+// abiAddressArray decodes the dynamic address[] argument whose offset is in head slot i
+func abiAddressArray(data []byte, i int) ([]string, bool) {
+	offset, ok := abiWord(data, i*32)
+	if !ok {
+		return nil, false
+	}
+	count, ok := abiWord(data, offset)
+	if !ok || offset+32+count*32 > len(data) {
+		return nil, false
+	}
+
+	addresses := make([]string, 0, count)
+	for j := 0; j < count; j++ {
+		addr, ok := abiAddress(data, offset+32+j*32)
+		if !ok {
+			return nil, false
+		}
+		addresses = append(addresses, addr)
+	}
+	return addresses, true
+}
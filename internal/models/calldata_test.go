@@ -0,0 +1,147 @@
+package models
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+const (
+	multicallSelector       = "ac9650d8" // multicall(bytes[])
+	multicallDeadline       = "5ae401dc" // multicall(uint256 deadline, bytes[])
+	exactInputSingle02      = "04e45aaf" // exactInputSingle on SwapRouter02
+	exactInputSingle02Words = 7
+)
+
+// abiUintWord returns n as a 32-byte ABI word
+func abiUintWord(n int) []byte {
+	return new(big.Int).SetInt64(int64(n)).FillBytes(make([]byte, 32))
+}
+
+// mustHex decodes a hex selector
+func mustHex(t testing.TB, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// encodeBytesArray ABI-encodes elems as a bytes[] tail: the count, the
+// element offsets and the padded elements
+func encodeBytesArray(elems [][]byte) []byte {
+	out := abiUintWord(len(elems))
+	var tail []byte
+	for _, elem := range elems {
+		out = append(out, abiUintWord(len(elems)*32+len(tail))...)
+		tail = append(tail, abiUintWord(len(elem))...)
+		tail = append(tail, elem...)
+		if pad := len(elem) % 32; pad != 0 {
+			tail = append(tail, make([]byte, 32-pad)...)
+		}
+	}
+	return append(out, tail...)
+}
+
+// encodeMulticall encodes multicall(bytes[]) wrapping calls
+func encodeMulticall(t testing.TB, calls ...[]byte) []byte {
+	data := mustHex(t, multicallSelector)
+	data = append(data, abiUintWord(32)...)
+	return append(data, encodeBytesArray(calls)...)
+}
+
+// encodeSwap encodes an exactInputSingle call with zeroed params
+func encodeSwap(t testing.TB) []byte {
+	return append(mustHex(t, exactInputSingle02), make([]byte, exactInputSingle02Words*32)...)
+}
+
+func TestCountSwapsMulticallWrapped(t *testing.T) {
+	// multicall(deadline, [swap, swap]) as sent to SwapRouter02
+	wrapped := mustHex(t, multicallDeadline)
+	wrapped = append(wrapped, abiUintWord(1700000000)...)
+	wrapped = append(wrapped, abiUintWord(64)...)
+	wrapped = append(wrapped, encodeBytesArray([][]byte{encodeSwap(t), encodeSwap(t)})...)
+
+	nested := encodeMulticall(t, wrapped)
+
+	tests := []struct {
+		name  string
+		input []byte
+		depth int
+		want  int
+	}{
+		{name: "plain swap", input: encodeSwap(t), depth: DefaultCallDecodeDepth, want: 1},
+		{name: "wrapped", input: wrapped, depth: DefaultCallDecodeDepth, want: 2},
+		{name: "wrapped without unwrapping", input: wrapped, depth: 0, want: 0},
+		{name: "nested twice", input: nested, depth: 2, want: 2},
+		{name: "nested beyond depth", input: nested, depth: 1, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewMEVDetector(nil)
+			d.CallDecodeDepth = tt.depth
+			if got := d.countSwaps("0x" + hex.EncodeToString(tt.input)); got != tt.want {
+				t.Errorf("countSwaps = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// aliasedMulticall encodes multicall(bytes[]) with n element offsets that
+// all point at inner
+func aliasedMulticall(t testing.TB, inner []byte, n int) []byte {
+	data := mustHex(t, multicallSelector)
+	data = append(data, abiUintWord(32)...)
+	data = append(data, abiUintWord(n)...)
+	for range n {
+		data = append(data, abiUintWord(n*32)...)
+	}
+	data = append(data, abiUintWord(len(inner))...)
+	return append(data, inner...)
+}
+
+// TestDecodeCallsRejectsAliasedElements is a regression test for element
+// offsets that alias one payload, which made nested unwrapping decode it
+// once per alias at every level
+func TestDecodeCallsRejectsAliasedElements(t *testing.T) {
+	payload := encodeSwap(t)
+	for range 3 {
+		payload = aliasedMulticall(t, payload, 64)
+	}
+	input := "0x" + hex.EncodeToString(payload)
+
+	var calls []decodedCall
+	allocs := testing.AllocsPerRun(5, func() {
+		calls = decodeCalls(input, MaxCallDecodeDepth)
+	})
+	if len(calls) != 1 {
+		t.Errorf("decoded %d calls, want only the outer multicall", len(calls))
+	}
+	if allocs > 100 {
+		t.Errorf("decoding took %v allocations", allocs)
+	}
+}
+
+func TestDecodeCallsCapsCalls(t *testing.T) {
+	// Bare selectors keep 300 calls under maxCallDataBytes
+	swaps := make([][]byte, 300)
+	for i := range swaps {
+		swaps[i] = mustHex(t, exactInputSingle02)
+	}
+	input := "0x" + hex.EncodeToString(encodeMulticall(t, swaps...))
+
+	if got := len(decodeCalls(input, DefaultCallDecodeDepth)); got != maxDecodedCalls {
+		t.Errorf("decoded %d calls, want the %d limit", got, maxDecodedCalls)
+	}
+}
+
+func TestDecodeCallsSkipsOversizedInput(t *testing.T) {
+	input := encodeSwap(t)
+	input = append(input, make([]byte, maxCallDataBytes)...)
+
+	if calls := decodeCalls("0x"+hex.EncodeToString(input), DefaultCallDecodeDepth); calls != nil {
+		t.Errorf("decoded %d calls from a %d byte input, want none", len(calls), len(input))
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+// DefaultChain is used when no chain is configured
+const DefaultChain = "ethereum"
+
+// nativeSymbols maps supported chains to their native token symbol
+var nativeSymbols = map[string]string{
+	"ethereum":  "ETH",
+	"polygon":   "MATIC",
+	"arbitrum":  "ETH",
+	"optimism":  "ETH",
+	"base":      "ETH",
+	"bsc":       "BNB",
+	"gnosis":    "xDAI",
+	"avalanche": "AVAX",
+}
+
+// wrappedNatives maps supported chains to their wrapped native token
+// contract, e.g. WETH on Ethereum
+var wrappedNatives = map[string]string{
+	"ethereum":  "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", // WETH
+	"polygon":   "0x0d500b1d8e8ef31e21c99d1db9a6444d3adf1270", // WMATIC
+	"arbitrum":  "0x82af49447d8a07e3bd95bd0d56f35241523fbab1", // WETH
+	"optimism":  "0x4200000000000000000000000000000000000006", // WETH
+	"base":      "0x4200000000000000000000000000000000000006", // WETH
+	"bsc":       "0xbb4cdb9cbd36b01bd1cbaebf2de08d9173bc095c", // WBNB
+	"gnosis":    "0xe91d153e0b41518a2ce8dd3d7944fa863463a97d", // WXDAI
+	"avalanche": "0xb31f66aa3c1e785363f0875a1b74e27b85fd66c7", // WAVAX
+}
+
+// NativeSymbol returns the native token symbol for a chain
+func NativeSymbol(chain string) (string, bool) {
+	symbol, ok := nativeSymbols[chain]
+	return symbol, ok
+}
+
+// WrappedNative returns the wrapped native token contract for a chain
+func WrappedNative(chain string) (string, bool) {
+	addr, ok := wrappedNatives[chain]
+	return addr, ok
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// busyBlock builds a block of about n transactions mixing the patterns
+// every detector looks for, listed in reverse transaction-index order as
+// some providers return them
+func busyBlock(tb testing.TB, n int) *Block {
+	swap := "0x" + hex.EncodeToString(encodeV2Swap(tb, milliEther(1000), milliEther(990), testWETH, testUSDC))
+	cyclic := "0x" + hex.EncodeToString(encodeV2Swap(tb, milliEther(1000), milliEther(1010), testWETH, testUSDC, testWETH))
+	batched := "0x" + hex.EncodeToString(encodeMulticall(tb, encodeSwap(tb), encodeSwap(tb)))
+	liquidation := "0x00a718a9" + fmt.Sprintf("%0320x", 1)
+
+	var txs []Transaction
+	add := func(from, to, value, gasPrice, input string) {
+		i := len(txs)
+		txs = append(txs, Transaction{
+			Hash:             fmt.Sprintf("0x%064x", i+1),
+			From:             from,
+			To:               to,
+			Value:            value,
+			GasPrice:         gasPrice,
+			GasUsed:          "0x30d40",
+			Input:            input,
+			TransactionIndex: fmt.Sprintf("0x%x", i),
+			Nonce:            fmt.Sprintf("0x%x", i),
+		})
+	}
+	for group := 0; len(txs) < n; group++ {
+		attacker := fmt.Sprintf("0x%040x", 0xa000+group)
+		user := fmt.Sprintf("0x%040x", 0xb000+group)
+		pool := fmt.Sprintf("0x%040x", 0xc000+group)
+
+		add("0x0000000000007f150bd6f54c40a34d7c3d5e9f56", pool, "0x0", "0x3b9aca00", "0x")
+		add(user, attacker, "0x3635c9adc5dea00000", "0x3b9aca00", "0x") // 1000 ETH
+		add(attacker, "", "0x0", "0x3b9aca00", cyclic)
+		add(attacker, pool, "0x0", "0xba43b7400", swap) // 50 gwei frontrun
+		add(user, pool, "0x0", "0x4a817c800", swap)     // 20 gwei victim
+		add(attacker, pool, "0x0", "0x2540be400", swap) // 10 gwei backrun
+		add(user, testAaveV3Pool, "0x0", "0x3b9aca00", liquidation)
+		add(user, attacker, "0x1", "0x3b9aca00", "0x")
+		add(user, "", "0x0", "0x3b9aca00", batched)
+	}
+
+	for i, j := 0, len(txs)-1; i < j; i, j = i+1, j-1 {
+		txs[i], txs[j] = txs[j], txs[i]
+	}
+	return &Block{Transactions: txs, BaseFeePerGas: "0x3b9aca00"}
+}
+
+func newBusyDetector() *MEVDetector {
+	d := NewMEVDetector(nil)
+	d.AddLiquidationTargets([]string{testAaveV3Pool}, []string{"0x00a718a9"})
+	return d
+}
+
+// runDetectorsSequentially is runDetectors without the goroutines, as a
+// baseline
+func runDetectorsSequentially(block *Block, detectors []func(block *Block) []MEVOpportunity) []MEVOpportunity {
+	var opportunities []MEVOpportunity
+	for _, detect := range detectors {
+		opportunities = append(opportunities, detect(block)...)
+	}
+	return opportunities
+}
+
+// TestDetectOpportunitiesOrderStable checks that concurrent detectors report
+// opportunities in detector order, identically on every run
+func TestDetectOpportunitiesOrderStable(t *testing.T) {
+	d := newBusyDetector()
+	block := busyBlock(t, 90)
+
+	ordered := *block
+	ordered.Transactions = orderedTransactions(block)
+	want := runDetectorsSequentially(&ordered, d.blockDetectors(100))
+
+	var types []string
+	for _, opp := range want {
+		if len(types) == 0 || types[len(types)-1] != opp.Type {
+			types = append(types, opp.Type)
+		}
+	}
+	wantTypes := []string{"known_bot", "high_value", "complex", "arbitrage", "liquidations", "sandwich"}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Fatalf("got types %v, want every detector in order %v", types, wantTypes)
+	}
+
+	for run := 0; run < 50; run++ {
+		got := d.DetectOpportunities(block, 100)
+		if len(got) != len(want) {
+			t.Fatalf("run %d: got %d opportunities, want %d", run, len(got), len(want))
+		}
+		for i := range got {
+			if got[i].Type != want[i].Type || !sameHashes(got[i].Transactions, want[i].Transactions) {
+				t.Fatalf("run %d: opportunity %d is %s %v, want %s %v", run, i,
+					got[i].Type, hashes(got[i].Transactions), want[i].Type, hashes(want[i].Transactions))
+			}
+		}
+	}
+}
+
+func hashes(txs []Transaction) []string {
+	out := make([]string, len(txs))
+	for i, tx := range txs {
+		out[i] = tx.Hash
+	}
+	return out
+}
+
+func sameHashes(a, b []Transaction) bool {
+	return reflect.DeepEqual(hashes(a), hashes(b))
+}
+
+// BenchmarkDetectors compares running the block detectors one after another
+// with running them concurrently, over a block of mainnet size
+func BenchmarkDetectors(b *testing.B) {
+	d := newBusyDetector()
+	block := busyBlock(b, 300)
+	block.Transactions = orderedTransactions(block)
+	detectors := d.blockDetectors(100)
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			runDetectorsSequentially(block, detectors)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			runDetectors(block, detectors)
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Detector thresholds
+const (
+	highValueThresholdETH = 10   // Minimum transfer value in ETH
+	complexInputLength    = 1000 // Minimum hex input length for "complex"
+	complexMinSwaps       = 2    // Minimum batched swaps for "complex"
+)
+
+// Detector confidence scores, from 0 to 1. Matches on signals specific to
+// MEV, such as a known bot sender or a sandwich pattern, score high; matches
+// on signals ordinary transactions also show score low.
+const (
+	knownBotConfidence    = 0.95
+	sandwichConfidence    = 0.9
+	liquidationConfidence = 0.9
+	arbitrageConfidence   = 0.8
+	highValueConfidence   = 0.3
+	complexConfidence     = 0.2
+)
+
+// DefaultArbitrageMinSwaps is the minimum number of swap hops for a cyclic
+// route to count as arbitrage; a single swap is never arbitrage
+const DefaultArbitrageMinSwaps = 2
+
+// DefaultValidatorMEVShare is the fraction of MEV transaction fees assumed
+// to reach the validator
+const DefaultValidatorMEVShare = 0.1
+
+// DefaultRewardCeiling is the per-block validator reward in native units above
+// which a computed reward is treated as implausible
+const DefaultRewardCeiling = 100.0
+
+// detectorLogicVersion is bumped whenever a detector change alters the
+// results of already analyzed blocks, so stored results are analyzed again
+const detectorLogicVersion = 1
+
+// ConfigVersion returns a short hash of everything that shapes a block's
+// analysis: the detector logic and the bots, labels and settings it runs
+// with. Stored results recorded under another version are stale.
+func (d *MEVDetector) ConfigVersion() string {
+	var bots []string
+	if d.KnownMEVBots != nil {
+		bots = d.KnownMEVBots.List()
+	}
+	// Maps marshal with sorted keys, so equal configurations hash equally
+	data, err := json.Marshal(struct {
+		Logic                int
+		Bots                 []string
+		Labels               Labels
+		CallDecodeDepth      int
+		ArbitrageMinSwaps    int
+		ValidatorMEVShare    float64
+		WrappedNative        string
+		LendingProtocols     map[string]bool
+		LiquidationSelectors map[string]bool
+	}{
+		detectorLogicVersion, bots, d.Labels, d.CallDecodeDepth, d.ArbitrageMinSwaps,
+		d.ValidatorMEVShare, d.WrappedNative, d.LendingProtocols, d.LiquidationSelectors,
+	})
+	if err != nil {
+		return "" // Unreachable: every field marshals
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// PlausibilityWarnings returns warnings when a block's computed reward looks
+// implausible, which usually points at misread gas or value fields
+func (d *MEVDetector) PlausibilityWarnings(reward float64) []string {
+	if d.RewardCeiling <= 0 || reward <= d.RewardCeiling {
+		return nil
+	}
+	return []string{
+		fmt.Sprintf("computed reward %.4f %s exceeds plausibility ceiling of %.4f %s; verify transaction gas and value fields",
+			reward, d.NativeSymbol, d.RewardCeiling, d.NativeSymbol),
+	}
+}
+
+// DetectorInfo describes a detector and the configuration it currently runs with
+type DetectorInfo struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Signals     []string           `json:"signals"`
+	Thresholds  map[string]float64 `json:"thresholds"`
+	Confidence  float64            `json:"confidence"` // Score assigned to this detector's opportunities
+	Enabled     bool               `json:"enabled"`
+}
+
+// FilterByConfidence returns the opportunities scoring at least minConfidence
+func FilterByConfidence(opportunities []MEVOpportunity, minConfidence float64) []MEVOpportunity {
+	filtered := make([]MEVOpportunity, 0, len(opportunities))
+	for _, opp := range opportunities {
+		if opp.Confidence >= minConfidence {
+			filtered = append(filtered, opp)
+		}
+	}
+	return filtered
+}
+
+// FilterResultByConfidence narrows result to the opportunities scoring at
+// least minConfidence and recomputes its reward from them. A minConfidence
+// of 0 or less returns result unchanged.
+func (d *MEVDetector) FilterResultByConfidence(result BlockMEVResult, minConfidence float64) BlockMEVResult {
+	if minConfidence <= 0 {
+		return result
+	}
+	result.Opportunities = FilterByConfidence(result.Opportunities, minConfidence)
+	result.ValidatorReward, result.SkippedTransactions = d.CalculateMEVReward(result.Opportunities)
+	return result
+}
+
+// FilterByType returns the opportunities of type oppType
+func FilterByType(opportunities []MEVOpportunity, oppType string) []MEVOpportunity {
+	filtered := make([]MEVOpportunity, 0, len(opportunities))
+	for _, opp := range opportunities {
+		if opp.Type == oppType {
+			filtered = append(filtered, opp)
+		}
+	}
+	return filtered
+}
+
+// Detectors returns metadata for every detector run by CheckMEV
+func (d *MEVDetector) Detectors() []DetectorInfo {
+	return []DetectorInfo{
+		{
+			Name:        "known_bot",
+			Description: "Transactions sent from addresses on the known MEV bot list",
+			Signals:     []string{"from"},
+			Thresholds: map[string]float64{
+				"knownBots": float64(d.KnownMEVBots.Len()),
+			},
+			Confidence: knownBotConfidence,
+			Enabled:    true,
+		},
+		{
+			Name:        "high_value",
+			Description: "Transactions transferring a large amount of ETH",
+			Signals:     []string{"value"},
+			Thresholds: map[string]float64{
+				"minValueEth": highValueThresholdETH,
+			},
+			Confidence: highValueConfidence,
+			Enabled:    true,
+		},
+		{
+			Name:        "complex",
+			Description: "Transactions with large calldata or several batched swaps, indicating potential arbitrage",
+			Signals:     []string{"input"},
+			Thresholds: map[string]float64{
+				"minInputLength":  complexInputLength,
+				"minSwaps":        complexMinSwaps,
+				"callDecodeDepth": float64(d.CallDecodeDepth),
+			},
+			Confidence: complexConfidence,
+			Enabled:    true,
+		},
+		{
+			Name:        "arbitrage",
+			Description: "Router swaps whose token route starts and ends on the same token",
+			Signals:     []string{"input", "value"},
+			Thresholds: map[string]float64{
+				"minSwaps":        float64(d.ArbitrageMinSwaps),
+				"callDecodeDepth": float64(d.CallDecodeDepth),
+			},
+			Confidence: arbitrageConfidence,
+			Enabled:    true,
+		},
+		{
+			Name:        "liquidations",
+			Description: "Liquidation calls made to configured lending-protocol contracts",
+			Signals:     []string{"to", "input"},
+			Thresholds: map[string]float64{
+				"lendingProtocols":     float64(len(d.LendingProtocols)),
+				"liquidationSelectors": float64(len(d.LiquidationSelectors)),
+			},
+			Confidence: liquidationConfidence,
+			Enabled:    len(d.LendingProtocols) > 0 && len(d.LiquidationSelectors) > 0,
+		},
+		{
+			Name:        "sandwich",
+			Description: "Frontrun and backrun transactions from one sender bracketing a victim on the same pool",
+			Signals:     []string{"from", "to", "gasPrice", "transactionIndex"},
+			Thresholds:  map[string]float64{},
+			Confidence:  sandwichConfidence,
+			Enabled:     true,
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+func TestConfigVersion(t *testing.T) {
+	detector := models.NewMEVDetector(nil)
+	version := detector.ConfigVersion()
+	if version == "" {
+		t.Fatal("ConfigVersion is empty")
+	}
+	if got := models.NewMEVDetector(nil).ConfigVersion(); got != version {
+		t.Errorf("equal configurations have versions %s and %s", version, got)
+	}
+
+	detector.KnownMEVBots.AddBot(userAddress)
+	withBot := detector.ConfigVersion()
+	if withBot == version {
+		t.Error("adding a bot did not change the version")
+	}
+
+	detector.KnownMEVBots.RemoveBot(userAddress)
+	if got := detector.ConfigVersion(); got != version {
+		t.Errorf("removing the bot gave version %s, want %s", got, version)
+	}
+
+	detector.ValidatorMEVShare = 0.5
+	if got := detector.ConfigVersion(); got == version {
+		t.Error("changing the validator share did not change the version")
+	}
+}
+
+func TestFilterResultByConfidence(t *testing.T) {
+	detector := models.NewMEVDetector(nil)
+	botTx := models.Transaction{Hash: "0x01", Value: "0x0", GasPrice: "0x3b9aca00"}
+	transferTx := models.Transaction{Hash: "0x02", Value: "0x0", GasPrice: "0x3b9aca00"}
+	result := models.BlockMEVResult{
+		BlockNumber: 100,
+		Opportunities: []models.MEVOpportunity{
+			{Type: "known_bot", Confidence: 0.95, Transactions: []models.Transaction{botTx}},
+			{Type: "high_value", Confidence: 0.3, Transactions: []models.Transaction{transferTx}},
+		},
+	}
+	result.ValidatorReward, result.SkippedTransactions = detector.CalculateMEVReward(result.Opportunities)
+
+	if got := detector.FilterResultByConfidence(result, 0); len(got.Opportunities) != 2 {
+		t.Errorf("threshold 0 kept %d opportunities, want 2", len(got.Opportunities))
+	}
+
+	got := detector.FilterResultByConfidence(result, 0.5)
+	if len(got.Opportunities) != 1 || got.Opportunities[0].Type != "known_bot" {
+		t.Fatalf("threshold 0.5 kept %+v, want only known_bot", got.Opportunities)
+	}
+	want, _ := detector.CalculateMEVReward(got.Opportunities)
+	if got.ValidatorReward != want {
+		t.Errorf("validator reward = %v, want %v", got.ValidatorReward, want)
+	}
+	if len(result.Opportunities) != 2 {
+		t.Error("filtering modified the original result")
+	}
+}
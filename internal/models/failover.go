@@ -0,0 +1,96 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/metrics"
+)
+
+// FailoverEndpoint is a named provider within a FailoverProvider. The name
+// labels metrics, so it should not contain credentials.
+type FailoverEndpoint struct {
+	Name     string
+	Provider RPCProvider
+}
+
+// FailoverProvider tries its endpoints in order and returns the first
+// successful response, so a single provider outage does not fail requests
+type FailoverProvider struct {
+	Endpoints []FailoverEndpoint
+}
+
+// NewFailoverProvider creates a provider that fails over across endpoints
+func NewFailoverProvider(endpoints ...FailoverEndpoint) *FailoverProvider {
+	return &FailoverProvider{Endpoints: endpoints}
+}
+
+// GetBlockByNumber retrieves a block from the first endpoint that succeeds
+func (f *FailoverProvider) GetBlockByNumber(ctx context.Context, blockNumber int, fullTransactions bool) (*Block, error) {
+	var block *Block
+	err := f.try(ctx, func(p RPCProvider) error {
+		var err error
+		block, err = p.GetBlockByNumber(ctx, blockNumber, fullTransactions)
+		return err
+	})
+	return block, err
+}
+
+// GetBlocksByNumber retrieves several blocks from the first endpoint that
+// succeeds, batching where the endpoint supports it
+func (f *FailoverProvider) GetBlocksByNumber(ctx context.Context, blockNumbers []int, fullTransactions bool) ([]*Block, error) {
+	var blocks []*Block
+	err := f.try(ctx, func(p RPCProvider) error {
+		var err error
+		blocks, err = getBlocks(ctx, p, blockNumbers, fullTransactions)
+		return err
+	})
+	return blocks, err
+}
+
+// BlockNumber returns the latest block number from the first endpoint that succeeds
+func (f *FailoverProvider) BlockNumber(ctx context.Context) (int, error) {
+	var blockNumber int
+	err := f.try(ctx, func(p RPCProvider) error {
+		var err error
+		blockNumber, err = p.BlockNumber(ctx)
+		return err
+	})
+	return blockNumber, err
+}
+
+// Call performs a JSON-RPC request against the first endpoint that succeeds
+func (f *FailoverProvider) Call(ctx context.Context, method string, params []interface{}, out interface{}) (bool, error) {
+	var found bool
+	err := f.try(ctx, func(p RPCProvider) error {
+		var err error
+		found, err = p.Call(ctx, method, params, out)
+		return err
+	})
+	return found, err
+}
+
+// try runs fn against each endpoint in order until one succeeds or ctx is
+// done, returning the errors of every failed attempt otherwise
+func (f *FailoverProvider) try(ctx context.Context, fn func(p RPCProvider) error) error {
+	if len(f.Endpoints) == 0 {
+		return fmt.Errorf("no RPC endpoints configured")
+	}
+
+	var errs []error
+	for _, endpoint := range f.Endpoints {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := fn(endpoint.Provider)
+		if err == nil {
+			metrics.RPCEndpointServed.WithLabelValues(endpoint.Name).Inc()
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", endpoint.Name, err))
+	}
+
+	return errors.Join(errs...)
+}
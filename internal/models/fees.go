@@ -0,0 +1,177 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/servertiming"
+)
+
+// Receipt represents the subset of a transaction receipt used for fee accounting
+type Receipt struct {
+	TransactionHash   string `json:"transactionHash"`
+	GasUsed           string `json:"gasUsed"`
+	EffectiveGasPrice string `json:"effectiveGasPrice"`
+}
+
+// BlockFeeBreakdown splits a block's fees into burned base fee, proposer
+// priority fee and estimated MEV, all denominated in ETH
+type BlockFeeBreakdown struct {
+	BlockNumber int
+	BaseFee     float64
+	PriorityFee float64
+	MEVReward   float64
+}
+
+// GetBlockReceipts retrieves all transaction receipts for a block
+func (d *MEVDetector) GetBlockReceipts(ctx context.Context, blockNumber int) ([]Receipt, error) {
+	var receipts []Receipt
+	found, err := d.call(ctx, "eth_getBlockReceipts", []interface{}{fmt.Sprintf("0x%x", blockNumber)}, &receipts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("empty receipts result")
+	}
+
+	return receipts, nil
+}
+
+// GetFeeBreakdown computes the base fee, priority fee and MEV split for a block
+func (d *MEVDetector) GetFeeBreakdown(ctx context.Context, blockNumber int) (*BlockFeeBreakdown, error) {
+	block, err := d.GetBlockData(ctx, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block data: %w", err)
+	}
+
+	receipts, err := d.GetBlockReceipts(ctx, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block receipts: %w", err)
+	}
+
+	start := time.Now()
+	burned, priority := splitFees(block, receipts)
+	opportunities := d.DetectOpportunities(block, blockNumber)
+	reward, _ := d.CalculateMEVReward(opportunities)
+	servertiming.Since(ctx, "detect", start)
+
+	return &BlockFeeBreakdown{
+		BlockNumber: blockNumber,
+		BaseFee:     WeiToEth(burned),
+		PriorityFee: WeiToEth(priority),
+		MEVReward:   reward,
+	}, nil
+}
+
+// effectiveGasPricesGwei returns the effective gas price, in gwei, of each
+// transaction whose receipt has been applied, skipping the rest
+func effectiveGasPricesGwei(txs []Transaction) []float64 {
+	prices := make([]float64, 0, len(txs))
+	for _, tx := range txs {
+		price, err := parseHexWei(tx.EffectiveGasPrice)
+		if err != nil {
+			continue
+		}
+		gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(price), weiPerGwei).Float64()
+		prices = append(prices, gwei)
+	}
+	return prices
+}
+
+// applyReceipts copies gas used and effective gas price from receipts onto
+// the block's transactions, which eth_getBlockByNumber does not return
+func applyReceipts(block *Block, receipts []Receipt) {
+	byHash := make(map[string]Receipt, len(receipts))
+	for _, r := range receipts {
+		byHash[strings.ToLower(r.TransactionHash)] = r
+	}
+
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		r, ok := byHash[strings.ToLower(tx.Hash)]
+		if !ok {
+			continue // Missing receipt data
+		}
+		tx.GasUsed = r.GasUsed
+		tx.EffectiveGasPrice = r.EffectiveGasPrice
+	}
+}
+
+// paidGasPrice returns the transaction's effective gas price when known from
+// its receipt, otherwise its declared gas price
+func paidGasPrice(tx Transaction) string {
+	if tx.EffectiveGasPrice != "" {
+		return tx.EffectiveGasPrice
+	}
+	return tx.GasPrice
+}
+
+// splitFees sums the burned base fee and the proposer priority fee across a
+// block. It also applies the receipts to the block's transactions so reward
+// calculation sees real values. Pre-London blocks have no base fee, so the
+// whole fee counts as priority fee.
+func splitFees(block *Block, receipts []Receipt) (burned, priority *big.Int) {
+	burned = new(big.Int)
+	priority = new(big.Int)
+
+	baseFee, err := parseHexWei(block.BaseFeePerGas)
+	if err != nil {
+		baseFee = new(big.Int)
+	}
+
+	applyReceipts(block, receipts)
+	for _, tx := range block.Transactions {
+		gasUsed, err := parseHexWei(tx.GasUsed)
+		if err != nil {
+			continue // Missing receipt data
+		}
+
+		gasPrice, err := parseHexWei(paidGasPrice(tx))
+		if err != nil {
+			continue
+		}
+
+		burned.Add(burned, new(big.Int).Mul(baseFee, gasUsed))
+
+		tip := new(big.Int).Sub(gasPrice, baseFee)
+		if tip.Sign() > 0 {
+			priority.Add(priority, tip.Mul(tip, gasUsed))
+		}
+	}
+
+	return burned, priority
+}
+
+// parseHexWei parses a 0x-prefixed hex quantity such as a wei amount,
+// returning an error for empty, unprefixed or malformed values
+func parseHexWei(s string) (*big.Int, error) {
+	digits, ok := strings.CutPrefix(s, "0x")
+	if !ok {
+		digits, ok = strings.CutPrefix(s, "0X")
+	}
+	if !ok || digits == "" {
+		return nil, fmt.Errorf("invalid hex quantity %q", s)
+	}
+
+	n, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid hex quantity %q", s)
+	}
+	return n, nil
+}
+
+// Unit divisors, shared read-only so conversions don't rebuild them
+var (
+	weiPerEth  = new(big.Float).SetInt(big.NewInt(1e18))
+	weiPerGwei = new(big.Float).SetInt(big.NewInt(1e9))
+)
+
+// WeiToEth converts a wei amount to ETH
+func WeiToEth(wei *big.Int) float64 {
+	eth, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), weiPerEth).Float64()
+	return eth
+}
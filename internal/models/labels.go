@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// AddressLabel names a known contract or account
+type AddressLabel struct {
+	Label    string `json:"label"`              // e.g. "Uniswap V2 Router"
+	Category string `json:"category,omitempty"` // e.g. "dex", "lending", "builder"
+}
+
+// Labels maps lowercased addresses to their labels
+type Labels map[string]AddressLabel
+
+// DefaultLabels returns labels for widely used Ethereum mainnet contracts
+func DefaultLabels() Labels {
+	return Labels{
+		"0x7a250d5630b4cf539739df2c5dacb4c659f2488d": {Label: "Uniswap V2 Router", Category: "dex"},
+		"0xe592427a0aece92de3edee1f18e0157c05861564": {Label: "Uniswap V3 Router", Category: "dex"},
+		"0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45": {Label: "Uniswap V3 Router 2", Category: "dex"},
+		"0x3fc91a3afd70395cd496c647d5a6cc9d4b2b7fad": {Label: "Uniswap Universal Router", Category: "dex"},
+		"0xd9e1ce17f2641f24ae83637ab66a2cca9c378b9f": {Label: "SushiSwap Router", Category: "dex"},
+		"0x1111111254eeb25477b68fb85ed929f73a960582": {Label: "1inch Aggregation Router V5", Category: "dex"},
+		"0x87870bca3f3fd6335c3f4ce8392d69350b4fa4e2": {Label: "Aave V3 Pool", Category: "lending"},
+		"0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9": {Label: "Aave V2 Lending Pool", Category: "lending"},
+		"0x3d9819210a31b4961b30ef54be2aed79b9c9cd3b": {Label: "Compound Comptroller", Category: "lending"},
+		"0x0000000000007f150bd6f54c40a34d7c3d5e9f56": {Label: "Flashbots Builder", Category: "builder"},
+		"0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5": {Label: "beaverbuild", Category: "builder"},
+		"0x4838b106fce9647bdf1e7877bf73ce8b0bad5f97": {Label: "Titan Builder", Category: "builder"},
+	}
+}
+
+// Lookup returns the label for addr, matching case-insensitively
+func (l Labels) Lookup(addr string) (AddressLabel, bool) {
+	addr, ok := NormalizeAddress(addr)
+	if !ok {
+		return AddressLabel{}, false
+	}
+	label, ok := l[addr]
+	return label, ok
+}
+
+// LoadLabels reads a JSON object mapping addresses to labels, e.g.
+// {"0x7a25...488d": {"label": "Uniswap V2 Router", "category": "dex"}}.
+// Addresses are lowercased.
+func LoadLabels(path string) (Labels, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read labels file: %w", err)
+	}
+
+	var entries map[string]AddressLabel
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse labels file: %w", err)
+	}
+
+	labels := make(Labels, len(entries))
+	for entry, label := range entries {
+		addr, ok := NormalizeAddress(entry)
+		if !ok {
+			return nil, fmt.Errorf("invalid address %q in labels file", entry)
+		}
+		if label.Label == "" {
+			return nil, fmt.Errorf("missing label for %s in labels file", entry)
+		}
+		labels[addr] = label
+	}
+
+	return labels, nil
+}
+
+// labelOpportunity sets the recipient label of each of opp's transactions
+// and lists the distinct labels involved
+func (l Labels) labelOpportunity(opp *MEVOpportunity) {
+	seen := make(map[string]bool)
+	for j, tx := range opp.Transactions {
+		label, ok := l.Lookup(tx.To)
+		if !ok {
+			continue
+		}
+		opp.Transactions[j].ToLabel = label.Label
+		if !seen[label.Label] {
+			seen[label.Label] = true
+			opp.Protocols = append(opp.Protocols, label.Label)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/abi"
+)
+
+// AddLiquidationTargets registers lending-protocol contracts and the
+// liquidation method selectors to match on them
+func (d *MEVDetector) AddLiquidationTargets(protocols, selectors []string) {
+	if d.LendingProtocols == nil {
+		d.LendingProtocols = make(map[string]bool)
+	}
+	if d.LiquidationSelectors == nil {
+		d.LiquidationSelectors = make(map[string]bool)
+	}
+
+	for _, addr := range protocols {
+		d.LendingProtocols[strings.ToLower(addr)] = true
+	}
+	for _, selector := range selectors {
+		d.LiquidationSelectors[strings.ToLower(selector)] = true
+	}
+}
+
+// detectLiquidations finds liquidation calls made directly to configured
+// lending-protocol contracts
+func (d *MEVDetector) detectLiquidations(block *Block) []Transaction {
+	if len(d.LendingProtocols) == 0 || len(d.LiquidationSelectors) == 0 {
+		return nil
+	}
+
+	var liquidationTxs []Transaction
+	for _, tx := range block.Transactions {
+		if !d.LendingProtocols[strings.ToLower(tx.To)] {
+			continue
+		}
+
+		selector, ok := abi.Selector(tx.Input)
+		if !ok {
+			continue
+		}
+		if d.LiquidationSelectors[selector] {
+			liquidationTxs = append(liquidationTxs, tx)
+		}
+	}
+	return liquidationTxs
+}
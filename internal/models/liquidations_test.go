@@ -0,0 +1,153 @@
+package models
+
+import (
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// Lending protocol contracts
+const (
+	testAaveV3Pool = "0x87870bca3f3fd6335c3f4ce8392d69350b4fa4e2"
+	testCUSDC      = "0x39aa39c021dfbae8fac545936693ac917d5e7563" // Compound cUSDC
+)
+
+// encodeLiquidationCall encodes Aave's liquidationCall(collateralAsset,
+// debtAsset, user, debtToCover, receiveAToken)
+func encodeLiquidationCall(t testing.TB, user string, debtToCover *big.Int) string {
+	data := mustHex(t, "00a718a9")
+	data = append(data, addressWord(t, testWETH)...)
+	data = append(data, addressWord(t, testUSDC)...)
+	data = append(data, addressWord(t, user)...)
+	data = append(data, debtToCover.FillBytes(make([]byte, 32))...)
+	data = append(data, abiUintWord(0)...) // receiveAToken = false
+	return "0x" + hex.EncodeToString(data)
+}
+
+// encodeSupply encodes Aave's supply(asset, amount, onBehalfOf, referralCode)
+func encodeSupply(t testing.TB) string {
+	data := mustHex(t, "617ba037")
+	data = append(data, addressWord(t, testUSDC)...)
+	data = append(data, abiUintWord(1000e6)...)
+	data = append(data, addressWord(t, "1111111111111111111111111111111111111111")...)
+	data = append(data, abiUintWord(0)...)
+	return "0x" + hex.EncodeToString(data)
+}
+
+func newLiquidationDetector() *MEVDetector {
+	d := NewMEVDetector(nil)
+	d.AddLiquidationTargets([]string{testAaveV3Pool}, []string{"0x00a718a9"})
+	return d
+}
+
+func TestDetectLiquidations(t *testing.T) {
+	liquidation := encodeLiquidationCall(t, "2222222222222222222222222222222222222222", big.NewInt(5000e6))
+
+	tests := []struct {
+		name string
+		txs  []Transaction
+		want []string // Hashes of the liquidation calls
+	}{
+		{
+			name: "liquidation call",
+			txs:  []Transaction{{Hash: "0x01", To: testAaveV3Pool, Input: liquidation}},
+			want: []string{"0x01"},
+		},
+		{
+			name: "checksummed address",
+			txs:  []Transaction{{Hash: "0x01", To: "0x87870Bca3F3fD6335C3F4ce8392D69350B4fA4E2", Input: liquidation}},
+			want: []string{"0x01"},
+		},
+		{
+			name: "unrelated call to the pool",
+			txs:  []Transaction{{Hash: "0x01", To: testAaveV3Pool, Input: encodeSupply(t)}},
+		},
+		{
+			name: "liquidation selector on another contract",
+			txs:  []Transaction{{Hash: "0x01", To: testPool, Input: liquidation}},
+		},
+		{
+			name: "plain transfer to the pool",
+			txs:  []Transaction{{Hash: "0x01", To: testAaveV3Pool, Value: "0x1", Input: "0x"}},
+		},
+		{
+			name: "truncated input",
+			txs:  []Transaction{{Hash: "0x01", To: testAaveV3Pool, Input: "0x00a7"}},
+		},
+		{
+			name: "among other calls",
+			txs: []Transaction{
+				{Hash: "0x01", To: testAaveV3Pool, Input: encodeSupply(t)},
+				{Hash: "0x02", To: testAaveV3Pool, Input: liquidation},
+				{Hash: "0x03", To: testPool, Input: "0x022c0d9f"},
+				{Hash: "0x04", To: testAaveV3Pool, Input: liquidation},
+			},
+			want: []string{"0x02", "0x04"},
+		},
+	}
+
+	d := newLiquidationDetector()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := d.detectLiquidations(&Block{Transactions: tt.txs})
+			if len(got) != len(tt.want) || len(got) > 0 && !reflect.DeepEqual(hashes(got), tt.want) {
+				t.Errorf("got %v, want %v", hashes(got), tt.want)
+			}
+		})
+	}
+}
+
+// TestDetectLiquidationsTargets checks that only configured protocols and
+// selectors match, and that nothing matches until both are configured
+func TestDetectLiquidationsTargets(t *testing.T) {
+	block := &Block{Transactions: []Transaction{
+		{Hash: "0x01", To: testAaveV3Pool, Input: encodeLiquidationCall(t, "2222222222222222222222222222222222222222", big.NewInt(1))},
+		{Hash: "0x02", To: testCUSDC, Input: "0xf5e3c462" + hex.EncodeToString(make([]byte, 96))}, // liquidateBorrow
+	}}
+
+	d := NewMEVDetector(nil)
+	if got := d.detectLiquidations(block); len(got) != 0 {
+		t.Errorf("unconfigured detector found %v", hashes(got))
+	}
+	d.AddLiquidationTargets([]string{testAaveV3Pool, testCUSDC}, nil)
+	if got := d.detectLiquidations(block); len(got) != 0 {
+		t.Errorf("detector without selectors found %v", hashes(got))
+	}
+	d.AddLiquidationTargets(nil, []string{"0x00A718A9"})
+	if got := hashes(d.detectLiquidations(block)); !reflect.DeepEqual(got, []string{"0x01"}) {
+		t.Errorf("got %v, want only the Aave liquidation", got)
+	}
+	d.AddLiquidationTargets(nil, []string{"0xf5e3c462"})
+	if got := hashes(d.detectLiquidations(block)); !reflect.DeepEqual(got, []string{"0x01", "0x02"}) {
+		t.Errorf("got %v, want both liquidations", got)
+	}
+}
+
+func TestDetectOpportunitiesLiquidation(t *testing.T) {
+	block := &Block{Transactions: []Transaction{
+		{Hash: "0x01", From: testVictim, To: testAaveV3Pool, Value: "0x0", GasPrice: "0x3b9aca00", Input: encodeSupply(t), TransactionIndex: "0x0"},
+		{Hash: "0x02", From: testAttacker, To: testAaveV3Pool, Value: "0x0", GasPrice: "0x3b9aca00", TransactionIndex: "0x1",
+			Input: encodeLiquidationCall(t, "2222222222222222222222222222222222222222", big.NewInt(5000e6))},
+	}}
+
+	var liquidations []MEVOpportunity
+	for _, opp := range newLiquidationDetector().DetectOpportunities(block, 100) {
+		if opp.Type == "liquidations" {
+			liquidations = append(liquidations, opp)
+		}
+	}
+	if len(liquidations) != 1 {
+		t.Fatalf("got %d liquidation opportunities, want 1", len(liquidations))
+	}
+	opp := liquidations[0]
+	if got := hashes(opp.Transactions); !reflect.DeepEqual(got, []string{"0x02"}) {
+		t.Errorf("got transactions %v, want the liquidation call", got)
+	}
+	if opp.Transactions[0].Method != "liquidationCall" {
+		t.Errorf("got method %q, want liquidationCall", opp.Transactions[0].Method)
+	}
+	if opp.Confidence != liquidationConfidence || opp.BlockNumber != 100 {
+		t.Errorf("got confidence %v block %d", opp.Confidence, opp.BlockNumber)
+	}
+}
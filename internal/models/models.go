@@ -2,12 +2,16 @@ package models
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"math/big"
-	"net/http"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/abi"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/metrics"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/servertiming"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/tracing"
 )
 
 type ErrorResponse struct {
@@ -15,43 +19,104 @@ type ErrorResponse struct {
 }
 
 type MEVOpportunitiesResponse struct {
-	BlockNumber              int              `json:"blockNumber"`
-	Opportunities            []MEVOpportunity `json:"opportunities"`
-	EstimatedValidatorReward float64          `json:"estimatedValidatorReward"`
-	Timestamp                time.Time        `json:"timestamp"`
+	BlockNumber              int                `json:"blockNumber"`
+	Opportunities            []MEVOpportunity   `json:"opportunities"`
+	EstimatedValidatorReward float64            `json:"estimatedValidatorReward"`
+	RewardByType             map[string]float64 `json:"rewardByType"`       // EstimatedValidatorReward split by opportunity type
+	ValueUSD                 *float64           `json:"valueUSD,omitempty"` // EstimatedValidatorReward in USD; set when currency=usd is requested
+	Warnings                 []string           `json:"warnings,omitempty"`
+	Currency                 string             `json:"currency"`
+	BlockTime                *time.Time         `json:"blockTime,omitempty"` // When the block was produced
+	Timestamp                time.Time          `json:"timestamp"`
 }
 
 type ValidatorMEVResponse struct {
-	ValidatorIndex int              `json:"validatorIndex"`
-	FromBlock      int              `json:"fromBlock"`
-	ToBlock        int              `json:"toBlock"`
-	TotalMEVReward float64          `json:"totalMEVReward"`
-	MEVBlocks      int              `json:"mevBlocks"`
-	TotalBlocks    int              `json:"totalBlocks"`
-	Blocks         []BlockMEVResult `json:"blocks"`
-	Timestamp      time.Time        `json:"timestamp"`
+	ValidatorIndex        int                  `json:"validatorIndex"`
+	FromBlock             int                  `json:"fromBlock"`
+	ToBlock               int                  `json:"toBlock"`
+	FeeRecipient          string               `json:"feeRecipient,omitempty"` // Set when blocks are attributed by fee recipient rather than beacon duties
+	TotalMEVReward        float64              `json:"totalMEVReward"`
+	RewardByType          map[string]float64   `json:"rewardByType"`                    // TotalMEVReward split by opportunity type
+	ValueUSD              *float64             `json:"valueUSD,omitempty"`              // TotalMEVReward in USD; set when currency=usd is requested
+	DuplicateTransactions int                  `json:"duplicateTransactions,omitempty"` // Transactions already counted earlier in the scan
+	MEVBlocks             int                  `json:"mevBlocks"`
+	TotalBlocks           int                  `json:"totalBlocks"`      // Blocks in fromBlock-toBlock
+	AnalyzedBlocks        int                  `json:"analyzedBlocks"`   // Blocks analyzed and attributed to the validator
+	Blocks                []BlockMEVResult     `json:"blocks,omitempty"` // Omitted from streamed summaries; one page when paginated
+	Page                  int                  `json:"page,omitempty"`   // Set when page or pageSize is requested
+	PageSize              int                  `json:"pageSize,omitempty"`
+	TotalPages            int                  `json:"totalPages,omitempty"`
+	FailedBlocks          []BlockError         `json:"failedBlocks,omitempty"` // Set when skipErrors is requested
+	GasPrices             *GasPricePercentiles `json:"gasPricePercentiles,omitempty"`
+	Currency              string               `json:"currency"`
+	Timestamp             time.Time            `json:"timestamp"`
+}
+
+// BlockError records a block that could not be analyzed during a scan
+type BlockError struct {
+	BlockNumber int    `json:"blockNumber"`
+	Error       string `json:"error"`
+}
+
+// GasPricePercentiles summarizes effective gas prices, in gwei, across the
+// transactions of a scanned range
+type GasPricePercentiles struct {
+	Samples       int     `json:"samples"`
+	MissingBlocks int     `json:"missingBlocks,omitempty"` // Blocks whose receipts could not be fetched
+	P10           float64 `json:"p10"`
+	P50           float64 `json:"p50"`
+	P90           float64 `json:"p90"`
+	P99           float64 `json:"p99"`
 }
 
 type BlockMEVResult struct {
-	BlockNumber     int              `json:"blockNumber"`
-	Opportunities   []MEVOpportunity `json:"opportunities"`
-	ValidatorReward float64          `json:"validatorReward"`
+	BlockNumber         int              `json:"blockNumber"`
+	Opportunities       []MEVOpportunity `json:"opportunities"`
+	ValidatorReward     float64          `json:"validatorReward"`
+	SkippedTransactions int              `json:"skippedTransactions,omitempty"` // Transactions with malformed gas fields
+	FeeRecipient        string           `json:"feeRecipient,omitempty"`        // Lowercased miner of the block
+	BlockTime           *time.Time       `json:"blockTime,omitempty"`           // When the block was produced; unset if its timestamp is malformed
+	Warnings            []string         `json:"warnings,omitempty"`
+
+	// Effective gas prices in gwei of the transactions with receipts, for
+	// scan-wide percentiles. Nil when unknown, as for results stored before
+	// they were recorded.
+	GasPrices []float64 `json:"-"`
+
+	// ConfigVersion of the detector that analyzed the block. Empty for
+	// results stored before versions were recorded.
+	DetectorVersion string `json:"-"`
 }
 
 type SimulationRequest struct {
-	ValidatorIndex int `json:"validatorIndex" binding:"required"`
-	BlockCount     int `json:"blockCount" binding:"required"`
+	ValidatorIndex int    `json:"validatorIndex" binding:"required"`
+	BlockCount     int    `json:"blockCount" binding:"required"`
+	Seed           *int64 `json:"seed,omitempty"`         // Makes the simulation reproducible
+	Runs           int    `json:"runs,omitempty"`         // Monte Carlo runs; defaults to 1
+	Distribution   string `json:"distribution,omitempty"` // "exponential" (default) or "lognormal"
+
+	// Custom reward distribution. When CustomAvgReward and
+	// CustomMEVProbability are set the simulation uses them instead of
+	// recent blocks, and simulated blocks are numbered from 1.
+	CustomAvgReward      *float64 `json:"customAvgReward,omitempty"`      // Mean reward of a block with MEV
+	CustomMEVProbability *float64 `json:"customMEVProbability,omitempty"` // Chance a block has MEV, in [0, 1]
+	CustomMaxReward      *float64 `json:"customMaxReward,omitempty"`      // Largest simulated block reward; uncapped if unset
 }
 
 type SimulationResponse struct {
-	ValidatorIndex      int              `json:"validatorIndex"`
-	SimulatedBlockCount int              `json:"simulatedBlockCount"`
-	TotalReward         float64          `json:"totalReward"`
-	AverageReward       float64          `json:"averageReward"`
-	BlocksWithMEV       int              `json:"blocksWithMEV"`
-	MEVProbability      float64          `json:"mevProbability"`
-	Blocks              []SimulatedBlock `json:"blocks"`
-	Timestamp           time.Time        `json:"timestamp"`
+	ValidatorIndex      int                `json:"validatorIndex"`
+	SimulatedBlockCount int                `json:"simulatedBlockCount"`
+	Runs                int                `json:"runs"`
+	TotalReward         float64            `json:"totalReward"`        // Mean across runs
+	ValueUSD            *float64           `json:"valueUSD,omitempty"` // TotalReward in USD; set when currency=usd is requested
+	AverageReward       float64            `json:"averageReward"`
+	BlocksWithMEV       float64            `json:"blocksWithMEV"` // Mean across runs
+	MEVProbability      float64            `json:"mevProbability"`
+	Percentiles         map[string]float64 `json:"percentiles"`        // Per-block reward percentiles across all runs
+	ConfidenceInterval  [2]float64         `json:"confidenceInterval"` // 95% interval of the total across runs
+	Blocks              []SimulatedBlock   `json:"blocks"`             // From the first run
+	Currency            string             `json:"currency"`
+	Timestamp           time.Time          `json:"timestamp"`
 }
 
 type SimulatedBlock struct {
@@ -60,169 +125,615 @@ type SimulatedBlock struct {
 	EstimatedReward float64 `json:"estimatedReward"`
 }
 
+type EpochPeerComparisonResponse struct {
+	ValidatorIndex int            `json:"validatorIndex"`
+	Epoch          int            `json:"epoch"`
+	MEVReward      float64        `json:"mevReward"`
+	Rank           int            `json:"rank"`
+	Percentile     float64        `json:"percentile"`
+	PeerCount      int            `json:"peerCount"`
+	Proposals      []PeerProposal `json:"proposals"`
+	Currency       string         `json:"currency"`
+	Timestamp      time.Time      `json:"timestamp"`
+}
+
+type ActualRewardsResponse struct {
+	ValidatorIndex int                `json:"validatorIndex"`
+	FromBlock      int                `json:"fromBlock"`
+	ToBlock        int                `json:"toBlock"`
+	FromSlot       int                `json:"fromSlot"`
+	ToSlot         int                `json:"toSlot"`
+	ProposerSlots  int                `json:"proposerSlots"` // Slots the validator was assigned
+	RelayBlocks    int                `json:"relayBlocks"`   // Slots with a relay-delivered payload
+	TotalReward    float64            `json:"totalReward"`
+	Payloads       []DeliveredPayload `json:"payloads"`
+	Currency       string             `json:"currency"`
+	Timestamp      time.Time          `json:"timestamp"`
+}
+
+// DeliveredPayload is a block a relay delivered to the proposer, with the
+// value actually paid for it
+type DeliveredPayload struct {
+	Slot          int     `json:"slot"`
+	BlockNumber   int     `json:"blockNumber"`
+	BlockHash     string  `json:"blockHash"`
+	Relay         string  `json:"relay"`
+	BuilderPubkey string  `json:"builderPubkey"`
+	FeeRecipient  string  `json:"feeRecipient"`
+	Value         float64 `json:"value"`
+}
+
+type PeerProposal struct {
+	ValidatorIndex int     `json:"validatorIndex"`
+	Slot           int     `json:"slot"`
+	BlockNumber    int     `json:"blockNumber,omitempty"`
+	MEVReward      float64 `json:"mevReward"`
+	Missed         bool    `json:"missed"`
+}
+
+type FeeBreakdownResponse struct {
+	FromBlock    int       `json:"fromBlock"`
+	ToBlock      int       `json:"toBlock"`
+	Blocks       []int     `json:"blocks"`
+	BaseFees     []float64 `json:"baseFees"`
+	PriorityFees []float64 `json:"priorityFees"`
+	MEVRewards   []float64 `json:"mevRewards"`
+	Currency     string    `json:"currency"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+type TopExtractorsResponse struct {
+	FromBlock  int         `json:"fromBlock"`
+	ToBlock    int         `json:"toBlock"`
+	Extractors []Extractor `json:"extractors"`
+	Currency   string      `json:"currency"`
+	Timestamp  time.Time   `json:"timestamp"`
+}
+
+// LeaderboardResponse ranks validators by estimated MEV reward over a block
+// range
+type LeaderboardResponse struct {
+	FromBlock          int                `json:"fromBlock"`
+	ToBlock            int                `json:"toBlock"`
+	Validators         []LeaderboardEntry `json:"validators"`
+	UnattributedBlocks int                `json:"unattributedBlocks"` // Blocks whose proposer is unknown
+	Currency           string             `json:"currency"`
+	Timestamp          time.Time          `json:"timestamp"`
+}
+
+// LeaderboardEntry is a validator's aggregated reward over a block range
+type LeaderboardEntry struct {
+	ValidatorIndex int     `json:"validatorIndex"`
+	TotalReward    float64 `json:"totalReward"`
+	ProposedBlocks int     `json:"proposedBlocks"`
+	MEVBlocks      int     `json:"mevBlocks"` // Proposed blocks with a positive reward
+}
+
+// MEVStatsResponse summarizes the distribution of per-block validator
+// rewards over a block range
+type MEVStatsResponse struct {
+	FromBlock   int           `json:"fromBlock"`
+	ToBlock     int           `json:"toBlock"`
+	TotalBlocks int           `json:"totalBlocks"`
+	MEVBlocks   int           `json:"mevBlocks"` // Blocks with a positive reward
+	Min         float64       `json:"min"`
+	Max         float64       `json:"max"`
+	Mean        float64       `json:"mean"`
+	Median      float64       `json:"median"`
+	StdDev      float64       `json:"stdDev"` // Population standard deviation
+	TopBlocks   []BlockReward `json:"topBlocks"`
+	Currency    string        `json:"currency"`
+	Timestamp   time.Time     `json:"timestamp"`
+}
+
+// MEVAnomaliesResponse lists the blocks whose reward exceeds the range mean by
+// more than Sigma standard deviations
+type MEVAnomaliesResponse struct {
+	FromBlock   int           `json:"fromBlock"`
+	ToBlock     int           `json:"toBlock"`
+	TotalBlocks int           `json:"totalBlocks"`
+	Sigma       float64       `json:"sigma"`
+	Mean        float64       `json:"mean"`
+	StdDev      float64       `json:"stdDev"`    // Population standard deviation
+	Threshold   float64       `json:"threshold"` // Mean + Sigma*StdDev
+	Anomalies   []BlockReward `json:"anomalies"` // Ordered by block number
+	Currency    string        `json:"currency"`
+	Timestamp   time.Time     `json:"timestamp"`
+}
+
+// MEVHistoryResponse lists stored results for a block range. Blocks the
+// scanner has not stored yet are reported in Gaps rather than analyzed.
+type MEVHistoryResponse struct {
+	FromBlock      int                `json:"fromBlock"`
+	ToBlock        int                `json:"toBlock"`
+	Type           string             `json:"type,omitempty"` // Opportunity type the blocks were filtered to
+	TotalMEVReward float64            `json:"totalMEVReward"`
+	RewardByType   map[string]float64 `json:"rewardByType"`
+	MEVBlocks      int                `json:"mevBlocks"`
+	ScannedBlocks  int                `json:"scannedBlocks"` // Blocks in the range with a stored result
+	TotalBlocks    int                `json:"totalBlocks"`
+	Complete       bool               `json:"complete"` // Every block in the range has been scanned
+	Gaps           []BlockRange       `json:"gaps"`     // Unscanned runs of blocks, ordered by block number
+	Blocks         []BlockMEVResult   `json:"blocks"`   // Stored blocks with opportunities, narrowed to Type when set
+	Currency       string             `json:"currency"`
+	Timestamp      time.Time          `json:"timestamp"`
+}
+
+// BlockRange is an inclusive run of blocks
+type BlockRange struct {
+	FromBlock int `json:"fromBlock"`
+	ToBlock   int `json:"toBlock"`
+}
+
+// BlockReward is a block's estimated validator reward
+type BlockReward struct {
+	BlockNumber int     `json:"blockNumber"`
+	Reward      float64 `json:"reward"`
+}
+
+// Extractor is an address's aggregated estimated MEV over a block range
+type Extractor struct {
+	Address      string  `json:"address"`
+	TotalProfit  float64 `json:"totalProfit"`
+	Blocks       int     `json:"blocks"`
+	Transactions int     `json:"transactions"`
+}
+
+type CalendarResponse struct {
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	Granularity  string           `json:"granularity"`
+	FromBlock    int              `json:"fromBlock"`
+	ToBlock      int              `json:"toBlock"`
+	StoredBlocks int              `json:"storedBlocks"` // Blocks read from storage rather than analyzed
+	Buckets      []CalendarBucket `json:"buckets"`      // Ordered by Start; buckets without blocks are omitted
+	Currency     string           `json:"currency"`
+	Timestamp    time.Time        `json:"timestamp"`
+}
+
+// CalendarBucket is the estimated MEV of the blocks mined in one hour, day
+// or week
+type CalendarBucket struct {
+	Start       time.Time `json:"start"`
+	Blocks      int       `json:"blocks"`
+	MEVBlocks   int       `json:"mevBlocks"`
+	TotalReward float64   `json:"totalReward"`
+}
+
+type BotsResponse struct {
+	Bots  []string `json:"bots"`
+	Count int      `json:"count"`
+}
+
+type AddBotRequest struct {
+	Address string `json:"address" binding:"required"`
+}
+
+type HealthResponse struct {
+	Status      string `json:"status"`
+	LatestBlock int    `json:"latestBlock,omitempty"`
+}
+
+type DetectorsResponse struct {
+	Detectors []DetectorInfo `json:"detectors"`
+}
+
+type ForecastResponse struct {
+	ValidatorIndex int              `json:"validatorIndex"`
+	Realized       RealizedRewards  `json:"realized"`
+	Forecast       ForecastedReward `json:"forecast"`
+	Currency       string           `json:"currency"`
+	Timestamp      time.Time        `json:"timestamp"`
+}
+
+type RealizedRewards struct {
+	FromBlock      int     `json:"fromBlock"`
+	ToBlock        int     `json:"toBlock"`
+	TotalReward    float64 `json:"totalReward"`
+	AverageReward  float64 `json:"averageReward"`
+	MEVBlocks      int     `json:"mevBlocks"`
+	MEVProbability float64 `json:"mevProbability"`
+	MaxReward      float64 `json:"maxReward"`
+}
+
+type ForecastedReward struct {
+	HorizonBlocks   int     `json:"horizonBlocks"`
+	Runs            int     `json:"runs"`
+	ExpectedReward  float64 `json:"expectedReward"`
+	LowerBound      float64 `json:"lowerBound"`
+	UpperBound      float64 `json:"upperBound"`
+	ConfidenceLevel float64 `json:"confidenceLevel"`
+}
+
+// BacktestResponse compares a simulation calibrated on the blocks before a
+// range with the rewards actually detected in it
+type BacktestResponse struct {
+	ValidatorIndex       int             `json:"validatorIndex"`
+	FromBlock            int             `json:"fromBlock"`
+	ToBlock              int             `json:"toBlock"`
+	CalibrationFromBlock int             `json:"calibrationFromBlock"` // Blocks the simulation's distribution was drawn from
+	CalibrationToBlock   int             `json:"calibrationToBlock"`
+	Distribution         string          `json:"distribution"`
+	Seed                 int64           `json:"seed"` // Reproduces the simulated series
+	Blocks               []BacktestBlock `json:"blocks"`
+	ActualTotal          float64         `json:"actualTotal"`
+	SimulatedTotal       float64         `json:"simulatedTotal"`
+	MAE                  float64         `json:"mae"`  // Mean absolute per-block error
+	RMSE                 float64         `json:"rmse"` // Root mean squared per-block error
+	Currency             string          `json:"currency"`
+	Timestamp            time.Time       `json:"timestamp"`
+}
+
+// BacktestBlock pairs a block's detected reward with its simulated one
+type BacktestBlock struct {
+	BlockNumber     int     `json:"blockNumber"`
+	ActualReward    float64 `json:"actualReward"`
+	SimulatedReward float64 `json:"simulatedReward"`
+}
+
+// GraphQLRequest is a GraphQL query sent over HTTP
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName,omitempty"` // Selects among several operations in Query
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse holds the selected fields of a GraphQL query and any
+// errors resolving them
+type GraphQLResponse struct {
+	Data   interface{}    `json:"data,omitempty"` // Selected fields by root field; absent when the query could not run
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// GraphQLError reports a failed query or field
+type GraphQLError struct {
+	Message string   `json:"message"`
+	Path    []string `json:"path,omitempty"` // Response key of the failed field
+}
+
+// BlockTransactionsResponse lists a block's transactions with the details
+// detectors act on
+type BlockTransactionsResponse struct {
+	BlockNumber  int                  `json:"blockNumber"`
+	Transactions []DecodedTransaction `json:"transactions"`
+	Currency     string               `json:"currency"`
+	Timestamp    time.Time            `json:"timestamp"`
+}
+
+// DecodedTransaction is a transaction enriched with its decoded call and
+// the detectors that flagged it
+type DecodedTransaction struct {
+	Transaction
+	Signature string   `json:"signature,omitempty"` // Method signature when the selector is known
+	Arguments []string `json:"arguments,omitempty"` // Decoded call arguments when the method's shape is supported
+	ValueEth  float64  `json:"valueEth"`
+	FlaggedBy []string `json:"flaggedBy"` // Types of the opportunities that include the transaction
+}
+
 // Block represents an Ethereum block with transactions
 type Block struct {
-	Number       string        `json:"number"`
-	Transactions []Transaction `json:"transactions"`
-	Timestamp    string        `json:"timestamp"`
+	Number        string        `json:"number"`
+	Transactions  []Transaction `json:"transactions"`
+	Timestamp     string        `json:"timestamp"`
+	BaseFeePerGas string        `json:"baseFeePerGas"`
+	Miner         string        `json:"miner"` // Fee recipient chosen by the proposer
 }
 
 // Transaction represents an Ethereum transaction
 type Transaction struct {
-	Hash     string `json:"hash"`
-	From     string `json:"from"`
-	To       string `json:"to"`
-	Value    string `json:"value"`
-	GasPrice string `json:"gasPrice"`
-	GasUsed  string `json:"gasUsed"`
-	Input    string `json:"input"`
+	Hash              string `json:"hash"`
+	From              string `json:"from"`
+	To                string `json:"to"`
+	FromName          string `json:"fromName,omitempty"` // Primary ENS name of From; set when names are resolved
+	ToName            string `json:"toName,omitempty"`   // Primary ENS name of To; set when names are resolved
+	ToLabel           string `json:"toLabel,omitempty"`  // Known protocol or builder at To
+	Value             string `json:"value"`
+	GasPrice          string `json:"gasPrice"`
+	GasUsed           string `json:"gasUsed"`
+	EffectiveGasPrice string `json:"effectiveGasPrice,omitempty"` // From the receipt
+	Input             string `json:"input"`
+	Method            string `json:"method,omitempty"` // Decoded from input when the selector is known
+	TransactionIndex  string `json:"transactionIndex"`
+	Nonce             string `json:"nonce"`
 }
 
 // MEVOpportunity represents a detected MEV opportunity
 type MEVOpportunity struct {
-	Type         string        `json:"type"` // "arbitrage", "liquidations", "sandwich"
-	Profit       float64       `json:"profit"`
-	Transactions []Transaction `json:"transactions"`
-	BlockNumber  int           `json:"blockNumber"`
+	Type          string        `json:"type"` // "arbitrage", "liquidations", "sandwich"
+	Profit        float64       `json:"profit"`
+	Transactions  []Transaction `json:"transactions"`
+	BlockNumber   int           `json:"blockNumber"`
+	BaseFeePerGas string        `json:"baseFeePerGas,omitempty"` // Empty for pre-London blocks
+	Confidence    float64       `json:"confidence"`              // 0-1 strength of the detector's signal
+	Protocols     []string      `json:"protocols,omitempty"`     // Labels of the known contracts the transactions call
+}
+
+// StatusUnconfirmed marks opportunities whose transactions are still pending
+const StatusUnconfirmed = "unconfirmed"
+
+// PendingOpportunity is an opportunity detected among pending transactions.
+// It has not been mined and may never be.
+type PendingOpportunity struct {
+	MEVOpportunity
+	Status    string    `json:"status"`    // Always "unconfirmed"
+	FirstSeen time.Time `json:"firstSeen"` // First poll that found it
+	LastSeen  time.Time `json:"lastSeen"`  // Latest poll that found it
+}
+
+// PendingMEVResponse lists the opportunities among currently pending
+// transactions
+type PendingMEVResponse struct {
+	Opportunities []PendingOpportunity `json:"opportunities"`
+	Count         int                  `json:"count"`
+	UpdatedAt     *time.Time           `json:"updatedAt,omitempty"` // When the pending transactions were last polled
+	Timestamp     time.Time            `json:"timestamp"`
 }
 
 // MEVDetector handles MEV detection logic
 type MEVDetector struct {
-	AlchemyAPIURL string
-	AlchemyAPIKey string
-	HttpClient    *http.Client
-	KnownMEVBots  map[string]bool // Known MEV bot addresses
+	Provider          RPCProvider
+	Archive           RPCProvider // Optional archive provider for historical requests
+	KnownMEVBots      *BotSet     // Known MEV bot addresses
+	Labels            Labels      // Known contract addresses, annotated on opportunities
+	CallDecodeDepth   int         // Levels of multicall wrappers to unwrap
+	NativeSymbol      string      // Native token symbol rewards are denominated in
+	WrappedNative     string      // Lowercased wrapped native token contract, used to value arbitrage profit
+	RewardCeiling     float64     // Per-block reward above which results are flagged
+	ArbitrageMinSwaps int         // Minimum swap hops for arbitrage classification
+	ValidatorMEVShare float64     // Fraction of MEV fees attributed to the validator
+
+	LendingProtocols     map[string]bool // Lending-protocol contracts watched for liquidations
+	LiquidationSelectors map[string]bool // Liquidation method selectors, e.g. liquidationCall
+
+	boundaryMu sync.Mutex
+	boundaries map[int64]int // BlockAtOrAfter results keyed by Unix nanoseconds
 }
 
-// NewMEVDetector creates a new MEV detector instance
-func NewMEVDetector(alchemyURL, alchemyKey string) *MEVDetector {
+// NewMEVDetector creates a new MEV detector instance reading from provider
+func NewMEVDetector(provider RPCProvider) *MEVDetector {
 	return &MEVDetector{
-		AlchemyAPIURL: alchemyURL,
-		AlchemyAPIKey: alchemyKey,
-		HttpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		KnownMEVBots: map[string]bool{
-			"0x0000000000007f150bd6f54c40a34d7c3d5e9f56": true, // Flashbots builder
+		Provider: provider,
+		KnownMEVBots: NewBotSet(
+			"0x0000000000007f150bd6f54c40a34d7c3d5e9f56", // Flashbots builder
 			// Add more known MEV bot addresses
-		},
+		),
+		Labels:            DefaultLabels(),
+		CallDecodeDepth:   DefaultCallDecodeDepth,
+		NativeSymbol:      nativeSymbols[DefaultChain],
+		WrappedNative:     wrappedNatives[DefaultChain],
+		RewardCeiling:     DefaultRewardCeiling,
+		ArbitrageMinSwaps: DefaultArbitrageMinSwaps,
+		ValidatorMEVShare: DefaultValidatorMEVShare,
+	}
+}
+
+// BlockNumber returns the latest block number from the provider
+func (d *MEVDetector) BlockNumber(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "eth_blockNumber", tracing.KindClient)
+	defer span.End()
+
+	blockNumber, err := d.Provider.BlockNumber(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
 	}
+	span.SetAttribute("block.number", blockNumber)
+	return blockNumber, nil
 }
 
-// GetBlockData retrieves block data from Alchemy
+// GetBlockData retrieves block data with full transactions from the provider
 func (d *MEVDetector) GetBlockData(ctx context.Context, blockNumber int) (*Block, error) {
-	url := fmt.Sprintf("%s/%s", d.AlchemyAPIURL, d.AlchemyAPIKey)
+	ctx, span := tracing.Start(ctx, "eth_getBlockByNumber", tracing.KindClient)
+	defer span.End()
+	span.SetAttribute("block.number", blockNumber)
+
+	var block *Block
+	err := d.withArchive(func(p RPCProvider) error {
+		var err error
+		block, err = p.GetBlockByNumber(ctx, blockNumber, true)
+		return err
+	})
+	span.RecordError(err)
+	return block, err
+}
 
-	payload := fmt.Sprintf(`{
-		"jsonrpc":"2.0",
-		"method":"eth_getBlockByNumber",
-		"params":["0x%x", true],
-		"id":1
-	}`, blockNumber)
+// GetBlocksData retrieves several blocks with full transactions, using a
+// single batched request when the provider supports it
+func (d *MEVDetector) GetBlocksData(ctx context.Context, blockNumbers []int) ([]*Block, error) {
+	ctx, span := tracing.Start(ctx, "eth_getBlockByNumber batch", tracing.KindClient)
+	defer span.End()
+	span.SetAttribute("block.count", len(blockNumbers))
+	if len(blockNumbers) > 0 {
+		span.SetAttributes(
+			tracing.Attribute{Key: "block.first", Value: blockNumbers[0]},
+			tracing.Attribute{Key: "block.last", Value: blockNumbers[len(blockNumbers)-1]},
+		)
+	}
+
+	var blocks []*Block
+	err := d.withArchive(func(p RPCProvider) error {
+		var err error
+		blocks, err = getBlocks(ctx, p, blockNumbers, true)
+		return err
+	})
+	span.RecordError(err)
+	return blocks, err
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(payload))
+// CheckMEV detects MEV opportunities in a block
+func (d *MEVDetector) CheckMEV(ctx context.Context, blockNumber int) ([]MEVOpportunity, error) {
+	block, err := d.GetBlockData(ctx, blockNumber)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to get block data: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := d.HttpClient.Do(req)
+	return d.CheckBlockMEV(ctx, block, blockNumber)
+}
+
+// AnalyzeBlock fetches a block, detects its MEV opportunities and summarizes
+// them as a result
+func (d *MEVDetector) AnalyzeBlock(ctx context.Context, blockNumber int) (BlockMEVResult, error) {
+	block, err := d.GetBlockData(ctx, blockNumber)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return BlockMEVResult{}, fmt.Errorf("failed to get block data: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	opps, err := d.CheckBlockMEV(ctx, block, blockNumber)
+	if err != nil {
+		return BlockMEVResult{}, err
 	}
 
-	var result struct {
-		Result *Block `json:"result"`
-		Error  struct {
-			Message string `json:"message"`
-		} `json:"error"`
+	return d.BlockResult(block, blockNumber, opps), nil
+}
+
+// BlockResult summarizes the opportunities detected in a block. Receipts
+// must already have been applied to the block, as CheckBlockMEV does.
+func (d *MEVDetector) BlockResult(block *Block, blockNumber int, opps []MEVOpportunity) BlockMEVResult {
+	reward, skipped := d.CalculateMEVReward(opps)
+	result := BlockMEVResult{
+		BlockNumber:         blockNumber,
+		Opportunities:       opps,
+		ValidatorReward:     reward,
+		SkippedTransactions: skipped,
+		FeeRecipient:        strings.ToLower(block.Miner),
+		GasPrices:           effectiveGasPricesGwei(block.Transactions),
+		DetectorVersion:     d.ConfigVersion(),
+	}
+	if blockTime, err := ParseBlockTimestamp(block.Timestamp); err == nil {
+		result.BlockTime = &blockTime
 	}
+	return result
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+// CheckBlockMEV detects MEV opportunities in an already fetched block
+func (d *MEVDetector) CheckBlockMEV(ctx context.Context, block *Block, blockNumber int) ([]MEVOpportunity, error) {
+	// Gas used is only available from receipts
+	receipts, err := d.GetBlockReceipts(ctx, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block receipts: %w", err)
 	}
+	applyReceipts(block, receipts)
 
-	if result.Error.Message != "" {
-		return nil, fmt.Errorf("API error: %s", result.Error.Message)
+	start := time.Now()
+	defer servertiming.Since(ctx, "detect", start)
+
+	opportunities := d.DetectOpportunities(block, blockNumber)
+	for _, opp := range opportunities {
+		metrics.Opportunities.WithLabelValues(opp.Type).Inc()
 	}
+	return opportunities, nil
+}
 
-	if result.Result == nil {
-		return nil, fmt.Errorf("empty block result")
+// DetectOpportunities runs all detectors over an already fetched block. The
+// detectors see its transactions in transaction-index order.
+func (d *MEVDetector) DetectOpportunities(block *Block, blockNumber int) []MEVOpportunity {
+	ordered := *block
+	ordered.Transactions = orderedTransactions(block)
+	block = &ordered
+
+	opportunities := runDetectors(block, d.blockDetectors(blockNumber))
+	for i := range opportunities {
+		opportunities[i].BaseFeePerGas = block.BaseFeePerGas
+		for j, tx := range opportunities[i].Transactions {
+			if _, name, ok := abi.DecodeMethod(tx.Input); ok {
+				opportunities[i].Transactions[j].Method = name
+			}
+		}
+		d.Labels.labelOpportunity(&opportunities[i])
 	}
 
-	return result.Result, nil
+	return opportunities
 }
 
-// CheckMEV detects MEV opportunities in a block
-func (d *MEVDetector) CheckMEV(ctx context.Context, blockNumber int) ([]MEVOpportunity, error) {
-	block, err := d.GetBlockData(ctx, blockNumber)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get block data: %w", err)
+// runDetectors runs detectors on block. They only read the block, so they
+// run concurrently; results are concatenated in detector order to keep the
+// output deterministic.
+func runDetectors(block *Block, detectors []func(block *Block) []MEVOpportunity) []MEVOpportunity {
+	results := make([][]MEVOpportunity, len(detectors))
+	var wg sync.WaitGroup
+	for i, detect := range detectors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = detect(block)
+		}()
 	}
+	wg.Wait()
 
 	var opportunities []MEVOpportunity
-
-	// Check for known MEV bots
-	botTxs := d.detectKnownBots(block)
-	if len(botTxs) > 0 {
-		opportunities = append(opportunities, MEVOpportunity{
-			Type:         "known_bot",
-			Transactions: botTxs,
-			BlockNumber:  blockNumber,
-		})
+	for _, result := range results {
+		opportunities = append(opportunities, result...)
 	}
+	return opportunities
+}
 
-	// Check for high-value transactions (potential MEV)
-	highValueTxs := d.detectHighValueTransactions(block)
-	if len(highValueTxs) > 0 {
-		opportunities = append(opportunities, MEVOpportunity{
-			Type:         "high_value",
-			Transactions: highValueTxs,
-			BlockNumber:  blockNumber,
-		})
+// blockDetectors returns the detectors run by DetectOpportunities, in the
+// order their opportunities are reported
+func (d *MEVDetector) blockDetectors(blockNumber int) []func(block *Block) []MEVOpportunity {
+	// single wraps a detector reporting one opportunity of its type
+	single := func(oppType string, confidence float64, detect func(block *Block) []Transaction) func(block *Block) []MEVOpportunity {
+		return func(block *Block) []MEVOpportunity {
+			txs := detect(block)
+			if len(txs) == 0 {
+				return nil
+			}
+			return []MEVOpportunity{{Type: oppType, Transactions: txs, BlockNumber: blockNumber, Confidence: confidence}}
+		}
 	}
 
-	// Check for complex transactions (potential arbitrage)
-	complexTxs := d.detectComplexTransactions(block)
-	if len(complexTxs) > 0 {
-		opportunities = append(opportunities, MEVOpportunity{
-			Type:         "complex",
-			Transactions: complexTxs,
-			BlockNumber:  blockNumber,
-		})
+	return []func(block *Block) []MEVOpportunity{
+		// Known MEV bots
+		single("known_bot", knownBotConfidence, d.detectKnownBots),
+		// High-value transactions (potential MEV)
+		single("high_value", highValueConfidence, d.detectHighValueTransactions),
+		// Complex transactions (potential arbitrage)
+		single("complex", complexConfidence, d.detectComplexTransactions),
+		// Cyclic router swaps (arbitrage)
+		func(block *Block) []MEVOpportunity {
+			arbTxs, arbProfit := d.detectArbitrage(block)
+			if len(arbTxs) == 0 {
+				return nil
+			}
+			return []MEVOpportunity{{Type: "arbitrage", Profit: arbProfit, Transactions: arbTxs, BlockNumber: blockNumber, Confidence: arbitrageConfidence}}
+		},
+		// Liquidations against lending protocols
+		single("liquidations", liquidationConfidence, d.detectLiquidations),
+		// Sandwich attacks
+		func(block *Block) []MEVOpportunity {
+			var opps []MEVOpportunity
+			for _, triple := range d.detectSandwich(block) {
+				opps = append(opps, MEVOpportunity{Type: "sandwich", Transactions: triple, BlockNumber: blockNumber, Confidence: sandwichConfidence})
+			}
+			return opps
+		},
 	}
-
-	return opportunities, nil
 }
 
 // detectKnownBots finds transactions from known MEV bots
 func (d *MEVDetector) detectKnownBots(block *Block) []Transaction {
 	var botTxs []Transaction
 	for _, tx := range block.Transactions {
-		if d.KnownMEVBots[strings.ToLower(tx.From)] {
+		if d.KnownMEVBots.IsKnownBot(tx.From) {
 			botTxs = append(botTxs, tx)
 		}
 	}
 	return botTxs
 }
 
+// highValueThresholdWei is highValueThresholdETH in wei, so values compare
+// without converting each one to ETH
+var highValueThresholdWei = new(big.Int).Mul(big.NewInt(highValueThresholdETH), big.NewInt(1e18))
+
 // detectHighValueTransactions finds high ETH value transactions
 func (d *MEVDetector) detectHighValueTransactions(block *Block) []Transaction {
 	var highValueTxs []Transaction
 	for _, tx := range block.Transactions {
-		value := new(big.Int)
-		value.SetString(tx.Value[2:], 16) // Remove 0x and parse as hex
-		ethValue := new(big.Float).Quo(
-			new(big.Float).SetInt(value),
-			new(big.Float).SetInt(big.NewInt(1e18)),
-		)
+		value, err := parseHexWei(tx.Value)
+		if err != nil {
+			continue // Skip malformed value
+		}
 
-		if ethValue.Cmp(big.NewFloat(10)) >= 0 { // 10 ETH threshold
+		if value.Cmp(highValueThresholdWei) >= 0 {
 			highValueTxs = append(highValueTxs, tx)
 		}
 	}
@@ -239,56 +750,91 @@ func (d *MEVDetector) detectComplexTransactions(block *Block) []Transaction {
 		}
 
 		// Check for multiple internal calls (indicated by complex input)
-		if len(tx.Input) > 1000 { // Arbitrary threshold for "complex"
+		if len(tx.Input) > complexInputLength {
+			complexTxs = append(complexTxs, tx)
+			continue
+		}
+
+		// Several swaps batched through a router wrapper are potential arbitrage
+		if d.countSwaps(tx.Input) >= complexMinSwaps {
 			complexTxs = append(complexTxs, tx)
 		}
 	}
 	return complexTxs
 }
 
-// CalculateMEVReward estimates the MEV reward for validators
-// CalculateMEVReward estimates the MEV reward for validators
-func (d *MEVDetector) CalculateMEVReward(opportunities []MEVOpportunity) float64 {
+// CalculateMEVReward estimates the MEV reward for validators from the
+// priority fees the proposer earned on MEV transactions. Post-London the
+// base fee is burned, so only (effectiveGasPrice - baseFee) * gasUsed is
+// counted; pre-London opportunities carry no base fee and count the full
+// fee. A transaction flagged by several detectors is counted once.
+// Transactions with missing or malformed gas fields are skipped and counted.
+func (d *MEVDetector) CalculateMEVReward(opportunities []MEVOpportunity) (float64, int) {
+	rewards, skipped := d.opportunityRewards(opportunities)
 	var total float64
-	for _, opp := range opportunities {
-		for _, tx := range opp.Transactions {
-			// Skip if gas fields are empty
-			if tx.GasPrice == "" || tx.GasUsed == "" {
-				continue
-			}
+	for _, reward := range rewards {
+		total += reward
+	}
+	return total, skipped
+}
 
-			// Safely parse gas price (hex string to big.Int)
-			gasPriceStr := strings.TrimPrefix(tx.GasPrice, "0x")
-			if gasPriceStr == "" {
-				gasPriceStr = "0"
-			}
+// RewardByType splits the reward CalculateMEVReward computes for
+// opportunities by opportunity type. A transaction flagged by several
+// detectors is attributed to the first opportunity listing it.
+func (d *MEVDetector) RewardByType(opportunities []MEVOpportunity) map[string]float64 {
+	rewards, _ := d.opportunityRewards(opportunities)
+	byType := make(map[string]float64)
+	for i, opp := range opportunities {
+		byType[opp.Type] += rewards[i]
+	}
+	return byType
+}
 
-			gasPrice := new(big.Int)
-			if _, ok := gasPrice.SetString(gasPriceStr, 16); !ok {
-				continue // Skip invalid gas price
-			}
+// opportunityRewards returns the validator reward from each opportunity,
+// counting each transaction hash only in the first opportunity it appears in,
+// and the number of transactions skipped for malformed gas fields
+func (d *MEVDetector) opportunityRewards(opportunities []MEVOpportunity) ([]float64, int) {
+	var (
+		rewards = make([]float64, len(opportunities))
+		counted = make(map[string]bool)
+		skipped int
+	)
+	tip := new(big.Int) // Reused across transactions
+	for i, opp := range opportunities {
+		baseFee, err := parseHexWei(opp.BaseFeePerGas)
+		if err != nil {
+			baseFee = new(big.Int) // Legacy pre-London block
+		}
 
-			// Safely parse gas used (hex string to big.Int)
-			gasUsedStr := strings.TrimPrefix(tx.GasUsed, "0x")
-			if gasUsedStr == "" {
-				gasUsedStr = "0"
+		for _, tx := range opp.Transactions {
+			if tx.Hash != "" {
+				hash := strings.ToLower(tx.Hash)
+				if counted[hash] {
+					continue
+				}
+				counted[hash] = true
 			}
 
-			gasUsed := new(big.Int)
-			if _, ok := gasUsed.SetString(gasUsedStr, 16); !ok {
-				continue // Skip invalid gas used
+			gasPrice, err := parseHexWei(paidGasPrice(tx))
+			if err != nil {
+				skipped++
+				continue
 			}
 
-			// Calculate tx fee: gasPrice * gasUsed
-			fee := new(big.Int).Mul(gasPrice, gasUsed)
-			feeEth := new(big.Float).Quo(
-				new(big.Float).SetInt(fee),
-				new(big.Float).SetInt(big.NewInt(1e18)),
-			)
+			gasUsed, err := parseHexWei(tx.GasUsed)
+			if err != nil {
+				skipped++
+				continue
+			}
 
-			feeFloat, _ := feeEth.Float64()
-			total += feeFloat * 0.1 // Assume validator gets 10% of MEV
+			// Calculate proposer tip: (gasPrice - baseFee) * gasUsed
+			tip.Sub(gasPrice, baseFee)
+			if tip.Sign() <= 0 {
+				continue
+			}
+			tip.Mul(tip, gasUsed)
+			rewards[i] += WeiToEth(tip) * d.ValidatorMEVShare
 		}
 	}
-	return total
+	return rewards, skipped
 }
@@ -0,0 +1,140 @@
+package models_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/testutil"
+)
+
+const (
+	flashbotsBuilder = "0x0000000000007f150bd6f54c40a34d7c3d5e9f56"
+	userAddress      = "0x1111111111111111111111111111111111111111"
+	poolAddress      = "0x2222222222222222222222222222222222222222"
+)
+
+func TestCheckMEV(t *testing.T) {
+	tests := []struct {
+		name      string
+		txs       []models.Transaction
+		wantTypes []string
+	}{
+		{
+			name: "known bot",
+			txs: []models.Transaction{
+				{Hash: "0x01", From: flashbotsBuilder, To: poolAddress, Value: "0x0", GasPrice: "0x3b9aca00", Input: "0x"},
+			},
+			wantTypes: []string{"known_bot"},
+		},
+		{
+			name: "high value transfer",
+			txs: []models.Transaction{
+				{Hash: "0x02", From: userAddress, To: poolAddress, Value: "0x3635c9adc5dea00000", GasPrice: "0x3b9aca00", Input: "0x"}, // 1000 ETH
+			},
+			wantTypes: []string{"high_value"},
+		},
+		{
+			name: "plain transfer",
+			txs: []models.Transaction{
+				{Hash: "0x03", From: userAddress, To: poolAddress, Value: "0x1", GasPrice: "0x3b9aca00", Input: "0x"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := testutil.NewRPCServer()
+			defer srv.Close()
+			srv.AddBlock(100, &models.Block{Transactions: tt.txs, BaseFeePerGas: "0x1"})
+
+			opps, err := srv.Detector().CheckMEV(context.Background(), 100)
+			if err != nil {
+				t.Fatalf("CheckMEV: %v", err)
+			}
+
+			var gotTypes []string
+			for _, opp := range opps {
+				gotTypes = append(gotTypes, opp.Type)
+				if opp.BlockNumber != 100 {
+					t.Errorf("opportunity %s has block number %d, want 100", opp.Type, opp.BlockNumber)
+				}
+			}
+			if len(gotTypes) != len(tt.wantTypes) {
+				t.Fatalf("got types %v, want %v", gotTypes, tt.wantTypes)
+			}
+			for i := range gotTypes {
+				if gotTypes[i] != tt.wantTypes[i] {
+					t.Fatalf("got types %v, want %v", gotTypes, tt.wantTypes)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckMEVAppliesReceipts(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	defer srv.Close()
+	srv.AddBlock(100, &models.Block{
+		BaseFeePerGas: "0x3b9aca00", // 1 gwei
+		Transactions: []models.Transaction{
+			{Hash: "0x01", From: flashbotsBuilder, To: poolAddress, Value: "0x0", GasPrice: "0x77359400", Input: "0x"},
+		},
+	})
+	srv.AddReceipts(100, []models.Receipt{
+		{TransactionHash: "0x01", GasUsed: "0x5208", EffectiveGasPrice: "0x77359400"}, // 21000 gas at 2 gwei
+	})
+
+	detector := srv.Detector()
+	opps, err := detector.CheckMEV(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("CheckMEV: %v", err)
+	}
+	if len(opps) != 1 || opps[0].Transactions[0].GasUsed != "0x5208" {
+		t.Fatalf("receipt gas used not applied: %+v", opps)
+	}
+
+	// The validator's share of 21000 gas at a 1 gwei priority fee
+	reward, skipped := detector.CalculateMEVReward(opps)
+	if want := 21000e-9 * models.DefaultValidatorMEVShare; skipped != 0 || reward < want*0.999 || reward > want*1.001 {
+		t.Errorf("CalculateMEVReward = %v (%d skipped), want %v", reward, skipped, want)
+	}
+}
+
+func TestCheckMEVErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(srv *testutil.RPCServer)
+	}{
+		{
+			name:  "missing block",
+			setup: func(srv *testutil.RPCServer) {},
+		},
+		{
+			name: "receipts error",
+			setup: func(srv *testutil.RPCServer) {
+				srv.AddBlock(100, &models.Block{})
+				srv.SetError("eth_getBlockReceipts", -32000, "receipts unavailable")
+			},
+		},
+		{
+			name: "server error",
+			setup: func(srv *testutil.RPCServer) {
+				srv.AddBlock(100, &models.Block{})
+				srv.SetStatus(500)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := testutil.NewRPCServer()
+			defer srv.Close()
+			tt.setup(srv)
+
+			if _, err := srv.Detector().CheckMEV(context.Background(), 100); err == nil {
+				t.Fatal("CheckMEV succeeded, want error")
+			}
+		})
+	}
+}
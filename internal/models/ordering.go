@@ -0,0 +1,35 @@
+package models
+
+import (
+	"math/big"
+	"sort"
+)
+
+// SortTransactions orders txs in place by decoded transaction index.
+// Transactions without a parsable index keep their relative position after
+// the indexed ones.
+func SortTransactions(txs []Transaction) {
+	indexes := make(map[string]*big.Int, len(txs))
+	for _, tx := range txs {
+		if index, err := parseHexWei(tx.TransactionIndex); err == nil {
+			indexes[tx.TransactionIndex] = index
+		}
+	}
+
+	sort.SliceStable(txs, func(a, b int) bool {
+		ia, ib := indexes[txs[a].TransactionIndex], indexes[txs[b].TransactionIndex]
+		if ia == nil || ib == nil {
+			return ia != nil && ib == nil
+		}
+		return ia.Cmp(ib) < 0
+	})
+}
+
+// orderedTransactions returns a copy of the block's transactions sorted by
+// transaction index, so detectors never rely on the JSON array order
+func orderedTransactions(block *Block) []Transaction {
+	txs := make([]Transaction, len(block.Transactions))
+	copy(txs, block.Transactions)
+	SortTransactions(txs)
+	return txs
+}
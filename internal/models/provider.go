@@ -0,0 +1,423 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/metrics"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/requestid"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/servertiming"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/throttle"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/tracing"
+)
+
+// Retry defaults for transient provider failures
+const (
+	DefaultMaxRetries     = 3
+	DefaultRetryBaseDelay = 250 * time.Millisecond
+	DefaultMaxRetryAfter  = 30 * time.Second
+)
+
+// Connection pool defaults. Go's default of two idle connections per host
+// would close and redial most connections during a concurrent scan.
+const (
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 32
+	DefaultIdleConnTimeout     = 90 * time.Second
+)
+
+// NewTransport returns an HTTP transport keeping up to maxIdlePerHost idle
+// connections to each host, and maxIdle overall, for idleTimeout
+func NewTransport(maxIdle, maxIdlePerHost int, idleTimeout time.Duration) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = maxIdle
+	t.MaxIdleConnsPerHost = maxIdlePerHost
+	t.IdleConnTimeout = idleTimeout
+	return t
+}
+
+// DefaultMaxResponseBytes caps a single JSON-RPC response body. Batches of
+// full blocks are the largest responses and stay well below it.
+const DefaultMaxResponseBytes = 256 << 20
+
+// RPCProvider is an execution-layer JSON-RPC endpoint the detector reads
+// chain data from
+type RPCProvider interface {
+	// GetBlockByNumber returns a block, with full transaction objects when
+	// fullTransactions is set
+	GetBlockByNumber(ctx context.Context, blockNumber int, fullTransactions bool) (*Block, error)
+
+	// BlockNumber returns the latest block number
+	BlockNumber(ctx context.Context) (int, error)
+
+	// Call performs an arbitrary JSON-RPC request and decodes the result
+	// into out, reporting false when the result was null
+	Call(ctx context.Context, method string, params []interface{}, out interface{}) (bool, error)
+}
+
+// BatchProvider is implemented by providers that can fetch several blocks
+// in one round-trip
+type BatchProvider interface {
+	GetBlocksByNumber(ctx context.Context, blockNumbers []int, fullTransactions bool) ([]*Block, error)
+}
+
+// getBlocks fetches blocks from p in a single batch when supported and one
+// at a time otherwise
+func getBlocks(ctx context.Context, p RPCProvider, blockNumbers []int, fullTransactions bool) ([]*Block, error) {
+	if batch, ok := p.(BatchProvider); ok {
+		return batch.GetBlocksByNumber(ctx, blockNumbers, fullTransactions)
+	}
+
+	blocks := make([]*Block, len(blockNumbers))
+	for i, blockNumber := range blockNumbers {
+		block, err := p.GetBlockByNumber(ctx, blockNumber, fullTransactions)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", blockNumber, err)
+		}
+		blocks[i] = block
+	}
+	return blocks, nil
+}
+
+// JSONRPCProvider is a plain JSON-RPC endpoint such as a self-hosted node
+// or an Infura project URL
+type JSONRPCProvider struct {
+	URL              string
+	HttpClient       *http.Client
+	MaxRetries       int           // Retries for transient provider failures
+	RetryBaseDelay   time.Duration // Initial backoff between retries
+	MaxRetryAfter    time.Duration // Longest Retry-After pause honored; longer ones fail immediately
+	MaxResponseBytes int64         // Larger responses fail rather than being buffered; 0 disables the cap
+}
+
+// NewJSONRPCProvider creates a provider for the JSON-RPC endpoint at url
+func NewJSONRPCProvider(url string) *JSONRPCProvider {
+	return &JSONRPCProvider{
+		URL: url,
+		HttpClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: NewTransport(DefaultMaxIdleConns, DefaultMaxIdleConnsPerHost, DefaultIdleConnTimeout),
+		},
+		MaxRetries:       DefaultMaxRetries,
+		RetryBaseDelay:   DefaultRetryBaseDelay,
+		MaxRetryAfter:    DefaultMaxRetryAfter,
+		MaxResponseBytes: DefaultMaxResponseBytes,
+	}
+}
+
+// DefaultAlchemyAPIVersion is the Alchemy API version used when none is set
+const DefaultAlchemyAPIVersion = "v2"
+
+// alchemyVersionSegment matches a version path segment such as v2
+var alchemyVersionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// AlchemyProvider is an Alchemy endpoint, addressed as
+// <api url>/<api version>/<api key>
+type AlchemyProvider struct {
+	*JSONRPCProvider
+	APIURL     string
+	APIVersion string
+	APIKey     string
+}
+
+// NewAlchemyProvider creates a provider for Alchemy's API with the given
+// version and key. An empty version uses DefaultAlchemyAPIVersion.
+func NewAlchemyProvider(apiURL, apiVersion, apiKey string) *AlchemyProvider {
+	if apiVersion == "" {
+		apiVersion = DefaultAlchemyAPIVersion
+	}
+	return &AlchemyProvider{
+		JSONRPCProvider: NewJSONRPCProvider(AlchemyURL(apiURL, apiVersion, apiKey)),
+		APIURL:          apiURL,
+		APIVersion:      apiVersion,
+		APIKey:          apiKey,
+	}
+}
+
+// AlchemyURL composes the endpoint for an Alchemy API key. An API URL that
+// already ends in a version segment, as in https://eth-mainnet.g.alchemy.com/v2,
+// has that segment replaced by apiVersion.
+func AlchemyURL(apiURL, apiVersion, apiKey string) string {
+	base := strings.TrimRight(apiURL, "/")
+	if i := strings.LastIndex(base, "/"); i >= 0 && alchemyVersionSegment.MatchString(base[i+1:]) {
+		base = base[:i]
+	}
+	return fmt.Sprintf("%s/%s/%s", base, strings.Trim(apiVersion, "/"), apiKey)
+}
+
+// GetBlockByNumber retrieves a block by number
+func (p *JSONRPCProvider) GetBlockByNumber(ctx context.Context, blockNumber int, fullTransactions bool) (*Block, error) {
+	var block Block
+	found, err := p.Call(ctx, "eth_getBlockByNumber", []interface{}{fmt.Sprintf("0x%x", blockNumber), fullTransactions}, &block)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("empty block result")
+	}
+
+	return &block, nil
+}
+
+// BlockNumber returns the latest block number
+func (p *JSONRPCProvider) BlockNumber(ctx context.Context) (int, error) {
+	var result string
+	found, err := p.Call(ctx, "eth_blockNumber", []interface{}{}, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	blockNumber, err := parseHexWei(result)
+	if !found || err != nil || !blockNumber.IsInt64() {
+		return 0, fmt.Errorf("failed to parse block number: %q", result)
+	}
+
+	return int(blockNumber.Int64()), nil
+}
+
+// Call performs a single JSON-RPC request and decodes the result into out.
+// It reports false when the provider returned a null result.
+func (p *JSONRPCProvider) Call(ctx context.Context, method string, params []interface{}, out interface{}) (bool, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+		"id":      1,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	metrics.RPCRequests.WithLabelValues(method).Inc()
+	result, err := p.post(ctx, payload)
+	if err == nil {
+		var resp rpcResponse
+		if err = json.Unmarshal(result, &resp); err != nil {
+			err = fmt.Errorf("failed to decode response: %w", err)
+		} else {
+			result, err = resp.result()
+		}
+	}
+	if err != nil {
+		metrics.RPCFailures.WithLabelValues(method).Inc()
+		return false, withRequestID(ctx, err)
+	}
+
+	if len(result) == 0 || string(result) == "null" {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(result, out); err != nil {
+		return false, fmt.Errorf("failed to decode result: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetBlocksByNumber retrieves several blocks in a single JSON-RPC batch
+// request, correlating responses to blocks by id. It fails if any block
+// could not be retrieved.
+func (p *JSONRPCProvider) GetBlocksByNumber(ctx context.Context, blockNumbers []int, fullTransactions bool) ([]*Block, error) {
+	if len(blockNumbers) == 0 {
+		return nil, nil
+	}
+
+	requests := make([]map[string]interface{}, len(blockNumbers))
+	for i, blockNumber := range blockNumbers {
+		requests[i] = map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "eth_getBlockByNumber",
+			"params":  []interface{}{fmt.Sprintf("0x%x", blockNumber), fullTransactions},
+			"id":      i,
+		}
+	}
+	payload, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	metrics.RPCRequests.WithLabelValues("eth_getBlockByNumber").Add(float64(len(blockNumbers)))
+	blocks, err := p.decodeBlockBatch(ctx, payload, blockNumbers)
+	if err != nil {
+		metrics.RPCFailures.WithLabelValues("eth_getBlockByNumber").Add(float64(len(blockNumbers)))
+		return nil, withRequestID(ctx, err)
+	}
+	return blocks, nil
+}
+
+// withRequestID prefixes err with the API request ID in ctx, if any, so
+// provider failures can be correlated with request logs
+func withRequestID(ctx context.Context, err error) error {
+	if id, ok := requestid.FromContext(ctx); ok {
+		return fmt.Errorf("request %s: %w", id, err)
+	}
+	return err
+}
+
+// decodeBlockBatch posts a batch of eth_getBlockByNumber requests and
+// decodes the responses in blockNumbers order
+func (p *JSONRPCProvider) decodeBlockBatch(ctx context.Context, payload []byte, blockNumbers []int) ([]*Block, error) {
+	body, err := p.post(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []rpcResponse
+	if err := json.Unmarshal(body, &responses); err != nil {
+		// Providers without batch support answer with a single error object
+		var single rpcResponse
+		if json.Unmarshal(body, &single) == nil {
+			if _, err := single.result(); err != nil {
+				return nil, err
+			}
+		}
+		return nil, fmt.Errorf("failed to decode batch response: %w", err)
+	}
+
+	blocks := make([]*Block, len(blockNumbers))
+	for _, resp := range responses {
+		if resp.ID < 0 || resp.ID >= len(blocks) {
+			return nil, fmt.Errorf("unexpected batch response id %d", resp.ID)
+		}
+
+		result, err := resp.result()
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", blockNumbers[resp.ID], err)
+		}
+		if len(result) == 0 || string(result) == "null" {
+			return nil, fmt.Errorf("block %d: empty block result", blockNumbers[resp.ID])
+		}
+
+		var block Block
+		if err := json.Unmarshal(result, &block); err != nil {
+			return nil, fmt.Errorf("block %d: failed to decode result: %w", blockNumbers[resp.ID], err)
+		}
+		blocks[resp.ID] = &block
+	}
+
+	for i, block := range blocks {
+		if block == nil {
+			return nil, fmt.Errorf("block %d: missing from batch response", blockNumbers[i])
+		}
+	}
+
+	return blocks, nil
+}
+
+// post sends a JSON-RPC payload to the endpoint and returns the raw response body. Network
+// errors and 429/5xx responses are retried up to MaxRetries times with
+// exponential backoff and jitter.
+func (p *JSONRPCProvider) post(ctx context.Context, payload []byte) (json.RawMessage, error) {
+	for attempt := 0; ; attempt++ {
+		result, retryable, err := p.postOnce(ctx, payload)
+		if err == nil || !retryable || attempt >= p.MaxRetries {
+			return result, err
+		}
+
+		// Honor the provider's requested pause when throttled, unless it is
+		// too long to wait out within a request
+		delay := backoff(p.RetryBaseDelay, attempt)
+		var rateLimited *RateLimitError
+		if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+			if rateLimited.RetryAfter > p.MaxRetryAfter {
+				return nil, err
+			}
+			delay = rateLimited.RetryAfter
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff returns the delay before retry attempt+1: base doubled per attempt
+// plus up to base of random jitter
+func backoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	return base<<attempt + rand.N(base)
+}
+
+// postOnce performs a single HTTP request and returns the response body. It
+// reports whether a failure is transient and worth retrying.
+func (p *JSONRPCProvider) postOnce(ctx context.Context, payload []byte) (json.RawMessage, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", p.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if id, ok := requestid.FromContext(ctx); ok {
+		req.Header.Set(requestid.Header, id)
+	}
+	tracing.Inject(ctx, req.Header)
+
+	start := time.Now()
+	defer servertiming.Since(ctx, "fetch", start)
+
+	resp, err := p.HttpClient.Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		throttle.Overloaded(ctx)
+		metrics.RPCRateLimited.Inc()
+		return nil, true, &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
+	}
+	if resp.StatusCode != http.StatusOK {
+		retryable := resp.StatusCode >= 500
+		if retryable {
+			throttle.Overloaded(ctx)
+		}
+		return nil, retryable, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Read one byte past the limit to tell a full-size body from a larger one
+	var reader io.Reader = resp.Body
+	if p.MaxResponseBytes > 0 {
+		reader = io.LimitReader(resp.Body, p.MaxResponseBytes+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, ctx.Err() == nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if p.MaxResponseBytes > 0 && int64(len(body)) > p.MaxResponseBytes {
+		return nil, false, fmt.Errorf("response exceeds %d bytes", p.MaxResponseBytes)
+	}
+
+	return body, false, nil
+}
+
+// rpcResponse is a single JSON-RPC response object
+type rpcResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// result returns the response's result, or its error normalized to an
+// RPCError, wrapped in ErrArchiveRequired when history has been pruned
+func (r rpcResponse) result() (json.RawMessage, error) {
+	if rpcErr := decodeRPCError(r.Error); rpcErr != nil {
+		if isArchiveError(rpcErr.Message) {
+			return nil, fmt.Errorf("%w: %w", ErrArchiveRequired, rpcErr)
+		}
+		return nil, rpcErr
+	}
+	return r.Result, nil
+}
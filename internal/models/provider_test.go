@@ -0,0 +1,130 @@
+package models_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/testutil"
+)
+
+// TestGetBlocksDataBatch checks that blocks are requested in one batch with
+// distinct ids and matched to responses by id rather than by position
+func TestGetBlocksDataBatch(t *testing.T) {
+	blockNumbers := []int{100, 101, 250}
+
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+		body, _ := io.ReadAll(r.Body)
+
+		var batch []struct {
+			JSONRPC string            `json:"jsonrpc"`
+			ID      json.RawMessage   `json:"id"`
+			Method  string            `json:"method"`
+			Params  []json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(body, &batch); err != nil {
+			t.Errorf("request is not a batch: %s", body)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if len(batch) != len(blockNumbers) {
+			t.Errorf("batch has %d requests, want %d", len(batch), len(blockNumbers))
+		}
+
+		ids := make(map[string]bool)
+		responses := make([]map[string]interface{}, len(batch))
+		for i, req := range batch {
+			if req.JSONRPC != "2.0" || req.Method != "eth_getBlockByNumber" || len(req.Params) != 2 {
+				t.Errorf("unexpected request %d: %+v", i, req)
+			}
+			if ids[string(req.ID)] {
+				t.Errorf("duplicate id %s", req.ID)
+			}
+			ids[string(req.ID)] = true
+
+			var number string
+			json.Unmarshal(req.Params[0], &number)
+			if want := fmt.Sprintf("0x%x", blockNumbers[i]); number != want {
+				t.Errorf("request %d asks for block %s, want %s", i, number, want)
+			}
+			if string(req.Params[1]) != "true" {
+				t.Errorf("request %d does not ask for full transactions", i)
+			}
+
+			// Answer in reverse order so only the ids tie blocks to requests
+			responses[len(batch)-1-i] = map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      req.ID,
+				"result":  models.Block{Number: number},
+			}
+		}
+		json.NewEncoder(w).Encode(responses)
+	}))
+	defer srv.Close()
+
+	provider := models.NewJSONRPCProvider(srv.URL)
+	provider.MaxRetries = 0
+	blocks, err := models.NewMEVDetector(provider).GetBlocksData(context.Background(), blockNumbers)
+	if err != nil {
+		t.Fatalf("GetBlocksData: %v", err)
+	}
+
+	if n := posts.Load(); n != 1 {
+		t.Errorf("made %d HTTP requests, want 1", n)
+	}
+	for i, block := range blocks {
+		if want := fmt.Sprintf("0x%x", blockNumbers[i]); block.Number != want {
+			t.Errorf("blocks[%d] is block %s, want %s", i, block.Number, want)
+		}
+	}
+}
+
+func TestGetBlocksDataBatchMissingBlock(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"jsonrpc":"2.0","id":0,"result":{"number":"0x1"}},{"jsonrpc":"2.0","id":1,"result":null}]`))
+	}))
+	defer srv.Close()
+
+	provider := models.NewJSONRPCProvider(srv.URL)
+	provider.MaxRetries = 0
+	if _, err := models.NewMEVDetector(provider).GetBlocksData(context.Background(), []int{1, 2}); err == nil {
+		t.Fatal("GetBlocksData succeeded with a missing block, want error")
+	}
+}
+
+func TestArchiveRequiredErrors(t *testing.T) {
+	tests := []struct {
+		message string
+		archive bool
+	}{
+		{message: "missing trie node 1a2b3c (path )", archive: true},
+		{message: "header not found", archive: true},
+		{message: "execution reverted: archive withdrawal failed", archive: false},
+		{message: "rate limited by upstream", archive: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			srv := testutil.NewRPCServer()
+			defer srv.Close()
+			srv.SetError("eth_getBlockByNumber", -32000, tt.message)
+
+			_, err := srv.Detector().GetBlockData(context.Background(), 100)
+			if err == nil {
+				t.Fatal("GetBlockData succeeded, want error")
+			}
+			if got := errors.Is(err, models.ErrArchiveRequired); got != tt.archive {
+				t.Errorf("errors.Is(%v, ErrArchiveRequired) = %v, want %v", err, got, tt.archive)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrArchiveRequired is returned when the provider cannot serve historical
+// data because it is not an archive node
+var ErrArchiveRequired = errors.New("archive node required for historical data; set blockchain.archive_url to an archive provider")
+
+// archiveErrorMessages are the error fragments full nodes return for
+// pruned history: "missing trie node" for state and "header not found" for
+// blocks beyond the retained range. Both come with the generic -32000 code,
+// so only the message tells them apart from other failures.
+var archiveErrorMessages = []string{
+	"missing trie node",
+	"header not found",
+}
+
+// isArchiveError reports whether a provider error means archive data is needed
+func isArchiveError(message string) bool {
+	message = strings.ToLower(message)
+	for _, fragment := range archiveErrorMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// call performs a single JSON-RPC request against the provider and decodes
+// the result into out. Requests needing archive data are retried against
+// the Archive provider when set. It reports false when the provider
+// returned a null result.
+func (d *MEVDetector) call(ctx context.Context, method string, params []interface{}, out interface{}) (bool, error) {
+	var found bool
+	err := d.withArchive(func(p RPCProvider) error {
+		var err error
+		found, err = p.Call(ctx, method, params, out)
+		return err
+	})
+	return found, err
+}
+
+// withArchive runs fn against the primary provider, repeating it against
+// the Archive provider when the primary has pruned the requested history
+func (d *MEVDetector) withArchive(fn func(p RPCProvider) error) error {
+	err := fn(d.Provider)
+	if errors.Is(err, ErrArchiveRequired) && d.Archive != nil {
+		err = fn(d.Archive)
+	}
+	return err
+}
+
+// RPCError is a JSON-RPC error returned by the provider. Code is zero when
+// the provider did not supply one.
+type RPCError struct {
+	Code    int
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("API error %d: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("API error: %s", e.Message)
+}
+
+// RateLimitError is returned when the provider throttles requests with HTTP
+// 429. RetryAfter is the pause the provider asked for, or zero if it did not
+// say.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited by provider, retry after %s", e.RetryAfter)
+	}
+	return "rate limited by provider"
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date, returning zero when it is missing or malformed
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
+// decodeRPCError normalizes the error member of a JSON-RPC response. Providers
+// send it as a plain string, as an object with optional code and message, or
+// wrap that object in a nested error member. It returns nil when there is no
+// error.
+func decodeRPCError(raw json.RawMessage) *RPCError {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var message string
+	if err := json.Unmarshal(raw, &message); err == nil {
+		if message == "" {
+			return nil
+		}
+		return &RPCError{Message: message}
+	}
+
+	var obj struct {
+		Code    json.Number     `json:"code"`
+		Message string          `json:"message"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return &RPCError{Message: string(raw)}
+	}
+
+	if obj.Message == "" && len(obj.Error) > 0 {
+		if nested := decodeRPCError(obj.Error); nested != nil {
+			return nested
+		}
+	}
+
+	code, _ := obj.Code.Int64()
+	if obj.Message == "" {
+		if code == 0 {
+			return nil
+		}
+		obj.Message = "unknown error"
+	}
+	return &RPCError{Code: int(code), Message: obj.Message}
+}
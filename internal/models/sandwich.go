@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+)
+
+// detectSandwich finds frontrun/victim/backrun triples: two transactions from
+// the same sender bracketing a victim that calls the same pool contract, with
+// the frontrun paying a higher gas price than the victim. Each triple is
+// returned in block order; block.Transactions must already be ordered by
+// transaction index.
+func (d *MEVDetector) detectSandwich(block *Block) [][]Transaction {
+	// Group transactions by the contract they call, preserving block order
+	byPool := make(map[string][]Transaction)
+	var pools []string
+	for _, tx := range block.Transactions {
+		if tx.To == "" || len(tx.Input) <= 2 {
+			continue
+		}
+		pool := strings.ToLower(tx.To)
+		if _, ok := byPool[pool]; !ok {
+			pools = append(pools, pool)
+		}
+		byPool[pool] = append(byPool[pool], tx)
+	}
+
+	var triples [][]Transaction
+	for _, pool := range pools {
+		calls := byPool[pool]
+		used := make(map[int]bool)
+
+		for i := 0; i < len(calls); i++ {
+			if used[i] {
+				continue
+			}
+			front := calls[i]
+			attacker := strings.ToLower(front.From)
+
+			frontGas, err := parseHexWei(front.GasPrice)
+			if err != nil {
+				continue
+			}
+
+		victims:
+			for j := i + 1; j < len(calls); j++ {
+				victim := calls[j]
+				if used[j] || strings.ToLower(victim.From) == attacker {
+					continue
+				}
+
+				victimGas, err := parseHexWei(victim.GasPrice)
+				if err != nil || frontGas.Cmp(victimGas) <= 0 {
+					continue
+				}
+
+				for k := j + 1; k < len(calls); k++ {
+					if used[k] || strings.ToLower(calls[k].From) != attacker {
+						continue
+					}
+					triples = append(triples, []Transaction{front, victim, calls[k]})
+					used[i], used[j], used[k] = true, true, true
+					break victims
+				}
+			}
+		}
+	}
+
+	return triples
+}
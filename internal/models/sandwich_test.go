@@ -0,0 +1,170 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const (
+	testAttacker = "0xae2fc483527b8ef99eb5d9b44875f005ba1fae13"
+	testVictim   = "0x1111111111111111111111111111111111111111"
+	testPool     = "0x88e6a0c2ddd26feeb64f039a2c41296fcb3f5640" // Uniswap V3 USDC/WETH
+	testPool2    = "0xb4e16d0168e52d35cacd2c6185b44281ec28c9dc" // Uniswap V2 USDC/WETH
+)
+
+// poolCall returns a swap call to pool at transaction index i paying gwei
+func poolCall(i int, from, pool string, gwei int) Transaction {
+	return Transaction{
+		Hash:             fmt.Sprintf("0x%02x", i),
+		From:             from,
+		To:               pool,
+		Value:            "0x0",
+		GasPrice:         fmt.Sprintf("0x%x", gwei*1e9),
+		Input:            "0x022c0d9f", // swap(uint256,uint256,address,bytes)
+		TransactionIndex: fmt.Sprintf("0x%x", i),
+	}
+}
+
+func TestDetectSandwich(t *testing.T) {
+	tests := []struct {
+		name string
+		txs  []Transaction
+		want [][]string // Hashes of each triple
+	}{
+		{
+			name: "known triple",
+			txs: []Transaction{
+				poolCall(0, testAttacker, testPool, 50),
+				poolCall(1, testVictim, testPool, 20),
+				poolCall(2, testAttacker, testPool, 10),
+			},
+			want: [][]string{{"0x00", "0x01", "0x02"}},
+		},
+		{
+			name: "interleaved unrelated calls",
+			txs: []Transaction{
+				poolCall(0, testAttacker, testPool, 50),
+				poolCall(1, testVictim, testPool2, 100),
+				{Hash: "0x02", From: testVictim, To: testPool, Value: "0x1", GasPrice: "0x1", Input: "0x"},
+				poolCall(3, testVictim, testPool, 20),
+				poolCall(4, testAttacker, testPool, 10),
+			},
+			want: [][]string{{"0x00", "0x03", "0x04"}},
+		},
+		{
+			name: "addresses differ in case",
+			txs: []Transaction{
+				poolCall(0, testAttacker, testPool, 50),
+				poolCall(1, testVictim, "0x88E6A0c2dDD26FEEb64F039a2c41296FcB3f5640", 20),
+				poolCall(2, "0xAE2Fc483527B8EF99EB5D9B44875F005ba1FaE13", testPool, 10),
+			},
+			want: [][]string{{"0x00", "0x01", "0x02"}},
+		},
+		{
+			name: "one sandwich per pool",
+			txs: []Transaction{
+				poolCall(0, testAttacker, testPool, 50),
+				poolCall(1, testAttacker, testPool2, 50),
+				poolCall(2, testVictim, testPool, 20),
+				poolCall(3, testVictim, testPool2, 20),
+				poolCall(4, testAttacker, testPool, 10),
+				poolCall(5, testAttacker, testPool2, 10),
+			},
+			want: [][]string{{"0x00", "0x02", "0x04"}, {"0x01", "0x03", "0x05"}},
+		},
+		{
+			name: "control block",
+			txs: []Transaction{
+				poolCall(0, testVictim, testPool, 20),
+				poolCall(1, testAttacker, testPool2, 30),
+				{Hash: "0x02", From: testAttacker, To: testVictim, Value: "0xde0b6b3a7640000", GasPrice: "0x3b9aca00", Input: "0x"},
+			},
+		},
+		{
+			name: "victim pays more than the frontrun",
+			txs: []Transaction{
+				poolCall(0, testAttacker, testPool, 20),
+				poolCall(1, testVictim, testPool, 50),
+				poolCall(2, testAttacker, testPool, 10),
+			},
+		},
+		{
+			name: "victim calls another pool",
+			txs: []Transaction{
+				poolCall(0, testAttacker, testPool, 50),
+				poolCall(1, testVictim, testPool2, 20),
+				poolCall(2, testAttacker, testPool, 10),
+			},
+		},
+		{
+			name: "no backrun",
+			txs: []Transaction{
+				poolCall(0, testAttacker, testPool, 50),
+				poolCall(1, testVictim, testPool, 20),
+				poolCall(2, testVictim, testPool, 10),
+			},
+		},
+		{
+			name: "same sender throughout",
+			txs: []Transaction{
+				poolCall(0, testAttacker, testPool, 50),
+				poolCall(1, testAttacker, testPool, 20),
+				poolCall(2, testAttacker, testPool, 10),
+			},
+		},
+		{
+			name: "malformed gas price",
+			txs: []Transaction{
+				{Hash: "0x00", From: testAttacker, To: testPool, GasPrice: "fast", Input: "0x022c0d9f"},
+				poolCall(1, testVictim, testPool, 20),
+				poolCall(2, testAttacker, testPool, 10),
+			},
+		},
+	}
+
+	d := NewMEVDetector(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, triple := range d.detectSandwich(&Block{Transactions: tt.txs}) {
+				got = append(got, hashes(triple))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got triples %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDetectOpportunitiesSandwichOutOfOrder checks that a sandwich is found
+// in transaction-index order when the provider lists the block otherwise
+func TestDetectOpportunitiesSandwichOutOfOrder(t *testing.T) {
+	front := poolCall(7, testAttacker, testPool, 50)
+	victim := poolCall(8, testVictim, testPool, 20)
+	back := poolCall(9, testAttacker, testPool, 10)
+
+	// Listed backrun first, the frontrun would follow its victim
+	block := &Block{Transactions: []Transaction{back, victim, front}}
+
+	var sandwiches []MEVOpportunity
+	for _, opp := range NewMEVDetector(nil).DetectOpportunities(block, 100) {
+		if opp.Type == "sandwich" {
+			sandwiches = append(sandwiches, opp)
+		}
+	}
+	if len(sandwiches) != 1 {
+		t.Fatalf("got %d sandwiches, want 1", len(sandwiches))
+	}
+	if got, want := hashes(sandwiches[0].Transactions), []string{"0x07", "0x08", "0x09"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got triple %v, want %v", got, want)
+	}
+	if sandwiches[0].Confidence != sandwichConfidence || sandwiches[0].BlockNumber != 100 {
+		t.Errorf("got confidence %v block %d", sandwiches[0].Confidence, sandwiches[0].BlockNumber)
+	}
+
+	// The provider's order is left alone
+	if block.Transactions[0].Hash != "0x09" {
+		t.Error("DetectOpportunities reordered the caller's block")
+	}
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxCachedBoundaries bounds the BlockAtOrAfter cache; it is cleared when full
+const maxCachedBoundaries = 10000
+
+// BlockTime returns the timestamp of a block without fetching its transactions
+func (d *MEVDetector) BlockTime(ctx context.Context, blockNumber int) (time.Time, error) {
+	var header *Block
+	err := d.withArchive(func(p RPCProvider) error {
+		var err error
+		header, err = p.GetBlockByNumber(ctx, blockNumber, false)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return ParseBlockTimestamp(header.Timestamp)
+}
+
+// ParseBlockTimestamp converts a block's hex Unix timestamp, e.g.
+// "0x5fc63a80", to a UTC time
+func ParseBlockTimestamp(timestamp string) (time.Time, error) {
+	seconds, err := parseHexWei(timestamp)
+	if err != nil || !seconds.IsInt64() {
+		return time.Time{}, fmt.Errorf("failed to parse block timestamp: %q", timestamp)
+	}
+
+	return time.Unix(seconds.Int64(), 0).UTC(), nil
+}
+
+// BlockByTimestamp returns the block whose timestamp is nearest t, preferring
+// the later block on a tie
+func (d *MEVDetector) BlockByTimestamp(ctx context.Context, t time.Time) (int, error) {
+	latest, err := d.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	n, err := d.BlockAtOrAfter(ctx, t, latest)
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	if n > latest {
+		return latest, nil
+	}
+
+	after, err := d.BlockTime(ctx, n)
+	if err != nil {
+		return 0, fmt.Errorf("block %d: %w", n, err)
+	}
+	before, err := d.BlockTime(ctx, n-1)
+	if err != nil {
+		return 0, fmt.Errorf("block %d: %w", n-1, err)
+	}
+	if t.Sub(before) < after.Sub(t) {
+		return n - 1, nil
+	}
+	return n, nil
+}
+
+// BlockAtOrAfter binary searches [0, latest] for the first block whose
+// timestamp is not before t. It returns latest+1 when every block is older.
+// Found blocks are cached, since block timestamps never change; latest+1 is
+// not, as a later block may yet satisfy the search.
+func (d *MEVDetector) BlockAtOrAfter(ctx context.Context, t time.Time, latest int) (int, error) {
+	key := t.UnixNano()
+	d.boundaryMu.Lock()
+	n, ok := d.boundaries[key]
+	d.boundaryMu.Unlock()
+	if ok && n <= latest {
+		return n, nil
+	}
+
+	n, err := d.searchBlockAtOrAfter(ctx, t, latest)
+	if err != nil || n > latest {
+		return n, err
+	}
+
+	d.boundaryMu.Lock()
+	defer d.boundaryMu.Unlock()
+	if d.boundaries == nil || len(d.boundaries) >= maxCachedBoundaries {
+		d.boundaries = make(map[int64]int)
+	}
+	d.boundaries[key] = n
+	return n, nil
+}
+
+func (d *MEVDetector) searchBlockAtOrAfter(ctx context.Context, t time.Time, latest int) (int, error) {
+	var searchErr error
+	n := sort.Search(latest+1, func(b int) bool {
+		if searchErr != nil {
+			return true
+		}
+		ts, err := d.BlockTime(ctx, b)
+		if err != nil {
+			searchErr = fmt.Errorf("block %d: %w", b, err)
+			return true
+		}
+		return !ts.Before(t)
+	})
+	if searchErr != nil {
+		return 0, searchErr
+	}
+
+	return n, nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/abi"
+)
+
+// DecodeTransactions returns a block's transactions in transaction-index
+// order, each with its decoded call, its value in ETH and the types of the
+// opportunities that include it
+func DecodeTransactions(block *Block, opportunities []MEVOpportunity) []DecodedTransaction {
+	flagged := make(map[string][]string)
+	for _, opp := range opportunities {
+		for _, tx := range opp.Transactions {
+			types := flagged[tx.Hash]
+			if len(types) == 0 || types[len(types)-1] != opp.Type {
+				flagged[tx.Hash] = append(types, opp.Type)
+			}
+		}
+	}
+
+	txs := orderedTransactions(block)
+	decoded := make([]DecodedTransaction, len(txs))
+	for i, tx := range txs {
+		decoded[i] = DecodedTransaction{Transaction: tx, FlaggedBy: flagged[tx.Hash]}
+		if decoded[i].FlaggedBy == nil {
+			decoded[i].FlaggedBy = []string{}
+		}
+
+		if selector, name, ok := abi.DecodeMethod(tx.Input); ok {
+			decoded[i].Method = name
+			if method, ok := abi.LookupMethod(selector); ok {
+				decoded[i].Signature = method.Signature
+			}
+			decoded[i].Arguments, _ = abi.DecodeArgs(tx.Input)
+		}
+		if value, err := parseHexWei(tx.Value); err == nil {
+			decoded[i].ValueEth = WeiToEth(value)
+		}
+	}
+	return decoded
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"context"
+	"fmt"
+)
+
+// warmupLookback is how far behind the latest block the second warmup
+// block is taken from
+const warmupLookback = 10
+
+// Warmup fetches the latest block and a recent one and checks the provider
+// returns the fields the detectors rely on. It returns a warning for each
+// missing capability and an error if the provider cannot be queried at all.
+func (d *MEVDetector) Warmup(ctx context.Context) ([]string, error) {
+	latest, err := d.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	var warnings []string
+	for _, blockNumber := range []int{latest, latest - warmupLookback} {
+		if blockNumber < 0 {
+			continue
+		}
+
+		block, err := d.GetBlockData(ctx, blockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block %d: %w", blockNumber, err)
+		}
+		warnings = append(warnings, checkBlockShape(blockNumber, block)...)
+
+		receipts, err := d.GetBlockReceipts(ctx, blockNumber)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("block %d: receipts unavailable (%v); rewards need per-transaction gas used", blockNumber, err))
+			continue
+		}
+		warnings = append(warnings, checkReceiptShape(blockNumber, receipts)...)
+	}
+
+	return warnings, nil
+}
+
+// checkBlockShape reports block fields the detectors need that are missing
+func checkBlockShape(blockNumber int, block *Block) []string {
+	var warnings []string
+	if _, err := parseHexWei(block.Number); err != nil {
+		warnings = append(warnings, fmt.Sprintf("block %d: missing block number", blockNumber))
+	}
+	if _, err := parseHexWei(block.Timestamp); err != nil {
+		warnings = append(warnings, fmt.Sprintf("block %d: missing timestamp; calendar aggregation will fail", blockNumber))
+	}
+	if _, err := parseHexWei(block.BaseFeePerGas); err != nil {
+		warnings = append(warnings, fmt.Sprintf("block %d: missing baseFeePerGas; rewards fall back to legacy full-fee accounting", blockNumber))
+	}
+
+	if len(block.Transactions) == 0 {
+		return warnings
+	}
+	tx := block.Transactions[0]
+	if tx.Hash == "" || tx.From == "" {
+		warnings = append(warnings, fmt.Sprintf("block %d: transactions are missing hash or sender; full transaction objects are required", blockNumber))
+	}
+	if _, err := parseHexWei(tx.TransactionIndex); err != nil {
+		warnings = append(warnings, fmt.Sprintf("block %d: missing transactionIndex; sandwich detection relies on block order", blockNumber))
+	}
+	if _, err := parseHexWei(tx.GasPrice); err != nil {
+		warnings = append(warnings, fmt.Sprintf("block %d: missing gasPrice", blockNumber))
+	}
+	return warnings
+}
+
+// checkReceiptShape reports receipt fields reward calculation needs that are
+// missing
+func checkReceiptShape(blockNumber int, receipts []Receipt) []string {
+	if len(receipts) == 0 {
+		return nil
+	}
+
+	var warnings []string
+	r := receipts[0]
+	if _, err := parseHexWei(r.GasUsed); err != nil {
+		warnings = append(warnings, fmt.Sprintf("block %d: receipts are missing gasUsed; rewards cannot be computed", blockNumber))
+	}
+	if _, err := parseHexWei(r.EffectiveGasPrice); err != nil {
+		warnings = append(warnings, fmt.Sprintf("block %d: receipts are missing effectiveGasPrice; declared gas price is used instead", blockNumber))
+	}
+	return warnings
+}
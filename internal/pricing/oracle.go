@@ -0,0 +1,125 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/servertiming"
+)
+
+// Defaults for the CoinGecko oracle
+const (
+	DefaultCoinGeckoURL = "https://api.coingecko.com/api/v3"
+	DefaultTTL          = time.Minute
+)
+
+// PriceOracle returns the USD price of a native token by symbol
+type PriceOracle interface {
+	USDPrice(ctx context.Context, symbol string) (float64, error)
+}
+
+// coinGeckoIDs maps native symbols to CoinGecko coin ids
+var coinGeckoIDs = map[string]string{
+	"ETH":   "ethereum",
+	"MATIC": "matic-network",
+	"BNB":   "binancecoin",
+	"xDAI":  "xdai",
+	"AVAX":  "avalanche-2",
+}
+
+type cachedPrice struct {
+	price     float64
+	fetchedAt time.Time
+}
+
+// CoinGecko is a PriceOracle backed by CoinGecko's simple price API. Prices
+// are cached in memory for TTL.
+type CoinGecko struct {
+	BaseURL    string
+	HttpClient *http.Client
+	TTL        time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedPrice
+}
+
+// NewCoinGecko creates an oracle for the CoinGecko API at baseURL
+func NewCoinGecko(baseURL string) *CoinGecko {
+	return &CoinGecko{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		HttpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+		TTL:   DefaultTTL,
+		cache: make(map[string]cachedPrice),
+	}
+}
+
+// USDPrice returns the USD price of symbol, served from the cache while fresh
+func (o *CoinGecko) USDPrice(ctx context.Context, symbol string) (float64, error) {
+	id, ok := coinGeckoIDs[symbol]
+	if !ok {
+		return 0, fmt.Errorf("no price source for %s", symbol)
+	}
+
+	o.mu.Lock()
+	cached, ok := o.cache[id]
+	o.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < o.TTL {
+		return cached.price, nil
+	}
+
+	price, err := o.fetch(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	o.mu.Lock()
+	o.cache[id] = cachedPrice{price: price, fetchedAt: time.Now()}
+	o.mu.Unlock()
+
+	return price, nil
+}
+
+// fetch requests the current USD price of a CoinGecko coin
+func (o *CoinGecko) fetch(ctx context.Context, id string) (float64, error) {
+	endpoint := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=usd", o.BaseURL, url.QueryEscape(id))
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	defer servertiming.Since(ctx, "price", start)
+
+	resp, err := o.HttpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result map[string]struct {
+		USD *float64 `json:"usd"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	price := result[id].USD
+	if price == nil || *price <= 0 {
+		return 0, fmt.Errorf("no USD price for %s", id)
+	}
+
+	return *price, nil
+}
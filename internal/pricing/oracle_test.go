@@ -0,0 +1,116 @@
+package pricing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// priceServer is a fake simple price API answering with body and status
+type priceServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	body     string
+	requests []string // Query strings
+}
+
+func newPriceServer(t *testing.T, body string) *priceServer {
+	s := &priceServer{status: http.StatusOK, body: body}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.URL.Path != "/api/v3/simple/price" {
+			http.NotFound(w, r)
+			return
+		}
+		s.requests = append(s.requests, r.URL.RawQuery)
+		w.WriteHeader(s.status)
+		w.Write([]byte(s.body)) //nolint:errcheck
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *priceServer) set(status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status, s.body = status, body
+}
+
+func (s *priceServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+func (s *priceServer) oracle() *CoinGecko {
+	o := NewCoinGecko(s.URL + "/api/v3/")
+	o.HttpClient = s.Client()
+	return o
+}
+
+func TestCoinGeckoUSDPrice(t *testing.T) {
+	s := newPriceServer(t, `{"ethereum": {"usd": 3150.25}}`)
+	o := s.oracle()
+
+	price, err := o.USDPrice(context.Background(), "ETH")
+	if err != nil || price != 3150.25 {
+		t.Fatalf("got %v, %v; want 3150.25", price, err)
+	}
+	if q := s.requests[0]; q != "ids=ethereum&vs_currencies=usd" {
+		t.Errorf("got query %q", q)
+	}
+
+	// Served from the cache while fresh
+	s.set(http.StatusOK, `{"ethereum": {"usd": 9999}}`)
+	if price, _ := o.USDPrice(context.Background(), "ETH"); price != 3150.25 || s.requestCount() != 1 {
+		t.Errorf("got %v after %d requests, want the cached price", price, s.requestCount())
+	}
+
+	// Refetched once expired
+	o.TTL = 0
+	if price, _ := o.USDPrice(context.Background(), "ETH"); price != 9999 || s.requestCount() != 2 {
+		t.Errorf("got %v after %d requests, want a fresh price", price, s.requestCount())
+	}
+}
+
+func TestCoinGeckoUSDPriceErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		symbol string
+		status int
+		body   string
+		want   string
+	}{
+		{"unknown symbol", "DOGE", http.StatusOK, `{}`, "no price source for DOGE"},
+		{"rate limited", "ETH", http.StatusTooManyRequests, `{"status": {"error_code": 429}}`, "unexpected status code: 429"},
+		{"malformed body", "ETH", http.StatusOK, `{"ethereum":`, "failed to decode response"},
+		{"missing coin", "MATIC", http.StatusOK, `{"ethereum": {"usd": 3150.25}}`, "no USD price for matic-network"},
+		{"missing usd", "ETH", http.StatusOK, `{"ethereum": {"eur": 2900}}`, "no USD price for ethereum"},
+		{"zero price", "ETH", http.StatusOK, `{"ethereum": {"usd": 0}}`, "no USD price for ethereum"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newPriceServer(t, tt.body)
+			s.set(tt.status, tt.body)
+			o := s.oracle()
+
+			price, err := o.USDPrice(context.Background(), tt.symbol)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, %v; want an error containing %q", price, err, tt.want)
+			}
+
+			// Failures are not cached
+			s.set(http.StatusOK, `{"ethereum": {"usd": 1}, "matic-network": {"usd": 1}}`)
+			if _, err := o.USDPrice(context.Background(), tt.symbol); err != nil && tt.symbol != "DOGE" {
+				t.Errorf("got %v after the API recovered", err)
+			}
+		})
+	}
+}
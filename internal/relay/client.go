@@ -0,0 +1,148 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/servertiming"
+)
+
+// ErrNoPayload is returned when no relay delivered a payload for a slot, as
+// when the block was built locally or the slot was missed
+var ErrNoPayload = errors.New("no relay delivered a payload for slot")
+
+// RelayPayload is an execution payload a relay delivered to a proposer
+type RelayPayload struct {
+	Relay                string
+	Slot                 int
+	BlockNumber          int
+	BlockHash            string
+	BuilderPubkey        string
+	ProposerFeeRecipient string
+	Value                *big.Int // Wei paid to the proposer
+}
+
+// Client queries the data API of one or more MEV-Boost relays
+type Client struct {
+	URLs       []string
+	HttpClient *http.Client
+}
+
+// NewClient creates a client for the relays at urls
+func NewClient(urls []string) *Client {
+	trimmed := make([]string, len(urls))
+	for i, url := range urls {
+		trimmed[i] = strings.TrimRight(url, "/")
+	}
+
+	return &Client{
+		URLs: trimmed,
+		HttpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// GetDeliveredPayload returns the payload delivered for a slot by the first
+// relay that reports one. ErrNoPayload is returned when every relay answered
+// without one; if any relay failed, the errors are returned instead since the
+// payload may have come from it.
+func (c *Client) GetDeliveredPayload(ctx context.Context, slot int) (*RelayPayload, error) {
+	var errs []error
+	for _, url := range c.URLs {
+		payload, err := c.deliveredPayload(ctx, url, slot)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+			continue
+		}
+		if payload != nil {
+			return payload, nil
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return nil, ErrNoPayload
+}
+
+// deliveredPayload queries a single relay, returning nil when it delivered no
+// payload for the slot
+func (c *Client) deliveredPayload(ctx context.Context, url string, slot int) (*RelayPayload, error) {
+	var result []struct {
+		Slot                 string `json:"slot"`
+		BlockNumber          string `json:"block_number"`
+		BlockHash            string `json:"block_hash"`
+		BuilderPubkey        string `json:"builder_pubkey"`
+		ProposerFeeRecipient string `json:"proposer_fee_recipient"`
+		Value                string `json:"value"`
+	}
+
+	path := fmt.Sprintf("/relay/v1/data/bidtraces/proposer_payload_delivered?slot=%d", slot)
+	if err := c.get(ctx, url+path, &result); err != nil {
+		return nil, err
+	}
+
+	for _, r := range result {
+		// Relays filter by slot, but guard against ones that ignore it
+		if r.Slot != strconv.Itoa(slot) {
+			continue
+		}
+
+		blockNumber, err := strconv.Atoi(r.BlockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block number %q: %w", r.BlockNumber, err)
+		}
+		value, ok := new(big.Int).SetString(r.Value, 10)
+		if !ok || value.Sign() < 0 {
+			return nil, fmt.Errorf("invalid payload value %q", r.Value)
+		}
+
+		return &RelayPayload{
+			Relay:                url,
+			Slot:                 slot,
+			BlockNumber:          blockNumber,
+			BlockHash:            r.BlockHash,
+			BuilderPubkey:        r.BuilderPubkey,
+			ProposerFeeRecipient: strings.ToLower(r.ProposerFeeRecipient),
+			Value:                value,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// get performs a GET request against a relay and decodes the JSON body
+func (c *Client) get(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	defer servertiming.Since(ctx, "fetch", start)
+
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
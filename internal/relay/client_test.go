@@ -0,0 +1,106 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newRelay starts a fake relay data API answering every delivered payload
+// query with status and body
+func newRelay(t *testing.T, status int, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/relay/v1/data/bidtraces/proposer_payload_delivered" || r.URL.Query().Get("slot") != "8000000" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body)) //nolint:errcheck
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+const deliveredPayload = `[{
+	"slot": "8000000",
+	"block_number": "18800000",
+	"block_hash": "0xabc",
+	"builder_pubkey": "0xa1dead",
+	"proposer_fee_recipient": "0x388C818CA8B9251b393131C08a736A67ccB19297",
+	"value": "52640953158153984"
+}]`
+
+func TestGetDeliveredPayload(t *testing.T) {
+	empty := newRelay(t, http.StatusOK, `[]`)
+	other := newRelay(t, http.StatusOK, `[{"slot": "7999999", "block_number": "1", "value": "1"}]`) // Ignores the slot filter
+	delivered := newRelay(t, http.StatusOK, deliveredPayload)
+
+	c := NewClient([]string{empty.URL, other.URL + "/", delivered.URL})
+	payload, err := c.GetDeliveredPayload(context.Background(), 8000000)
+	if err != nil {
+		t.Fatalf("GetDeliveredPayload: %v", err)
+	}
+
+	if payload.Relay != delivered.URL || payload.Slot != 8000000 || payload.BlockNumber != 18800000 {
+		t.Errorf("got relay %s slot %d block %d", payload.Relay, payload.Slot, payload.BlockNumber)
+	}
+	if payload.BlockHash != "0xabc" || payload.BuilderPubkey != "0xa1dead" {
+		t.Errorf("got block hash %s builder %s", payload.BlockHash, payload.BuilderPubkey)
+	}
+	if payload.ProposerFeeRecipient != "0x388c818ca8b9251b393131c08a736a67ccb19297" {
+		t.Errorf("got fee recipient %s, want it lowercased", payload.ProposerFeeRecipient)
+	}
+	if payload.Value.String() != "52640953158153984" {
+		t.Errorf("got value %s wei", payload.Value)
+	}
+}
+
+func TestGetDeliveredPayloadNone(t *testing.T) {
+	c := NewClient([]string{newRelay(t, http.StatusOK, `[]`).URL, newRelay(t, http.StatusOK, `[]`).URL})
+	if _, err := c.GetDeliveredPayload(context.Background(), 8000000); !errors.Is(err, ErrNoPayload) {
+		t.Errorf("got %v, want ErrNoPayload", err)
+	}
+}
+
+// TestGetDeliveredPayloadFailures checks that a failing relay is skipped
+// when another delivered the payload, but otherwise reported rather than
+// ErrNoPayload, since the payload may have come from it
+func TestGetDeliveredPayloadFailures(t *testing.T) {
+	failing := newRelay(t, http.StatusServiceUnavailable, `{"message": "unavailable"}`)
+	empty := newRelay(t, http.StatusOK, `[]`)
+	delivered := newRelay(t, http.StatusOK, deliveredPayload)
+
+	c := NewClient([]string{failing.URL, delivered.URL})
+	if payload, err := c.GetDeliveredPayload(context.Background(), 8000000); err != nil || payload.Relay != delivered.URL {
+		t.Errorf("got %+v, %v; want the payload from the healthy relay", payload, err)
+	}
+
+	c = NewClient([]string{failing.URL, empty.URL})
+	_, err := c.GetDeliveredPayload(context.Background(), 8000000)
+	if err == nil || errors.Is(err, ErrNoPayload) {
+		t.Fatalf("got %v, want the relay's failure", err)
+	}
+	if !strings.Contains(err.Error(), failing.URL) || !strings.Contains(err.Error(), "unexpected status code: 503") {
+		t.Errorf("got %v, want the failing relay and its status", err)
+	}
+}
+
+func TestGetDeliveredPayloadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"malformed body":       `[{"slot": "8000000"`,
+		"invalid block number": `[{"slot": "8000000", "block_number": "latest", "value": "1"}]`,
+		"invalid value":        `[{"slot": "8000000", "block_number": "1", "value": "0x10"}]`,
+		"negative value":       `[{"slot": "8000000", "block_number": "1", "value": "-1"}]`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := NewClient([]string{newRelay(t, http.StatusOK, body).URL})
+			if payload, err := c.GetDeliveredPayload(context.Background(), 8000000); err == nil || errors.Is(err, ErrNoPayload) {
+				t.Errorf("got %+v, %v; want an error", payload, err)
+			}
+		})
+	}
+}
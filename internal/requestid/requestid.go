@@ -0,0 +1,55 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// Header carries the request ID on HTTP requests and responses
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// New returns a random request ID
+func New() string {
+	b := make([]byte, 8)
+	rand.Read(b) //nolint:errcheck // never fails
+	return hex.EncodeToString(b)
+}
+
+// NewContext returns a context carrying request ID id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID in ctx, if any
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Handler adds the request ID from the record's context to every log
+// record passed to the wrapped handler
+type Handler struct {
+	slog.Handler
+}
+
+// Handle adds a request_id attribute when ctx carries one
+func (h Handler) Handle(ctx context.Context, r slog.Record) error {
+	if id, ok := FromContext(ctx); ok {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs keeps the wrapper around the derived handler
+func (h Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return Handler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the wrapper around the derived handler
+func (h Handler) WithGroup(name string) slog.Handler {
+	return Handler{h.Handler.WithGroup(name)}
+}
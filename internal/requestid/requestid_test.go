@@ -0,0 +1,73 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := New()
+		if b, err := hex.DecodeString(id); err != nil || len(b) != 8 {
+			t.Fatalf("got %q, want 16 hex characters", id)
+		}
+		if seen[id] {
+			t.Fatalf("got %q twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestContext(t *testing.T) {
+	if id, ok := FromContext(context.Background()); ok {
+		t.Errorf("got %q from an empty context", id)
+	}
+	if id, ok := FromContext(NewContext(context.Background(), "")); ok {
+		t.Errorf("got %q, want an empty ID treated as none", id)
+	}
+	if id, ok := FromContext(NewContext(context.Background(), "abc123")); !ok || id != "abc123" {
+		t.Errorf("got %q, %v; want abc123", id, ok)
+	}
+}
+
+// logLine logs msg through a Handler wrapping a JSON handler, after applying
+// derive, and returns the decoded record
+func logLine(t *testing.T, ctx context.Context, derive func(*slog.Logger) *slog.Logger) map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := derive(slog.New(Handler{slog.NewJSONHandler(&buf, nil)}))
+	logger.InfoContext(ctx, "msg")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decoding %q: %v", buf.String(), err)
+	}
+	return record
+}
+
+func TestHandler(t *testing.T) {
+	ctx := NewContext(context.Background(), "abc123")
+	same := func(l *slog.Logger) *slog.Logger { return l }
+
+	if got := logLine(t, ctx, same)["request_id"]; got != "abc123" {
+		t.Errorf("got request_id %v, want abc123", got)
+	}
+	if _, ok := logLine(t, context.Background(), same)["request_id"]; ok {
+		t.Error("added request_id without one in the context")
+	}
+
+	// Derived loggers keep adding it
+	record := logLine(t, ctx, func(l *slog.Logger) *slog.Logger { return l.With("component", "scanner") })
+	if record["request_id"] != "abc123" || record["component"] != "scanner" {
+		t.Errorf("got %v, want request_id and component", record)
+	}
+	record = logLine(t, ctx, func(l *slog.Logger) *slog.Logger { return l.WithGroup("rpc") })
+	if group, _ := record["rpc"].(map[string]interface{}); group["request_id"] != "abc123" {
+		t.Errorf("got %v, want request_id within the group", record)
+	}
+}
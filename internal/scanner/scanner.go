@@ -0,0 +1,215 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/storage"
+)
+
+// Defaults for the background scanner
+const (
+	DefaultPollInterval   = 12 * time.Second
+	DefaultMaxConcurrency = 4
+)
+
+// checkpointName identifies the scanner's progress in the store
+const checkpointName = "scanner"
+
+// Scanner continuously analyzes new blocks as the chain head advances and
+// persists the results. Progress is checkpointed in the store so a restarted
+// scanner resumes after the last block it persisted.
+type Scanner struct {
+	detector *models.MEVDetector
+	store    storage.Store
+
+	PollInterval   time.Duration // How often to check for new blocks
+	StartBlock     int           // First block when no checkpoint exists; 0 starts at the head
+	MaxConcurrency int           // Upper bound on concurrent block analyses
+	MinConfidence  float64       // Opportunities scoring below this are left out of saved results
+
+	// OnResult, when set, is called once with each saved block result, in
+	// block order, after the checkpoint has moved past the block. It runs on
+	// the polling goroutine and must not block.
+	OnResult func(result models.BlockMEVResult)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	next   int // Next block to analyze
+}
+
+// New creates a scanner that analyzes blocks with detector and saves them to store
+func New(detector *models.MEVDetector, store storage.Store) *Scanner {
+	return &Scanner{
+		detector:       detector,
+		store:          store,
+		PollInterval:   DefaultPollInterval,
+		MaxConcurrency: DefaultMaxConcurrency,
+	}
+}
+
+// Start resolves the block to resume from and begins scanning in the
+// background until ctx is cancelled or Stop is called
+func (s *Scanner) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return errors.New("scanner already started")
+	}
+	if s.store == nil {
+		return errors.New("scanner requires a store")
+	}
+
+	next, err := s.resumeBlock(ctx)
+	if err != nil {
+		return err
+	}
+	s.next = next
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.run(ctx, s.done)
+
+	log.Printf("Scanner started at block %d", next)
+	return nil
+}
+
+// Stop cancels scanning and waits for in-flight analyses to finish
+func (s *Scanner) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// resumeBlock returns the block after the last checkpoint, or the configured
+// start block, or the current head when neither is set
+func (s *Scanner) resumeBlock(ctx context.Context) (int, error) {
+	last, found, err := s.store.GetCheckpoint(ctx, checkpointName)
+	if err != nil {
+		return 0, err
+	}
+	if found {
+		return last + 1, nil
+	}
+	if s.StartBlock > 0 {
+		return s.StartBlock, nil
+	}
+
+	head, err := s.detector.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	return head, nil
+}
+
+// run polls for new blocks every PollInterval, closing done on exit
+func (s *Scanner) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.poll(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Scanner: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll analyzes every block from the next unscanned one up to the head, at
+// most MaxConcurrency at a time. The checkpoint only advances past blocks
+// whose predecessors have all been saved, so a failed block is retried on
+// the next poll along with the blocks after it. Only blocks the checkpoint
+// moved past are passed to OnResult, so retried blocks are not reported
+// twice.
+func (s *Scanner) poll(ctx context.Context) error {
+	head, err := s.detector.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	for s.next <= head {
+		end := min(s.next+max(s.MaxConcurrency, 1)-1, head)
+		results := make([]models.BlockMEVResult, end-s.next+1)
+		errs := make([]error, len(results))
+
+		var wg sync.WaitGroup
+		for b := s.next; b <= end; b++ {
+			wg.Add(1)
+			go func(i, b int) {
+				defer wg.Done()
+				results[i], errs[i] = s.scanBlock(ctx, b)
+			}(b-s.next, b)
+		}
+		wg.Wait()
+
+		for i, err := range errs {
+			if err != nil {
+				s.advance(ctx, s.next+i)
+				s.notify(results[:i])
+				return fmt.Errorf("block %d: %w", s.next, err)
+			}
+		}
+		s.advance(ctx, end+1)
+		s.notify(results)
+	}
+
+	return nil
+}
+
+// scanBlock analyzes a block and saves its result without the
+// opportunities below MinConfidence
+func (s *Scanner) scanBlock(ctx context.Context, blockNumber int) (models.BlockMEVResult, error) {
+	result, err := s.detector.AnalyzeBlock(ctx, blockNumber)
+	if err != nil {
+		return models.BlockMEVResult{}, err
+	}
+	result = s.detector.FilterResultByConfidence(result, s.MinConfidence)
+	if err := s.store.SaveBlockResult(ctx, result); err != nil {
+		return models.BlockMEVResult{}, err
+	}
+	return result, nil
+}
+
+// notify passes results to OnResult, if set
+func (s *Scanner) notify(results []models.BlockMEVResult) {
+	if s.OnResult == nil {
+		return
+	}
+	for _, result := range results {
+		s.OnResult(result)
+	}
+}
+
+// advance moves the next block to analyze forward and checkpoints the
+// block before it
+func (s *Scanner) advance(ctx context.Context, next int) {
+	if next == s.next {
+		return
+	}
+	s.next = next
+
+	if err := s.store.SaveCheckpoint(ctx, checkpointName, next-1); err != nil {
+		log.Printf("Scanner: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/testutil"
+)
+
+// TestPollNotifiesEachBlockOnce checks that blocks saved after a failed
+// block in the same batch are reported once, when the retry moves the
+// checkpoint past them
+func TestPollNotifiesEachBlockOnce(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	t.Cleanup(srv.Close)
+	store := testutil.NewMemStore()
+
+	s := New(srv.Detector(), store)
+	s.MaxConcurrency = 4
+	s.next = 100
+	var notified []int
+	s.OnResult = func(result models.BlockMEVResult) {
+		notified = append(notified, result.BlockNumber)
+	}
+
+	for _, b := range []int{100, 102, 103} {
+		srv.AddBlock(b, &models.Block{})
+	}
+
+	ctx := context.Background()
+	if err := s.poll(ctx); err == nil {
+		t.Fatal("poll succeeded with block 101 missing")
+	}
+	if checkpoint, _, _ := store.GetCheckpoint(ctx, checkpointName); checkpoint != 100 {
+		t.Errorf("checkpoint = %d, want 100", checkpoint)
+	}
+
+	srv.AddBlock(101, &models.Block{})
+	if err := s.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	want := []int{100, 101, 102, 103}
+	if len(notified) != len(want) {
+		t.Fatalf("notified blocks %v, want %v", notified, want)
+	}
+	for i := range want {
+		if notified[i] != want[i] {
+			t.Fatalf("notified blocks %v, want %v", notified, want)
+		}
+	}
+	if checkpoint, _, _ := store.GetCheckpoint(ctx, checkpointName); checkpoint != 103 {
+		t.Errorf("checkpoint = %d, want 103", checkpoint)
+	}
+}
+
+func TestScanBlockSavesOnlyConfidentOpportunities(t *testing.T) {
+	srv := testutil.NewRPCServer()
+	t.Cleanup(srv.Close)
+	store := testutil.NewMemStore()
+
+	s := New(srv.Detector(), store)
+	s.MinConfidence = 0.5
+	srv.AddBlock(100, &models.Block{
+		Transactions: []models.Transaction{
+			{Hash: "0x01", From: "0x0000000000007f150bd6f54c40a34d7c3d5e9f56", To: "0x2222222222222222222222222222222222222222", Value: "0x0", GasPrice: "0x3b9aca00", Input: "0x"},
+			{Hash: "0x02", From: "0x1111111111111111111111111111111111111111", To: "0x2222222222222222222222222222222222222222", Value: "0x3635c9adc5dea00000", GasPrice: "0x3b9aca00", Input: "0x"}, // 1000 ETH
+		},
+	})
+
+	if _, err := s.scanBlock(context.Background(), 100); err != nil {
+		t.Fatal(err)
+	}
+	stored, found, _ := store.GetBlockResult(context.Background(), 100)
+	if !found {
+		t.Fatal("block 100 was not saved")
+	}
+	if len(stored.Opportunities) != 1 || stored.Opportunities[0].Type != "known_bot" {
+		t.Errorf("stored opportunities %+v, want only known_bot", stored.Opportunities)
+	}
+}
@@ -0,0 +1,64 @@
+package servertiming
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type contextKey struct{}
+
+// Timings accumulates named durations for a single request. Durations for
+// the same metric are summed, so concurrent upstream calls report their
+// cumulative time.
+type Timings struct {
+	mu        sync.Mutex
+	order     []string
+	durations map[string]time.Duration
+}
+
+// NewContext returns a context carrying a fresh Timings recorder
+func NewContext(ctx context.Context) (context.Context, *Timings) {
+	t := &Timings{durations: make(map[string]time.Duration)}
+	return context.WithValue(ctx, contextKey{}, t), t
+}
+
+// Add records d against metric name on the recorder in ctx, if any
+func Add(ctx context.Context, name string, d time.Duration) {
+	t, ok := ctx.Value(contextKey{}).(*Timings)
+	if !ok {
+		return
+	}
+	t.Add(name, d)
+}
+
+// Since records the time elapsed since start against metric name
+func Since(ctx context.Context, name string, start time.Time) {
+	Add(ctx, name, time.Since(start))
+}
+
+// Add records d against metric name
+func (t *Timings) Add(name string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.durations[name]; !ok {
+		t.order = append(t.order, name)
+	}
+	t.durations[name] += d
+}
+
+// Header formats the recorded metrics as a Server-Timing header value
+func (t *Timings) Header() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	metrics := make([]string, 0, len(t.order))
+	for _, name := range t.order {
+		ms := float64(t.durations[name]) / float64(time.Millisecond)
+		metrics = append(metrics, fmt.Sprintf("%s;dur=%.3f", name, ms))
+	}
+	return strings.Join(metrics, ", ")
+}
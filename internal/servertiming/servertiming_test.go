@@ -0,0 +1,59 @@
+package servertiming
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHeader(t *testing.T) {
+	ctx, timings := NewContext(context.Background())
+	if got := timings.Header(); got != "" {
+		t.Errorf("got %q before any metric, want empty", got)
+	}
+
+	Add(ctx, "fetch", 12*time.Millisecond)
+	Add(ctx, "detect", 1500*time.Microsecond)
+	Add(ctx, "fetch", 3*time.Millisecond+250*time.Nanosecond) // Summed into the first
+
+	// Metrics keep the order they were first recorded in
+	want := "fetch;dur=15.000, detect;dur=1.500"
+	if got := timings.Header(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSince(t *testing.T) {
+	ctx, timings := NewContext(context.Background())
+	Since(ctx, "serialize", time.Now().Add(-2*time.Second))
+
+	if got := timings.Header(); !strings.HasPrefix(got, "serialize;dur=2") || len(got) != len("serialize;dur=2000.000") {
+		t.Errorf("got %q, want about 2000ms", got)
+	}
+}
+
+func TestAddWithoutRecorder(t *testing.T) {
+	// Upstream calls outside a request record nowhere
+	Add(context.Background(), "fetch", time.Second)
+	Since(context.Background(), "fetch", time.Now())
+}
+
+func TestAddConcurrent(t *testing.T) {
+	ctx, timings := NewContext(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Add(ctx, "fetch", time.Millisecond)
+		}()
+	}
+	wg.Wait()
+
+	if got := timings.Header(); got != "fetch;dur=50.000" {
+		t.Errorf("got %q, want the cumulative 50ms", got)
+	}
+}
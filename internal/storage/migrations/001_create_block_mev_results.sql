@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS block_mev_results (
+    block_number      BIGINT PRIMARY KEY,
+    validator_reward  DOUBLE PRECISION NOT NULL,
+    opportunity_types TEXT[] NOT NULL DEFAULT '{}',
+    opportunities     JSONB NOT NULL DEFAULT '[]',
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
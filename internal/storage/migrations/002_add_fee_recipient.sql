@@ -0,0 +1,2 @@
+ALTER TABLE block_mev_results
+    ADD COLUMN IF NOT EXISTS fee_recipient TEXT NOT NULL DEFAULT '';
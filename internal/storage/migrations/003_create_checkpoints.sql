@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS checkpoints (
+    name         TEXT PRIMARY KEY,
+    block_number BIGINT NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
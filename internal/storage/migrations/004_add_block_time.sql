@@ -0,0 +1,2 @@
+ALTER TABLE block_mev_results
+    ADD COLUMN IF NOT EXISTS block_time TIMESTAMPTZ;
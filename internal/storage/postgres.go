@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"sort"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/lib/pq"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// PostgresStore is a Store backed by PostgreSQL
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore connects to PostgreSQL and applies the schema migrations
+func NewPostgresStore(ctx context.Context, dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	s := &PostgresStore{db: db}
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// DSN builds a PostgreSQL connection URL. Values are escaped, so
+// credentials and names may contain spaces, quotes or URL delimiters.
+func DSN(host, port, user, password, name, sslMode string) string {
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(user, password),
+		Host:     host,
+		Path:     "/" + name,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// migrate applies the embedded migration files in lexical order
+func (s *PostgresStore) migrate(ctx context.Context) error {
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		query, err := migrations.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+		if _, err := s.db.ExecContext(ctx, string(query)); err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// SaveBlockResult inserts or replaces the result for a block
+func (s *PostgresStore) SaveBlockResult(ctx context.Context, result models.BlockMEVResult) error {
+	opportunities, err := json.Marshal(result.Opportunities)
+	if err != nil {
+		return fmt.Errorf("failed to encode opportunities: %w", err)
+	}
+
+	types := make([]string, 0, len(result.Opportunities))
+	for _, opp := range result.Opportunities {
+		types = append(types, opp.Type)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO block_mev_results (block_number, validator_reward, opportunity_types, opportunities, fee_recipient, block_time, gas_prices, detector_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (block_number) DO UPDATE SET
+			validator_reward = EXCLUDED.validator_reward,
+			opportunity_types = EXCLUDED.opportunity_types,
+			opportunities = EXCLUDED.opportunities,
+			fee_recipient = EXCLUDED.fee_recipient,
+			block_time = EXCLUDED.block_time,
+			gas_prices = EXCLUDED.gas_prices,
+			detector_version = EXCLUDED.detector_version`,
+		result.BlockNumber, result.ValidatorReward, pq.Array(types), opportunities, result.FeeRecipient, result.BlockTime, pq.Array(result.GasPrices), result.DetectorVersion)
+	if err != nil {
+		return fmt.Errorf("failed to save block %d: %w", result.BlockNumber, err)
+	}
+
+	return nil
+}
+
+// GetBlockResult returns the stored result for a block, reporting false if none exists
+func (s *PostgresStore) GetBlockResult(ctx context.Context, blockNumber int) (*models.BlockMEVResult, bool, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT block_number, validator_reward, opportunities, fee_recipient, block_time, gas_prices, detector_version
+		FROM block_mev_results
+		WHERE block_number = $1`, blockNumber)
+	result, err := scanBlockResult(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load block %d: %w", blockNumber, err)
+	}
+
+	return result, true, nil
+}
+
+// GetBlockResults returns the stored results for blocks fromBlock through toBlock, in block order
+func (s *PostgresStore) GetBlockResults(ctx context.Context, fromBlock, toBlock int) ([]models.BlockMEVResult, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT block_number, validator_reward, opportunities, fee_recipient, block_time, gas_prices, detector_version
+		FROM block_mev_results
+		WHERE block_number BETWEEN $1 AND $2
+		ORDER BY block_number`, fromBlock, toBlock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blocks %d-%d: %w", fromBlock, toBlock, err)
+	}
+	defer rows.Close()
+
+	var results []models.BlockMEVResult
+	for rows.Next() {
+		result, err := scanBlockResult(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load blocks %d-%d: %w", fromBlock, toBlock, err)
+		}
+		results = append(results, *result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load blocks %d-%d: %w", fromBlock, toBlock, err)
+	}
+
+	return results, nil
+}
+
+// scanBlockResult decodes a block_mev_results row selected as block_number,
+// validator_reward, opportunities, fee_recipient, block_time, gas_prices,
+// detector_version
+func scanBlockResult(row interface{ Scan(dest ...any) error }) (*models.BlockMEVResult, error) {
+	var (
+		result        models.BlockMEVResult
+		opportunities []byte
+		blockTime     sql.NullTime
+	)
+	if err := row.Scan(&result.BlockNumber, &result.ValidatorReward, &opportunities, &result.FeeRecipient, &blockTime, pq.Array(&result.GasPrices), &result.DetectorVersion); err != nil {
+		return nil, err
+	}
+
+	if blockTime.Valid {
+		t := blockTime.Time.UTC()
+		result.BlockTime = &t
+	}
+	if err := json.Unmarshal(opportunities, &result.Opportunities); err != nil {
+		return nil, fmt.Errorf("failed to decode opportunities for block %d: %w", result.BlockNumber, err)
+	}
+
+	return &result, nil
+}
+
+// SaveCheckpoint records the last block a named background job has processed
+func (s *PostgresStore) SaveCheckpoint(ctx context.Context, name string, blockNumber int) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO checkpoints (name, block_number)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET
+			block_number = EXCLUDED.block_number,
+			updated_at = now()`,
+		name, blockNumber)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// GetCheckpoint returns a named job's last processed block, reporting false if none exists
+func (s *PostgresStore) GetCheckpoint(ctx context.Context, name string) (int, bool, error) {
+	var blockNumber int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT block_number
+		FROM checkpoints
+		WHERE name = $1`, name).Scan(&blockNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to load checkpoint %s: %w", name, err)
+	}
+
+	return blockNumber, true, nil
+}
+
+// Close releases the underlying connections
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
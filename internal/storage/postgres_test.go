@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+
+	"github.com/lib/pq"
+)
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name                                    string
+		host, port, user, password, db, sslMode string
+		want                                    string // As parsed by lib/pq
+	}{
+		{
+			name: "plain", host: "localhost", port: "5432", user: "mev", password: "secret", db: "mev", sslMode: "require",
+			want: "dbname='mev' host='localhost' password='secret' port='5432' sslmode='require' user='mev'",
+		},
+		{
+			name: "default sslmode", host: "localhost", port: "5432", user: "mev", password: "secret", db: "mev",
+			want: "dbname='mev' host='localhost' password='secret' port='5432' sslmode='disable' user='mev'",
+		},
+		{
+			name: "special characters", host: "db.internal", port: "5432", user: "mev user@corp", password: `p@ss w/rd='x'?#`, db: "mev db",
+			want: `dbname='mev db' host='db.internal' password='p@ss w/rd=\'x\'?#' port='5432' sslmode='disable' user='mev user@corp'`,
+		},
+		{
+			name: "option injection", host: "localhost", port: "5432", user: "mev", password: "x sslmode=disable host=evil", db: "mev", sslMode: "verify-full",
+			want: "dbname='mev' host='localhost' password='x sslmode=disable host=evil' port='5432' sslmode='verify-full' user='mev'",
+		},
+		{
+			name: "no port", host: "localhost", user: "mev", password: "secret", db: "mev",
+			want: "dbname='mev' host='localhost' password='secret' sslmode='disable' user='mev'",
+		},
+		{
+			name: "ipv6 host", host: "::1", port: "5432", user: "mev", password: "secret", db: "mev",
+			want: "dbname='mev' host='::1' password='secret' port='5432' sslmode='disable' user='mev'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn := DSN(tt.host, tt.port, tt.user, tt.password, tt.db, tt.sslMode)
+			got, err := pq.ParseURL(dsn)
+			if err != nil {
+				t.Fatalf("ParseURL(%q): %v", dsn, err)
+			}
+			if got != tt.want {
+				t.Errorf("DSN parsed as\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMigrationsNumbered checks that migrations carry distinct, consecutive
+// numbers, so lexical order is the order they were written in
+func TestMigrationsNumbered(t *testing.T) {
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, file := range files {
+		var n int
+		if _, err := fmt.Sscanf(file, "migrations/%03d_", &n); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if n != i+1 {
+			t.Errorf("%s is migration %d, want %d", file, n, i+1)
+		}
+	}
+}
+
+// fakeRow scans fixed column values, as database/sql would for a row
+type fakeRow []any
+
+func (r fakeRow) Scan(dest ...any) error {
+	if len(dest) != len(r) {
+		return fmt.Errorf("scanning %d columns into %d destinations", len(r), len(dest))
+	}
+	for i, d := range dest {
+		if s, ok := d.(sql.Scanner); ok {
+			if err := s.Scan(r[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r[i]))
+	}
+	return nil
+}
+
+func TestScanBlockResult(t *testing.T) {
+	blockTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	row := fakeRow{
+		100,
+		0.25,
+		[]byte(`[{"type":"sandwich","profit":0.25,"blockNumber":100,"confidence":0.9}]`),
+		"0x1111111111111111111111111111111111111111",
+		blockTime,
+		[]byte("{1.5,20,300.25}"),
+		"v2",
+	}
+
+	result, err := scanBlockResult(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.BlockNumber != 100 || result.ValidatorReward != 0.25 || result.DetectorVersion != "v2" {
+		t.Errorf("got block %d reward %v version %q", result.BlockNumber, result.ValidatorReward, result.DetectorVersion)
+	}
+	if len(result.Opportunities) != 1 || result.Opportunities[0].Type != "sandwich" || result.Opportunities[0].Confidence != 0.9 {
+		t.Errorf("got opportunities %+v", result.Opportunities)
+	}
+	if result.BlockTime == nil || !result.BlockTime.Equal(blockTime) || result.BlockTime.Location() != time.UTC {
+		t.Errorf("got block time %v, want %v in UTC", result.BlockTime, blockTime)
+	}
+	if want := []float64{1.5, 20, 300.25}; !reflect.DeepEqual(result.GasPrices, want) {
+		t.Errorf("got gas prices %v, want %v", result.GasPrices, want)
+	}
+}
+
+// TestScanBlockResultNullColumns checks rows saved before block times and
+// gas prices were recorded
+func TestScanBlockResultNullColumns(t *testing.T) {
+	result, err := scanBlockResult(fakeRow{100, 0.0, []byte("[]"), "", nil, nil, ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.BlockTime != nil {
+		t.Errorf("got block time %v, want none", result.BlockTime)
+	}
+	if result.GasPrices != nil {
+		t.Errorf("got gas prices %v, want none", result.GasPrices)
+	}
+	if len(result.Opportunities) != 0 {
+		t.Errorf("got opportunities %+v, want none", result.Opportunities)
+	}
+}
+
+func TestScanBlockResultInvalidOpportunities(t *testing.T) {
+	_, err := scanBlockResult(fakeRow{100, 0.0, []byte("{"), "", nil, nil, ""})
+	if err == nil {
+		t.Fatal("expected an error for malformed opportunities")
+	}
+}
+
+func TestScanBlockResultScanError(t *testing.T) {
+	_, err := scanBlockResult(errRow{sql.ErrNoRows})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("got %v, want sql.ErrNoRows", err)
+	}
+}
+
+type errRow struct{ err error }
+
+func (r errRow) Scan(dest ...any) error { return r.err }
+
+// TestPostgresStore runs the store against a live database named by
+// TEST_DATABASE_URL, and is skipped without one. It writes to the
+// block_mev_results and checkpoints tables, so use a scratch database.
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+
+	s, err := NewPostgresStore(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	blockTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	base := 900_000_000 // Far above real blocks, so reruns only replace their own rows
+	for i := 0; i < 3; i++ {
+		result := models.BlockMEVResult{
+			BlockNumber:     base + i,
+			ValidatorReward: float64(i),
+			Opportunities:   []models.MEVOpportunity{{Type: "arbitrage", Profit: float64(i), BlockNumber: base + i}},
+			FeeRecipient:    "0x1111111111111111111111111111111111111111",
+			BlockTime:       &blockTime,
+			GasPrices:       []float64{1, 2},
+			DetectorVersion: "test",
+		}
+		if err := s.SaveBlockResult(ctx, result); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Saving again replaces the row
+	if err := s.SaveBlockResult(ctx, models.BlockMEVResult{BlockNumber: base + 1, ValidatorReward: 5}); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := s.GetBlockResult(ctx, base+1)
+	if err != nil || !ok {
+		t.Fatalf("GetBlockResult: %v, found %v", err, ok)
+	}
+	if got.ValidatorReward != 5 || len(got.Opportunities) != 0 || got.BlockTime != nil {
+		t.Errorf("got %+v after replacing block %d", got, base+1)
+	}
+
+	if _, ok, err := s.GetBlockResult(ctx, base+10); err != nil || ok {
+		t.Errorf("missing block: got found %v, error %v", ok, err)
+	}
+
+	results, err := s.GetBlockResults(ctx, base, base+2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for i, result := range results {
+		if result.BlockNumber != base+i {
+			t.Errorf("result %d is block %d, want %d", i, result.BlockNumber, base+i)
+		}
+	}
+	if r := results[2]; !r.BlockTime.Equal(blockTime) || !reflect.DeepEqual(r.GasPrices, []float64{1, 2}) || r.DetectorVersion != "test" {
+		t.Errorf("got %+v", r)
+	}
+
+	name := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	if _, ok, err := s.GetCheckpoint(ctx, name); err != nil || ok {
+		t.Errorf("new checkpoint: got found %v, error %v", ok, err)
+	}
+	for _, block := range []int{10, 20} {
+		if err := s.SaveCheckpoint(ctx, name, block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if block, ok, err := s.GetCheckpoint(ctx, name); err != nil || !ok || block != 20 {
+		t.Errorf("got checkpoint %d, found %v, error %v; want 20", block, ok, err)
+	}
+}
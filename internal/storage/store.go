@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// Store persists analyzed block results
+type Store interface {
+	// SaveBlockResult inserts or replaces the result for a block
+	SaveBlockResult(ctx context.Context, result models.BlockMEVResult) error
+	// GetBlockResult returns the stored result for a block, reporting false if none exists
+	GetBlockResult(ctx context.Context, blockNumber int) (*models.BlockMEVResult, bool, error)
+	// GetBlockResults returns the stored results for blocks fromBlock through toBlock, in block order
+	GetBlockResults(ctx context.Context, fromBlock, toBlock int) ([]models.BlockMEVResult, error)
+	// SaveCheckpoint records the last block a named background job has processed
+	SaveCheckpoint(ctx context.Context, name string, blockNumber int) error
+	// GetCheckpoint returns a named job's last processed block, reporting false if none exists
+	GetCheckpoint(ctx context.Context, name string) (int, bool, error)
+	// Close releases the underlying connections
+	Close() error
+}
@@ -0,0 +1,127 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/beacon"
+)
+
+// BeaconServer is an in-process beacon node REST API serving canned
+// proposer duties, blocks and genesis time. Slots without a block answer
+// 404, as for a missed slot.
+type BeaconServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	duties   map[int][]beacon.ProposerDuty // By epoch
+	blocks   map[int]int                   // Execution block number by slot
+	genesis  time.Time
+	requests map[string]int // By path
+}
+
+// NewBeaconServer starts a fake beacon node. Callers must Close it.
+func NewBeaconServer() *BeaconServer {
+	s := &BeaconServer{
+		duties:   make(map[int][]beacon.ProposerDuty),
+		blocks:   make(map[int]int),
+		genesis:  time.Unix(1606824023, 0).UTC(), // Mainnet
+		requests: make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddProposal assigns slot to validatorIndex and, unless blockNumber is
+// negative, serves blockNumber as the execution block proposed at slot
+func (s *BeaconServer) AddProposal(slot, validatorIndex, blockNumber int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	epoch := slot / beacon.SlotsPerEpoch
+	s.duties[epoch] = append(s.duties[epoch], beacon.ProposerDuty{ValidatorIndex: validatorIndex, Slot: slot})
+	if blockNumber >= 0 {
+		s.blocks[slot] = blockNumber
+	}
+}
+
+// SetGenesis overrides the genesis time, which defaults to mainnet's
+func (s *BeaconServer) SetGenesis(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.genesis = t.UTC()
+}
+
+// Requests returns how many requests the server has received for path
+func (s *BeaconServer) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// Client returns a beacon client for the server
+func (s *BeaconServer) Client() *beacon.Client {
+	c := beacon.NewClient(s.URL)
+	c.HttpClient = s.Server.Client()
+	return c
+}
+
+func (s *BeaconServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[r.URL.Path]++
+
+	switch {
+	case r.URL.Path == "/eth/v1/beacon/genesis":
+		writeData(w, map[string]string{"genesis_time": strconv.FormatInt(s.genesis.Unix(), 10)})
+
+	case strings.HasPrefix(r.URL.Path, "/eth/v1/validator/duties/proposer/"):
+		epoch, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/eth/v1/validator/duties/proposer/"))
+		if err != nil {
+			http.Error(w, "invalid epoch", http.StatusBadRequest)
+			return
+		}
+		duties := make([]map[string]string, 0, len(s.duties[epoch]))
+		for _, duty := range s.duties[epoch] {
+			duties = append(duties, map[string]string{
+				"validator_index": strconv.Itoa(duty.ValidatorIndex),
+				"slot":            strconv.Itoa(duty.Slot),
+			})
+		}
+		writeData(w, duties)
+
+	case strings.HasPrefix(r.URL.Path, "/eth/v2/beacon/blocks/"):
+		slot, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/eth/v2/beacon/blocks/"))
+		if err != nil {
+			http.Error(w, "invalid slot", http.StatusBadRequest)
+			return
+		}
+		blockNumber, ok := s.blocks[slot]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeData(w, map[string]interface{}{
+			"message": map[string]interface{}{
+				"slot": strconv.Itoa(slot),
+				"body": map[string]interface{}{
+					"execution_payload": map[string]string{"block_number": strconv.Itoa(blockNumber)},
+				},
+			},
+		})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// writeData writes data wrapped in the beacon API's {"data": ...} envelope
+func writeData(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data}) //nolint:errcheck
+}
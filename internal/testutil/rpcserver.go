@@ -0,0 +1,298 @@
+// Package testutil provides fake JSON-RPC execution and beacon node
+// endpoints and an in-memory store for exercising the detector, API and
+// scanner without real nodes or a database
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// RPCError is a canned JSON-RPC error response
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// RPCServer is an in-process JSON-RPC endpoint serving canned blocks,
+// receipts and errors. It answers eth_getBlockByNumber, eth_blockNumber and
+// eth_getBlockReceipts, singly or in batches; other methods fail with
+// method not found.
+type RPCServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	blocks   map[int]*models.Block
+	pending  *models.Block
+	receipts map[int][]models.Receipt
+	failures map[int]int // Receipts calls left to fail, by block
+	latest   int
+	errors   map[string]RPCError // By method
+	status   int                 // HTTP status to fail every request with; 0 serves normally
+	delay    time.Duration       // Added before answering each HTTP request
+	requests map[string]int      // By method
+	inFlight int                 // HTTP requests being answered
+	peak     int                 // Most HTTP requests answered at once
+}
+
+// NewRPCServer starts a fake endpoint. Callers must Close it.
+func NewRPCServer() *RPCServer {
+	s := &RPCServer{
+		blocks:   make(map[int]*models.Block),
+		receipts: make(map[int][]models.Receipt),
+		failures: make(map[int]int),
+		errors:   make(map[string]RPCError),
+		requests: make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddBlock serves block as blockNumber, moving the latest block forward if
+// needed. The block's number is filled in when empty.
+func (s *RPCServer) AddBlock(blockNumber int, block *models.Block) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if block.Number == "" {
+		block.Number = fmt.Sprintf("0x%x", blockNumber)
+	}
+	s.blocks[blockNumber] = block
+	s.latest = max(s.latest, blockNumber)
+}
+
+// SetPending serves block as the pending block; nil serves no pending block
+func (s *RPCServer) SetPending(block *models.Block) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = block
+}
+
+// AddReceipts serves receipts for blockNumber. Blocks without receipts
+// return an empty list.
+func (s *RPCServer) AddReceipts(blockNumber int, receipts []models.Receipt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.receipts[blockNumber] = receipts
+}
+
+// FailReceipts makes the next times eth_getBlockReceipts calls for
+// blockNumber fail with an internal error, as a flaky node would
+func (s *RPCServer) FailReceipts(blockNumber, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[blockNumber] = times
+}
+
+// SetLatest overrides the block number reported by eth_blockNumber
+func (s *RPCServer) SetLatest(blockNumber int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest = blockNumber
+}
+
+// SetError makes every call to method fail with a JSON-RPC error. A zero
+// code clears it.
+func (s *RPCServer) SetError(method string, code int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if code == 0 {
+		delete(s.errors, method)
+		return
+	}
+	s.errors[method] = RPCError{Code: code, Message: message}
+}
+
+// SetStatus makes every request fail with HTTP status code. Zero restores
+// normal responses.
+func (s *RPCServer) SetStatus(code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = code
+}
+
+// SetDelay holds every HTTP request for d before answering it, or until
+// the client gives up
+func (s *RPCServer) SetDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = d
+}
+
+// Requests returns how many calls to method the server has received,
+// counting each entry of a batch
+func (s *RPCServer) Requests(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[method]
+}
+
+// MaxConcurrent returns the most HTTP requests the server has answered at
+// once
+func (s *RPCServer) MaxConcurrent() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peak
+}
+
+// Provider returns a provider for the server that does not retry, so
+// canned failures surface immediately
+func (s *RPCServer) Provider() *models.JSONRPCProvider {
+	p := models.NewJSONRPCProvider(s.URL)
+	p.HttpClient = s.Client()
+	p.MaxRetries = 0
+	p.RetryBaseDelay = 0
+	return p
+}
+
+// Detector returns a detector reading from the server
+func (s *RPCServer) Detector() *models.MEVDetector {
+	return models.NewMEVDetector(s.Provider())
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// rpcResponse is a JSON-RPC response object. It is a map so a null result,
+// as for a missing block, is still encoded.
+type rpcResponse map[string]interface{}
+
+func newResponse(id json.RawMessage, result interface{}) rpcResponse {
+	return rpcResponse{"jsonrpc": "2.0", "id": id, "result": result}
+}
+
+func newErrorResponse(id json.RawMessage, code int, message string) rpcResponse {
+	return rpcResponse{"jsonrpc": "2.0", "id": id, "error": RPCError{Code: code, Message: message}}
+}
+
+func (s *RPCServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	status, delay := s.status, s.delay
+	s.inFlight++
+	s.peak = max(s.peak, s.inFlight)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	if delay > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+	if status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var batch []rpcRequest
+	if err := json.Unmarshal(body, &batch); err == nil {
+		responses := make([]rpcResponse, len(batch))
+		for i, req := range batch {
+			responses[i] = s.handle(req)
+		}
+		json.NewEncoder(w).Encode(responses)
+		return
+	}
+
+	var req rpcRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		json.NewEncoder(w).Encode(newErrorResponse(json.RawMessage("null"), -32700, "parse error"))
+		return
+	}
+	json.NewEncoder(w).Encode(s.handle(req))
+}
+
+// handle answers a single request
+func (s *RPCServer) handle(req rpcRequest) rpcResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[req.Method]++
+	if rpcErr, ok := s.errors[req.Method]; ok {
+		return newErrorResponse(req.ID, rpcErr.Code, rpcErr.Message)
+	}
+
+	switch req.Method {
+	case "eth_blockNumber":
+		return newResponse(req.ID, fmt.Sprintf("0x%x", s.latest))
+	case "eth_getBlockByNumber":
+		if len(req.Params) > 0 && string(req.Params[0]) == `"pending"` {
+			if s.pending == nil {
+				return newResponse(req.ID, nil)
+			}
+			return newResponse(req.ID, s.pending)
+		}
+		blockNumber, err := s.blockParam(req.Params)
+		if err != nil {
+			return newErrorResponse(req.ID, -32602, err.Error())
+		}
+		if block, ok := s.blocks[blockNumber]; ok {
+			return newResponse(req.ID, block)
+		}
+		return newResponse(req.ID, nil)
+	case "eth_getBlockReceipts":
+		blockNumber, err := s.blockParam(req.Params)
+		if err != nil {
+			return newErrorResponse(req.ID, -32602, err.Error())
+		}
+		if s.failures[blockNumber] > 0 {
+			s.failures[blockNumber]--
+			return newErrorResponse(req.ID, -32603, "internal error")
+		}
+		receipts := s.receipts[blockNumber]
+		if receipts == nil {
+			receipts = []models.Receipt{}
+		}
+		return newResponse(req.ID, receipts)
+	default:
+		return newErrorResponse(req.ID, -32601, fmt.Sprintf("the method %s does not exist/is not available", req.Method))
+	}
+}
+
+// blockParam parses the block number in the first param, a hex quantity or
+// "latest". The caller must hold s.mu.
+func (s *RPCServer) blockParam(params []json.RawMessage) (int, error) {
+	if len(params) == 0 {
+		return 0, fmt.Errorf("missing block number")
+	}
+
+	var tag string
+	if err := json.Unmarshal(params[0], &tag); err != nil {
+		return 0, fmt.Errorf("invalid block number: %s", params[0])
+	}
+	if tag == "latest" {
+		return s.latest, nil
+	}
+
+	n, err := strconv.ParseInt(strings.TrimPrefix(tag, "0x"), 16, 64)
+	if err != nil || !strings.HasPrefix(tag, "0x") {
+		return 0, fmt.Errorf("invalid block number: %q", tag)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,76 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+
+	"github.com/brianreynaldgit/mev-staking-tracker/internal/models"
+)
+
+// MemStore is an in-memory storage.Store
+type MemStore struct {
+	mu          sync.Mutex
+	results     map[int]models.BlockMEVResult
+	checkpoints map[string]int
+}
+
+// NewMemStore returns an empty store
+func NewMemStore() *MemStore {
+	return &MemStore{
+		results:     make(map[int]models.BlockMEVResult),
+		checkpoints: make(map[string]int),
+	}
+}
+
+// SaveBlockResult inserts or replaces the result for a block
+func (s *MemStore) SaveBlockResult(ctx context.Context, result models.BlockMEVResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[result.BlockNumber] = result
+	return nil
+}
+
+// GetBlockResult returns the stored result for a block, reporting false if none exists
+func (s *MemStore) GetBlockResult(ctx context.Context, blockNumber int) (*models.BlockMEVResult, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, ok := s.results[blockNumber]
+	if !ok {
+		return nil, false, nil
+	}
+	return &result, true, nil
+}
+
+// GetBlockResults returns the stored results for blocks fromBlock through toBlock, in block order
+func (s *MemStore) GetBlockResults(ctx context.Context, fromBlock, toBlock int) ([]models.BlockMEVResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var results []models.BlockMEVResult
+	for b := fromBlock; b <= toBlock; b++ {
+		if result, ok := s.results[b]; ok {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// SaveCheckpoint records the last block a named background job has processed
+func (s *MemStore) SaveCheckpoint(ctx context.Context, name string, blockNumber int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[name] = blockNumber
+	return nil
+}
+
+// GetCheckpoint returns a named job's last processed block, reporting false if none exists
+func (s *MemStore) GetCheckpoint(ctx context.Context, name string) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blockNumber, ok := s.checkpoints[name]
+	return blockNumber, ok, nil
+}
+
+// Close does nothing
+func (s *MemStore) Close() error {
+	return nil
+}
@@ -0,0 +1,125 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+)
+
+type contextKey struct{}
+
+// Limiter is an AIMD concurrency limiter. The limit grows by one after a
+// full window of successes and halves whenever the upstream reports
+// overload, staying within [min, max].
+type Limiter struct {
+	mu        sync.Mutex
+	min, max  int
+	limit     int
+	inFlight  int
+	successes int
+	wake      chan struct{}
+}
+
+// NewLimiter returns a limiter starting at max concurrency
+func NewLimiter(min, max int) *Limiter {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return &Limiter{
+		min:   min,
+		max:   max,
+		limit: max,
+		wake:  make(chan struct{}),
+	}
+}
+
+// NewContext returns a context carrying l so upstream calls can report overload
+func NewContext(ctx context.Context, l *Limiter) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the limiter carried by ctx, if any
+func FromContext(ctx context.Context) (*Limiter, bool) {
+	l, ok := ctx.Value(contextKey{}).(*Limiter)
+	return l, ok
+}
+
+// Overloaded reports upstream overload to the limiter in ctx, if any
+func Overloaded(ctx context.Context) {
+	l, ok := FromContext(ctx)
+	if !ok {
+		return
+	}
+	l.Overloaded()
+}
+
+// Acquire blocks until a slot is available under the current limit or ctx
+// is done
+func (l *Limiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.limit {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (l *Limiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	l.broadcast()
+}
+
+// Success records a successful unit of work, raising the limit by one once
+// a full window of successes has been seen
+func (l *Limiter) Success() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.successes++
+	if l.successes >= l.limit && l.limit < l.max {
+		l.limit++
+		l.successes = 0
+		l.broadcast()
+	}
+}
+
+// Overloaded halves the limit, not going below min
+func (l *Limiter) Overloaded() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit /= 2
+	if l.limit < l.min {
+		l.limit = l.min
+	}
+	l.successes = 0
+}
+
+// Limit returns the current concurrency limit
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// broadcast wakes all goroutines waiting in Acquire. Callers must hold mu.
+func (l *Limiter) broadcast() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
@@ -0,0 +1,171 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewLimiter(t *testing.T) {
+	tests := []struct {
+		min, max         int
+		wantMin, wantMax int
+	}{
+		{2, 8, 2, 8},
+		{0, 8, 1, 8},
+		{4, 2, 4, 4},
+		{-1, 0, 1, 1},
+	}
+	for _, tt := range tests {
+		l := NewLimiter(tt.min, tt.max)
+		if l.min != tt.wantMin || l.max != tt.wantMax || l.Limit() != tt.wantMax {
+			t.Errorf("NewLimiter(%d, %d) has range [%d, %d] limit %d, want [%d, %d] starting at the max",
+				tt.min, tt.max, l.min, l.max, l.Limit(), tt.wantMin, tt.wantMax)
+		}
+	}
+}
+
+func TestLimiterAIMD(t *testing.T) {
+	l := NewLimiter(2, 8)
+
+	// Overload halves the limit down to the min
+	for _, want := range []int{4, 2, 2} {
+		l.Overloaded()
+		if got := l.Limit(); got != want {
+			t.Fatalf("got limit %d after overload, want %d", got, want)
+		}
+	}
+
+	// A full window of successes raises it by one
+	l.Success()
+	if got := l.Limit(); got != 2 {
+		t.Errorf("got limit %d after one success, want 2", got)
+	}
+	l.Success()
+	if got := l.Limit(); got != 3 {
+		t.Errorf("got limit %d after a window of successes, want 3", got)
+	}
+
+	// Overload restarts the window
+	l.Success()
+	l.Success()
+	l.Overloaded()
+	if got := l.Limit(); got != 2 {
+		t.Fatalf("got limit %d, want 2", got)
+	}
+	l.Success()
+	if got := l.Limit(); got != 2 {
+		t.Errorf("got limit %d, want successes before the overload forgotten", got)
+	}
+
+	// Growth stops at the max
+	for i := 0; i < 100; i++ {
+		l.Success()
+	}
+	if got := l.Limit(); got != 8 {
+		t.Errorf("got limit %d after many successes, want the max 8", got)
+	}
+}
+
+// acquireAsync calls Acquire in the background, returning its result
+func acquireAsync(ctx context.Context, l *Limiter) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- l.Acquire(ctx) }()
+	return done
+}
+
+func expectBlocked(t *testing.T, done <-chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		t.Fatalf("Acquire returned %v above the limit", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func expectAcquired(t *testing.T, done <-chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Acquire stayed blocked")
+	}
+}
+
+func TestLimiterAcquire(t *testing.T) {
+	l := NewLimiter(1, 2)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := l.Acquire(ctx); err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+	}
+
+	// Release wakes a waiter
+	waiter := acquireAsync(ctx, l)
+	expectBlocked(t, waiter)
+	l.Release()
+	expectAcquired(t, waiter)
+
+	// A lowered limit holds new work back until enough is released
+	l.Overloaded()
+	waiter = acquireAsync(ctx, l)
+	l.Release()
+	expectBlocked(t, waiter)
+	l.Release()
+	expectAcquired(t, waiter)
+
+	// A raised limit wakes a waiter
+	waiter = acquireAsync(ctx, l)
+	expectBlocked(t, waiter)
+	l.Success()
+	expectAcquired(t, waiter)
+}
+
+func TestLimiterAcquireCancelled(t *testing.T) {
+	l := NewLimiter(1, 1)
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waiter := acquireAsync(ctx, l)
+	expectBlocked(t, waiter)
+	cancel()
+	select {
+	case err := <-waiter:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Acquire ignored cancellation")
+	}
+
+	// The cancelled waiter took no slot
+	l.Release()
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Errorf("Acquire after release: %v", err)
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := FromContext(ctx); ok {
+		t.Error("found a limiter in an empty context")
+	}
+	Overloaded(ctx) // No limiter to report to
+
+	l := NewLimiter(1, 8)
+	ctx = NewContext(ctx, l)
+	if got, ok := FromContext(ctx); !ok || got != l {
+		t.Errorf("got %p, %v; want the limiter", got, ok)
+	}
+	Overloaded(ctx)
+	if got := l.Limit(); got != 4 {
+		t.Errorf("got limit %d, want the overload reported through the context", got)
+	}
+}
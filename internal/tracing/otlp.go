@@ -0,0 +1,247 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Batching defaults for the OTLP exporter
+const (
+	DefaultBatchSize     = 512
+	DefaultFlushInterval = 5 * time.Second
+	queueSize            = 4096
+)
+
+// OTLPExporter batches spans and posts them to an OpenTelemetry collector
+// using OTLP/HTTP with JSON encoding. Spans are dropped rather than
+// blocking requests when the queue is full.
+type OTLPExporter struct {
+	Endpoint      string // Collector traces URL, e.g. http://localhost:4318/v1/traces
+	ServiceName   string
+	BatchSize     int
+	FlushInterval time.Duration
+	HttpClient    *http.Client
+
+	queue chan SpanData
+	done  chan struct{}
+	wg    sync.WaitGroup
+	once  sync.Once
+}
+
+// NewOTLPExporter creates an exporter for the collector at endpoint. A base
+// URL without a path has the standard /v1/traces path appended. Call Start
+// before use and Shutdown to flush.
+func NewOTLPExporter(endpoint, serviceName string) *OTLPExporter {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint += "/v1/traces"
+	}
+	return &OTLPExporter{
+		Endpoint:      endpoint,
+		ServiceName:   serviceName,
+		BatchSize:     DefaultBatchSize,
+		FlushInterval: DefaultFlushInterval,
+		HttpClient:    &http.Client{Timeout: 10 * time.Second},
+		queue:         make(chan SpanData, queueSize),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start begins exporting queued spans in the background
+func (e *OTLPExporter) Start() {
+	e.wg.Add(1)
+	go e.run()
+}
+
+// ExportSpan queues a finished span
+func (e *OTLPExporter) ExportSpan(span SpanData) {
+	select {
+	case e.queue <- span:
+	default:
+	}
+}
+
+// Shutdown exports any queued spans and stops the background exporter
+func (e *OTLPExporter) Shutdown() {
+	e.once.Do(func() { close(e.done) })
+	e.wg.Wait()
+}
+
+func (e *OTLPExporter) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []SpanData
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.post(batch); err != nil {
+			slog.Warn("Failed to export spans", "spans", len(batch), "error", err)
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) >= e.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.done:
+			for {
+				select {
+				case span := <-e.queue:
+					batch = append(batch, span)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// post sends a batch of spans to the collector
+func (e *OTLPExporter) post(spans []SpanData) error {
+	payload, err := json.Marshal(e.encode(spans))
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// OTLP/JSON request structure. IDs are hex encoded and 64-bit integers are
+// decimal strings, per the OTLP JSON encoding rules.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+	}
+)
+
+func (e *OTLPExporter) encode(spans []SpanData) otlpRequest {
+	encoded := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		encoded[i] = otlpSpan{
+			TraceID:           hex.EncodeToString(span.Context.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.Context.SpanID[:]),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        encodeAttributes(span.Attributes),
+			Status:            otlpStatus{Code: span.StatusCode, Message: span.StatusMessage},
+		}
+		if span.Parent != (SpanID{}) {
+			encoded[i].ParentSpanID = hex.EncodeToString(span.Parent[:])
+		}
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: encodeAttributes([]Attribute{
+			{Key: "service.name", Value: e.ServiceName},
+		})},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: e.ServiceName},
+			Spans: encoded,
+		}},
+	}}}
+}
+
+func encodeAttributes(attrs []Attribute) []otlpKeyValue {
+	encoded := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		var value otlpAnyValue
+		switch v := attr.Value.(type) {
+		case int:
+			s := strconv.Itoa(v)
+			value.IntValue = &s
+		case int64:
+			s := strconv.FormatInt(v, 10)
+			value.IntValue = &s
+		case float64:
+			value.DoubleValue = &v
+		case bool:
+			value.BoolValue = &v
+		case string:
+			value.StringValue = &v
+		default:
+			s := fmt.Sprint(v)
+			value.StringValue = &s
+		}
+		encoded = append(encoded, otlpKeyValue{Key: attr.Key, Value: value})
+	}
+	return encoded
+}
@@ -0,0 +1,216 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is a fake OpenTelemetry collector recording the requests it
+// receives
+type collector struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	paths    []string
+	requests []otlpRequest
+	received chan struct{}
+}
+
+func newCollector(t *testing.T) *collector {
+	c := &collector{received: make(chan struct{}, 16)}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req otlpRequest
+		if err := json.Unmarshal(body, &req); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		c.mu.Lock()
+		c.paths = append(c.paths, r.URL.Path)
+		c.requests = append(c.requests, req)
+		c.mu.Unlock()
+		c.received <- struct{}{}
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func (c *collector) spans() []otlpSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var spans []otlpSpan
+	for _, req := range c.requests {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}
+	return spans
+}
+
+func attr(span otlpSpan, key string) (otlpAnyValue, bool) {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return otlpAnyValue{}, false
+}
+
+func TestOTLPExporterExportsSpans(t *testing.T) {
+	c := newCollector(t)
+	e := NewOTLPExporter(c.URL, "mev-staking-tracker")
+	e.Start()
+
+	start := time.Unix(1700000000, 5)
+	root := SpanData{
+		Context: SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}, Sampled: true},
+		Name:    "GET /api/v1/mev/block/:blockNumber",
+		Kind:    KindServer,
+		Start:   start,
+		End:     start.Add(time.Second),
+		Attributes: []Attribute{
+			{Key: "http.route", Value: "/api/v1/mev/block/:blockNumber"},
+			{Key: "http.response.status_code", Value: 502},
+			{Key: "block.number", Value: int64(19000000)},
+			{Key: "reward", Value: 0.25},
+			{Key: "cached", Value: true},
+			{Key: "timeout", Value: 3 * time.Second},
+		},
+		StatusCode:    StatusError,
+		StatusMessage: "Bad Gateway",
+	}
+	child := SpanData{
+		Context: SpanContext{TraceID: TraceID{1}, SpanID: SpanID{3}, Sampled: true},
+		Parent:  SpanID{2},
+		Name:    "eth_getBlockByNumber",
+		Kind:    KindClient,
+		Start:   start,
+		End:     start,
+	}
+	e.ExportSpan(child)
+	e.ExportSpan(root)
+	e.Shutdown()
+
+	if len(c.requests) != 1 {
+		t.Fatalf("collector received %d requests, want 1 batch", len(c.requests))
+	}
+	if c.paths[0] != "/v1/traces" {
+		t.Errorf("posted to %s, want /v1/traces", c.paths[0])
+	}
+	rs := c.requests[0].ResourceSpans[0]
+	if v := rs.Resource.Attributes; len(v) != 1 || v[0].Key != "service.name" || *v[0].Value.StringValue != "mev-staking-tracker" {
+		t.Errorf("got resource attributes %+v", v)
+	}
+	if rs.ScopeSpans[0].Scope.Name != "mev-staking-tracker" {
+		t.Errorf("got scope %q", rs.ScopeSpans[0].Scope.Name)
+	}
+
+	spans := c.spans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	gotChild, gotRoot := spans[0], spans[1]
+
+	if gotRoot.Name != root.Name || gotRoot.Kind != KindServer {
+		t.Errorf("got root %s kind %d", gotRoot.Name, gotRoot.Kind)
+	}
+	if gotRoot.TraceID != "01000000000000000000000000000000" || gotRoot.SpanID != "0200000000000000" || gotRoot.ParentSpanID != "" {
+		t.Errorf("got root ids trace %s span %s parent %q", gotRoot.TraceID, gotRoot.SpanID, gotRoot.ParentSpanID)
+	}
+	if gotRoot.StartTimeUnixNano != strconv.FormatInt(start.UnixNano(), 10) || gotRoot.EndTimeUnixNano != strconv.FormatInt(start.Add(time.Second).UnixNano(), 10) {
+		t.Errorf("got times %s-%s", gotRoot.StartTimeUnixNano, gotRoot.EndTimeUnixNano)
+	}
+	if gotRoot.Status.Code != StatusError || gotRoot.Status.Message != "Bad Gateway" {
+		t.Errorf("got status %+v", gotRoot.Status)
+	}
+
+	// 64-bit integers are decimal strings in OTLP JSON
+	if v, _ := attr(gotRoot, "http.route"); v.StringValue == nil || *v.StringValue != "/api/v1/mev/block/:blockNumber" {
+		t.Errorf("http.route = %+v", v)
+	}
+	if v, _ := attr(gotRoot, "http.response.status_code"); v.IntValue == nil || *v.IntValue != "502" {
+		t.Errorf("http.response.status_code = %+v", v)
+	}
+	if v, _ := attr(gotRoot, "block.number"); v.IntValue == nil || *v.IntValue != "19000000" {
+		t.Errorf("block.number = %+v", v)
+	}
+	if v, _ := attr(gotRoot, "reward"); v.DoubleValue == nil || *v.DoubleValue != 0.25 {
+		t.Errorf("reward = %+v", v)
+	}
+	if v, _ := attr(gotRoot, "cached"); v.BoolValue == nil || !*v.BoolValue {
+		t.Errorf("cached = %+v", v)
+	}
+	if v, _ := attr(gotRoot, "timeout"); v.StringValue == nil || *v.StringValue != "3s" {
+		t.Errorf("timeout = %+v, want it formatted as a string", v)
+	}
+
+	if gotChild.ParentSpanID != "0200000000000000" || gotChild.Kind != KindClient || len(gotChild.Attributes) != 0 {
+		t.Errorf("got child %+v", gotChild)
+	}
+	if gotChild.Status.Code != StatusUnset {
+		t.Errorf("got child status %+v, want unset", gotChild.Status)
+	}
+}
+
+// TestOTLPExporterFlushesFullBatches checks that a full batch is sent
+// without waiting for the flush interval
+func TestOTLPExporterFlushesFullBatches(t *testing.T) {
+	c := newCollector(t)
+	e := NewOTLPExporter(c.URL+"/v1/traces", "mev-staking-tracker")
+	e.BatchSize = 2
+	e.FlushInterval = time.Hour
+	e.Start()
+	defer e.Shutdown()
+
+	for _, name := range []string{"a", "b", "c"} {
+		e.ExportSpan(SpanData{Name: name})
+	}
+
+	select {
+	case <-c.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("full batch was not sent")
+	}
+	if spans := c.spans(); len(spans) != 2 || spans[0].Name != "a" || spans[1].Name != "b" {
+		t.Errorf("got spans %+v, want a and b", spans)
+	}
+}
+
+func TestOTLPExporterFlushesOnInterval(t *testing.T) {
+	c := newCollector(t)
+	e := NewOTLPExporter(c.URL, "mev-staking-tracker")
+	e.FlushInterval = 10 * time.Millisecond
+	e.Start()
+	defer e.Shutdown()
+
+	e.ExportSpan(SpanData{Name: "a"})
+
+	select {
+	case <-c.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("partial batch was not sent on the flush interval")
+	}
+}
+
+func TestNewOTLPExporterEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:4318":            "http://localhost:4318/v1/traces",
+		"http://localhost:4318/":           "http://localhost:4318/v1/traces",
+		"http://localhost:4318/v1/traces":  "http://localhost:4318/v1/traces",
+		"http://localhost:4318/v1/traces/": "http://localhost:4318/v1/traces",
+		"https://otel.example.com/otlp":    "https://otel.example.com/otlp/v1/traces",
+	}
+	for endpoint, want := range tests {
+		if got := NewOTLPExporter(endpoint, "svc").Endpoint; got != want {
+			t.Errorf("NewOTLPExporter(%q).Endpoint = %q, want %q", endpoint, got, want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package tracing records request spans and propagates W3C trace context.
+// Spans are exported in the OpenTelemetry protocol (OTLP/HTTP with JSON
+// encoding), so any OpenTelemetry collector can receive them.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Span kinds, as defined by OpenTelemetry
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// Span status codes, as defined by OpenTelemetry
+const (
+	StatusUnset = 0
+	StatusOK    = 1
+	StatusError = 2
+)
+
+// traceparentHeader carries W3C trace context
+const traceparentHeader = "traceparent"
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// SpanContext identifies a span for propagation
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// Valid reports whether both IDs are set
+func (sc SpanContext) Valid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// Attribute is a key/value pair recorded on a span. Values are strings,
+// integers, floats or booleans.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// SpanData is a finished span as handed to an Exporter
+type SpanData struct {
+	Context       SpanContext
+	Parent        SpanID
+	Name          string
+	Kind          int
+	Start         time.Time
+	End           time.Time
+	Attributes    []Attribute
+	StatusCode    int
+	StatusMessage string
+}
+
+// Attr returns the value of the named attribute, if recorded
+func (d SpanData) Attr(key string) (interface{}, bool) {
+	for _, attr := range d.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Exporter receives finished spans
+type Exporter interface {
+	ExportSpan(span SpanData)
+}
+
+var (
+	exporterMu sync.RWMutex
+	exporter   Exporter
+)
+
+// SetExporter installs the exporter finished spans are sent to. A nil
+// exporter disables tracing.
+func SetExporter(e Exporter) {
+	exporterMu.Lock()
+	defer exporterMu.Unlock()
+	exporter = e
+}
+
+func currentExporter() Exporter {
+	exporterMu.RLock()
+	defer exporterMu.RUnlock()
+	return exporter
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// Span is an in-progress span. All methods are safe on a nil Span, which is
+// what Start returns when tracing is disabled.
+type Span struct {
+	mu       sync.Mutex
+	data     SpanData
+	exporter Exporter
+	ended    bool
+}
+
+// Start begins a span named name as a child of the span in ctx, or of the
+// remote parent extracted into ctx, and returns a context carrying it
+func Start(ctx context.Context, name string, kind int) (context.Context, *Span) {
+	e := currentExporter()
+	if e == nil {
+		return ctx, nil
+	}
+
+	var parent SpanContext
+	if span, ok := ctx.Value(spanKey{}).(*Span); ok && span != nil {
+		parent = span.data.Context
+	} else if remote, ok := ctx.Value(remoteKey{}).(SpanContext); ok {
+		parent = remote
+	}
+
+	span := &Span{
+		exporter: e,
+		data: SpanData{
+			Context: SpanContext{TraceID: parent.TraceID, Sampled: true},
+			Parent:  parent.SpanID,
+			Name:    name,
+			Kind:    kind,
+			Start:   time.Now(),
+		},
+	}
+	if !parent.Valid() {
+		rand.Read(span.data.Context.TraceID[:]) //nolint:errcheck // never fails
+	}
+	rand.Read(span.data.Context.SpanID[:]) //nolint:errcheck // never fails
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttributes records attributes on the span, replacing earlier values
+// for the same keys
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+next:
+	for _, attr := range attrs {
+		for i := range s.data.Attributes {
+			if s.data.Attributes[i].Key == attr.Key {
+				s.data.Attributes[i].Value = attr.Value
+				continue next
+			}
+		}
+		s.data.Attributes = append(s.data.Attributes, attr)
+	}
+}
+
+// SetAttribute records a single attribute on the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.SetAttributes(Attribute{Key: key, Value: value})
+}
+
+// RecordError marks the span as failed with err. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.StatusCode = StatusError
+	s.data.StatusMessage = err.Error()
+}
+
+// SetError marks the span as failed with message
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.StatusCode = StatusError
+	s.data.StatusMessage = message
+}
+
+// End finishes the span and hands it to the exporter. Later calls are
+// ignored.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	data.Attributes = append([]Attribute(nil), s.data.Attributes...)
+	s.mu.Unlock()
+
+	s.exporter.ExportSpan(data)
+}
+
+// Extract returns a context carrying the remote parent described by the
+// traceparent header, if present and well formed
+func Extract(ctx context.Context, header http.Header) context.Context {
+	sc, ok := parseTraceparent(header.Get(traceparentHeader))
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// Inject sets the traceparent header for the span in ctx, if any, so
+// upstream services can join the trace
+func Inject(ctx context.Context, header http.Header) {
+	span, ok := ctx.Value(spanKey{}).(*Span)
+	if !ok || span == nil {
+		return
+	}
+	sc := span.data.Context
+	header.Set(traceparentHeader, fmt.Sprintf("00-%s-%s-01", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:])))
+}
+
+// parseTraceparent parses a version 00 W3C traceparent header
+func parseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+
+	return sc, sc.Valid()
+}
@@ -0,0 +1,192 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// memExporter keeps finished spans in memory, in the order they ended
+type memExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *memExporter) ExportSpan(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func (e *memExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// useExporter installs an in-memory exporter for the test
+func useExporter(t *testing.T) *memExporter {
+	e := &memExporter{}
+	SetExporter(e)
+	t.Cleanup(func() { SetExporter(nil) })
+	return e
+}
+
+func TestStartWithoutExporter(t *testing.T) {
+	SetExporter(nil)
+
+	ctx, span := Start(context.Background(), "request", KindServer)
+	if span != nil {
+		t.Fatalf("got span %+v with tracing disabled", span)
+	}
+
+	// A nil span is safe to use
+	span.SetAttribute("k", "v")
+	span.RecordError(errors.New("failed"))
+	span.SetError("failed")
+	span.End()
+
+	header := http.Header{}
+	Inject(ctx, header)
+	if v := header.Get("traceparent"); v != "" {
+		t.Errorf("injected traceparent %q with tracing disabled", v)
+	}
+}
+
+func TestSpanHierarchy(t *testing.T) {
+	e := useExporter(t)
+
+	ctx, root := Start(context.Background(), "GET /blocks", KindServer)
+	_, child := Start(ctx, "eth_getBlockByNumber", KindClient)
+	child.End()
+	root.End()
+
+	spans := e.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	gotChild, gotRoot := spans[0], spans[1]
+	if gotChild.Name != "eth_getBlockByNumber" || gotChild.Kind != KindClient {
+		t.Errorf("got child %s kind %d", gotChild.Name, gotChild.Kind)
+	}
+	if gotRoot.Name != "GET /blocks" || gotRoot.Kind != KindServer {
+		t.Errorf("got root %s kind %d", gotRoot.Name, gotRoot.Kind)
+	}
+	if !gotRoot.Context.Valid() || gotRoot.Parent != (SpanID{}) {
+		t.Errorf("root has context %+v, parent %x", gotRoot.Context, gotRoot.Parent)
+	}
+	if gotChild.Context.TraceID != gotRoot.Context.TraceID {
+		t.Errorf("child trace %x, want root's %x", gotChild.Context.TraceID, gotRoot.Context.TraceID)
+	}
+	if gotChild.Parent != gotRoot.Context.SpanID || gotChild.Context.SpanID == gotRoot.Context.SpanID {
+		t.Errorf("child span %x has parent %x, want root %x", gotChild.Context.SpanID, gotChild.Parent, gotRoot.Context.SpanID)
+	}
+	if gotRoot.End.Before(gotRoot.Start) {
+		t.Errorf("root ended at %v before starting at %v", gotRoot.End, gotRoot.Start)
+	}
+
+	// Unrelated roots start their own traces
+	_, other := Start(context.Background(), "GET /blocks", KindServer)
+	other.End()
+	if spans := e.Spans(); spans[2].Context.TraceID == gotRoot.Context.TraceID {
+		t.Error("separate requests share a trace")
+	}
+}
+
+func TestSpanAttributesAndStatus(t *testing.T) {
+	e := useExporter(t)
+
+	_, span := Start(context.Background(), "request", KindInternal)
+	span.SetAttributes(
+		Attribute{Key: "block.number", Value: 100},
+		Attribute{Key: "cached", Value: false},
+	)
+	span.SetAttribute("block.number", 101) // Replaces the earlier value
+	span.RecordError(nil)                  // Ignored
+	span.RecordError(errors.New("rpc timeout"))
+	span.End()
+	span.End()                         // Exported once
+	span.SetAttribute("late", "value") // Not in the exported copy
+
+	spans := e.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	got := spans[0]
+	if len(got.Attributes) != 2 {
+		t.Errorf("got attributes %+v, want block.number and cached", got.Attributes)
+	}
+	if v, ok := got.Attr("block.number"); !ok || v != 101 {
+		t.Errorf("block.number = %v, want 101", v)
+	}
+	if v, ok := got.Attr("cached"); !ok || v != false {
+		t.Errorf("cached = %v, want false", v)
+	}
+	if _, ok := got.Attr("late"); ok {
+		t.Error("attribute set after End was exported")
+	}
+	if got.StatusCode != StatusError || got.StatusMessage != "rpc timeout" {
+		t.Errorf("got status %d %q, want error rpc timeout", got.StatusCode, got.StatusMessage)
+	}
+}
+
+func TestExtractAndInject(t *testing.T) {
+	e := useExporter(t)
+
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	header := http.Header{}
+	header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+
+	ctx, span := Start(Extract(context.Background(), header), "GET /blocks", KindServer)
+	out := http.Header{}
+	Inject(ctx, out)
+	span.End()
+
+	got := e.Spans()[0]
+	if hex.EncodeToString(got.Context.TraceID[:]) != traceID {
+		t.Errorf("got trace %x, want the remote trace %s", got.Context.TraceID, traceID)
+	}
+	if hex.EncodeToString(got.Parent[:]) != spanID {
+		t.Errorf("got parent %x, want the remote span %s", got.Parent, spanID)
+	}
+
+	want := "00-" + traceID + "-" + hex.EncodeToString(got.Context.SpanID[:]) + "-01"
+	if v := out.Get("traceparent"); v != want {
+		t.Errorf("injected %q, want %q", v, want)
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantOK      bool
+		wantSampled bool
+	}{
+		{"sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"not sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"surrounding space", " 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01 ", true, true},
+		{"empty", "", false, false},
+		{"unknown version", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"short trace id", "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01", false, false},
+		{"non-hex span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bz-01", false, false},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"zero span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false},
+		{"missing flags", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := parseTraceparent(tt.value)
+			if ok != tt.wantOK || ok && sc.Sampled != tt.wantSampled {
+				t.Errorf("got %+v, %v; want ok %v, sampled %v", sc, ok, tt.wantOK, tt.wantSampled)
+			}
+		})
+	}
+}